@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx/backup"
+)
+
+func runBackup(ctx context.Context, args []string) error {
+	var (
+		f       clientFlags
+		account string
+		dir     string
+	)
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	f.register(fs)
+	fs.StringVar(&account, "account", "", "uid of the account to back up")
+	fs.StringVar(&dir, "dir", "", "directory the backup is written to")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if account == "" || dir == "" {
+		return errors.New("backup needs -account and -dir")
+	}
+
+	client, err := f.client(ctx)
+	if err != nil {
+		return err
+	}
+	manifest, err := backup.Backup(ctx, client, account, dir)
+	if err != nil {
+		return err
+	}
+	for _, objectType := range backup.Types() {
+		fmt.Printf("%-12s %d\n", objectType, manifest.Counts[objectType])
+	}
+	fmt.Printf("\nBacked up account %s to %s.\n", account, dir)
+	return nil
+}
+
+func runRestore(ctx context.Context, args []string) error {
+	var (
+		f       clientFlags
+		account string
+		dir     string
+		types   string
+	)
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	f.register(fs)
+	fs.StringVar(&dir, "dir", "", "backup directory to restore")
+	fs.StringVar(&account, "account", "", "uid of the account receiving the objects, the backed up one when empty")
+	fs.StringVar(&types, "types", "", "comma separated object types to restore, all of them when empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if dir == "" {
+		return errors.New("restore needs -dir")
+	}
+
+	client, err := f.client(ctx)
+	if err != nil {
+		return err
+	}
+	opts := backup.RestoreOptions{Account: account}
+	if types != "" {
+		opts.Types = strings.Split(types, ",")
+	}
+	report, err := backup.Restore(ctx, client, dir, opts)
+	if report == nil {
+		return err
+	}
+
+	// old uid to new uid, so references outside OX3 can be updated
+	old := make([]string, 0, len(report.UIDs))
+	for uid := range report.UIDs {
+		old = append(old, uid)
+	}
+	sort.Strings(old)
+	for _, uid := range old {
+		fmt.Printf("%s => %s\n", uid, report.UIDs[uid])
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nRestore complete: %d objects created.\n", report.Total())
+	return nil
+}
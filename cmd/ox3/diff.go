@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"strings"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/marcsantiago/OX3-Go-API-Client/openx/backup"
+	"github.com/marcsantiago/OX3-Go-API-Client/openx/diff"
+)
+
+// liveSnapshotPrefix marks a diff side read from OX3 instead of a backup directory
+const liveSnapshotPrefix = "ox3:"
+
+func runDiff(ctx context.Context, args []string) error {
+	var (
+		f      clientFlags
+		format string
+		types  string
+		ignore string
+	)
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	f.register(fs)
+	fs.StringVar(&format, "format", "text", "output format, text, json or markdown")
+	fs.StringVar(&types, "types", "", "comma separated object types to compare, all of them when empty")
+	fs.StringVar(&ignore, "ignore", strings.Join(diff.DefaultIgnore, ","), "comma separated fields left out of the comparison")
+	fs.Usage = func() {
+		fs.Output().Write([]byte("usage: ox3 diff [flags] <old> <new>\n\n" +
+			"old and new are backup directories or ox3:<account uid> to read the account from OX3\n\n"))
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errors.New("diff needs two snapshots")
+	}
+	out, err := diff.ParseFormat(format)
+	if err != nil {
+		return err
+	}
+
+	// the client is only logged in when a side is live
+	var client *openx.Client
+	load := func(source string) (*backup.Snapshot, error) {
+		if !strings.HasPrefix(source, liveSnapshotPrefix) {
+			return backup.Read(source)
+		}
+		if client == nil {
+			var err error
+			if client, err = f.client(ctx); err != nil {
+				return nil, err
+			}
+		}
+		return backup.Take(ctx, client, strings.TrimPrefix(source, liveSnapshotPrefix))
+	}
+	old, err := load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	new, err := load(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	opts := diff.Options{Ignore: []string{}}
+	if ignore != "" {
+		opts.Ignore = strings.Split(ignore, ",")
+	}
+	if types != "" {
+		opts.Types = strings.Split(types, ",")
+	}
+	return diff.Compare(old, new, opts).Write(os.Stdout, out)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx/inventory"
+)
+
+type inventoryFlags struct {
+	clientFlags
+	file string
+}
+
+func parseInventoryFlags(name string, args []string, extra func(*flag.FlagSet)) (*inventoryFlags, error) {
+	var f inventoryFlags
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	f.register(fs)
+	fs.StringVar(&f.file, "f", "inventory.yaml", "path to the inventory spec")
+	if extra != nil {
+		extra(fs)
+	}
+	return &f, fs.Parse(args)
+}
+
+// computePlan loads the spec and plans it against OX3
+func (f *inventoryFlags) computePlan(ctx context.Context) (*inventory.Plan, func() error, error) {
+	spec, err := inventory.Load(f.file)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := f.client(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	plan, err := inventory.Compute(ctx, client, spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	apply := func() error {
+		report, err := inventory.Apply(ctx, client, plan)
+		printReport(report)
+		return err
+	}
+	return plan, apply, nil
+}
+
+func runPlan(ctx context.Context, args []string) error {
+	f, err := parseInventoryFlags("plan", args, nil)
+	if err != nil {
+		return err
+	}
+	plan, _, err := f.computePlan(ctx)
+	if err != nil {
+		return err
+	}
+	if plan.Empty() {
+		fmt.Println("No changes, OX3 matches the spec.")
+		return nil
+	}
+	return plan.Write(os.Stdout)
+}
+
+func runApply(ctx context.Context, args []string) error {
+	var autoApprove bool
+	f, err := parseInventoryFlags("apply", args, func(fs *flag.FlagSet) {
+		fs.BoolVar(&autoApprove, "auto-approve", false, "apply without asking for confirmation")
+	})
+	if err != nil {
+		return err
+	}
+	plan, apply, err := f.computePlan(ctx)
+	if err != nil {
+		return err
+	}
+	if plan.Empty() {
+		fmt.Println("No changes, OX3 matches the spec.")
+		return nil
+	}
+	if err := plan.Write(os.Stdout); err != nil {
+		return err
+	}
+
+	if !autoApprove {
+		fmt.Print("\nApply these changes? Only 'yes' will be accepted: ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.TrimSpace(answer) != "yes" {
+			return errors.New("apply cancelled")
+		}
+	}
+	return apply()
+}
+
+func printReport(report *inventory.ApplyReport) {
+	if report == nil {
+		return
+	}
+	for _, c := range report.Applied {
+		fmt.Printf("%s %s %q done\n", c.Action, c.Type, c.Key)
+	}
+	if report.Failed == nil {
+		fmt.Printf("\nApply complete: %d changes.\n", len(report.Applied))
+		return
+	}
+
+	fmt.Printf("\n%s %s %q failed: %v\n", report.Failed.Action, report.Failed.Type, report.Failed.Key, report.Err)
+	for _, c := range report.RolledBack {
+		fmt.Printf("rolled back %s %s %q\n", c.Action, c.Type, c.Key)
+	}
+	for _, rf := range report.RollbackFailures {
+		fmt.Printf("COULDN'T ROLL BACK %s %s %q: %v\n", rf.Change.Action, rf.Change.Type, rf.Change.Key, rf.Err)
+	}
+}
@@ -0,0 +1,86 @@
+// Command ox3 is a command line companion to the openx package.
+//
+// Every command reads its OX3 credentials from the JSON file created by openx.CreateConfigFileTemplate
+//
+//	ox3 plan -config openx_config.json -f inventory.yaml
+//	ox3 apply -config openx_config.json -f inventory.yaml
+//	ox3 sync -config openx_config.json -db ox3.db
+//	ox3 backup -config openx_config.json -account <uid> -dir backup
+//	ox3 restore -config openx_config.json -dir backup
+//	ox3 diff -format markdown backup ox3:<uid>
+//	ox3 tags -config openx_config.json -domain ox-d.example.servedbyopenx.com -adunit <uid> -var section=news
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+)
+
+// command is a single ox3 subcommand
+type command struct {
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+var commands = map[string]command{
+	"plan":    {"show the changes needed to reach an inventory spec", runPlan},
+	"apply":   {"make the changes needed to reach an inventory spec", runApply},
+	"sync":    {"refresh a local SQLite mirror of OX3 objects", runSync},
+	"backup":  {"save every object of an account to a directory", runBackup},
+	"restore": {"recreate the objects of a backup", runRestore},
+	"diff":    {"compare two backups or a backup with OX3", runDiff},
+	"tags":    {"print the ad tags of an ad unit or a site", runTags},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "ox3: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := cmd.run(ctx, os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "ox3:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: ox3 <command> [flags]\n\ncommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].summary)
+	}
+}
+
+// clientFlags registers the flags every command shares for logging into OX3
+type clientFlags struct {
+	config string
+	debug  bool
+}
+
+func (f *clientFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.config, "config", "openx_config.json", "path to the OX3 credentials file")
+	fs.BoolVar(&f.debug, "debug", false, "log every request and response to stderr")
+}
+
+func (f *clientFlags) client(ctx context.Context) (*openx.Client, error) {
+	return openx.NewClientFromFileContext(ctx, f.config, f.debug)
+}
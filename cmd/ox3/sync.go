@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/marcsantiago/OX3-Go-API-Client/openx/mirror"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func runSync(ctx context.Context, args []string) error {
+	var (
+		f       clientFlags
+		dbPath  string
+		types   string
+		account string
+		full    bool
+	)
+	fs := flag.NewFlagSet("sync", flag.ContinueOnError)
+	f.register(fs)
+	fs.StringVar(&dbPath, "db", "ox3.db", "path to the SQLite mirror, created when missing")
+	fs.StringVar(&types, "types", "", "comma separated object types to sync, all of them when empty")
+	fs.StringVar(&account, "account", "", "only sync objects of this account uid")
+	fs.BoolVar(&full, "full", false, "list every object and drop the ones removed from OX3")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	m, err := mirror.Open(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	client, err := f.client(ctx)
+	if err != nil {
+		return err
+	}
+	opts := mirror.Options{Full: full}
+	if types != "" {
+		opts.Types = strings.Split(types, ",")
+	}
+	if account != "" {
+		opts.Params = map[string]interface{}{"account_uid": account}
+	}
+
+	results, err := m.Sync(ctx, client, opts)
+	for _, r := range results {
+		kind := "incremental"
+		if r.Full {
+			kind = "full"
+		}
+		mark := "-"
+		if !r.Watermark.IsZero() {
+			mark = openx.FormatDate(r.Watermark)
+		}
+		fmt.Printf("%-12s %-11s fetched %-6d removed %-6d watermark %s\n", r.Type, kind, r.Fetched, r.Removed, mark)
+	}
+	return err
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+)
+
+// varsFlag collects repeated -var name=value flags
+type varsFlag map[string]string
+
+func (v varsFlag) String() string {
+	pairs := make([]string, 0, len(v))
+	for name, value := range v {
+		pairs = append(pairs, name+"="+value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (v varsFlag) Set(s string) error {
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return fmt.Errorf("custom variable %q isn't name=value", s)
+	}
+	v[s[:i]] = s[i+1:]
+	return nil
+}
+
+func runTags(ctx context.Context, args []string) error {
+	var (
+		f        clientFlags
+		adUnit   string
+		site     string
+		formats  string
+		asJSON   bool
+		vars     = varsFlag{}
+		tagsOpts openx.TagOptions
+	)
+	fs := flag.NewFlagSet("tags", flag.ContinueOnError)
+	f.register(fs)
+	fs.StringVar(&adUnit, "adunit", "", "uid of the ad unit to make tags for")
+	fs.StringVar(&site, "site", "", "uid of the site whose ad units get tags")
+	fs.StringVar(&tagsOpts.DeliveryDomain, "domain", "", "ad serving domain of the instance, such as ox-d.example.servedbyopenx.com")
+	fs.StringVar(&formats, "formats", "", "comma separated tag formats, js, iframe or vast. By default js and iframe, vast for video units")
+	fs.Var(vars, "var", "custom variable passed with every ad request as name=value, can be repeated")
+	fs.StringVar(&tagsOpts.ClickURL, "click", "", "click tracking url ad clicks go through")
+	fs.BoolVar(&tagsOpts.Insecure, "insecure", false, "serve the tags over http")
+	fs.BoolVar(&asJSON, "json", false, "print the tags as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if (adUnit == "") == (site == "") {
+		return errors.New("tags needs one of -adunit or -site")
+	}
+	if tagsOpts.DeliveryDomain == "" {
+		return errors.New("tags needs -domain")
+	}
+	if formats != "" {
+		tagsOpts.Formats = strings.Split(formats, ",")
+	}
+	tagsOpts.CustomVars = vars
+
+	client, err := f.client(ctx)
+	if err != nil {
+		return err
+	}
+	var tags []openx.Tag
+	if adUnit != "" {
+		tags, err = client.Tags().AdUnit(ctx, adUnit, tagsOpts)
+	} else {
+		tags, err = client.Tags().Site(ctx, site, tagsOpts)
+	}
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(tags)
+	}
+	for _, tag := range tags {
+		fmt.Printf("# %s (%s) %s\n%s\n", tag.AdUnitName, tag.AdUnitUID, tag.Format, strings.TrimSpace(tag.Code))
+		fmt.Println()
+	}
+	return nil
+}
@@ -0,0 +1,397 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// Metadata is the snapshot of OX3's object metadata ox3gen reads
+type Metadata struct {
+	// Source names the snapshot in the generated file's header
+	Source string      `json:"-"`
+	Enums  []EnumMeta  `json:"enums"`
+	Types  []ModelMeta `json:"types"`
+}
+
+// EnumMeta is a set of values a string field accepts
+type EnumMeta struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// ModelMeta is an OX3 object type
+type ModelMeta struct {
+	Type   string      `json:"type"`
+	Name   string      `json:"name"`
+	Plural string      `json:"plural"`
+	Fields []FieldMeta `json:"fields"`
+}
+
+// FieldMeta is a field of an object type
+type FieldMeta struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Enum     string `json:"enum"`
+	Required bool   `json:"required"`
+	ReadOnly bool   `json:"read_only"`
+}
+
+// goTypes maps metadata field types to the Go types of model fields
+var goTypes = map[string]string{
+	"string":      "string",
+	"uid":         "string",
+	"uid_list":    "[]string",
+	"string_list": "[]string",
+	"integer":     "Int",
+	"decimal":     "Decimal",
+	"boolean":     "*bool",
+	"datetime":    "*Date",
+	"object":      "map[string]interface{}",
+}
+
+// initialisms are the words of field names that don't just get a capital first letter
+var initialisms = map[string]string{
+	"id":       "ID",
+	"ids":      "IDs",
+	"uid":      "UID",
+	"uids":     "UIDs",
+	"url":      "URL",
+	"uri":      "URI",
+	"html":     "HTML",
+	"cpm":      "CPM",
+	"cpc":      "CPC",
+	"cpa":      "CPA",
+	"adunit":   "AdUnit",
+	"lineitem": "LineItem",
+}
+
+func loadMetadata(path string) (*Metadata, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't read the file: %s", path)
+	}
+	var meta Metadata
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return nil, errors.Wrapf(err, "Couldn't decode %s", path)
+	}
+	meta.Source = filepath.ToSlash(path)
+	return &meta, meta.check()
+}
+
+// check catches metadata mistakes that would otherwise surface as confusing compile errors
+func (m *Metadata) check() error {
+	enums := map[string]bool{}
+	for _, e := range m.Enums {
+		if e.Name == "" || len(e.Values) == 0 {
+			return fmt.Errorf("enum %q needs a name and values", e.Name)
+		}
+		if enums[e.Name] {
+			return fmt.Errorf("enum %q is declared twice", e.Name)
+		}
+		enums[e.Name] = true
+	}
+
+	models := map[string]bool{}
+	for _, t := range m.Types {
+		if t.Type == "" || t.Name == "" || t.Plural == "" {
+			return fmt.Errorf("type %q needs a type, name and plural", t.Type)
+		}
+		if models[t.Name] {
+			return fmt.Errorf("type %q is declared twice", t.Name)
+		}
+		models[t.Name] = true
+
+		fields := map[string]bool{}
+		for _, f := range t.Fields {
+			if _, ok := goTypes[f.Type]; !ok {
+				return fmt.Errorf("%s.%s has unknown type %q", t.Type, f.Name, f.Type)
+			}
+			if f.Enum != "" && (!enums[f.Enum] || f.Type != "string") {
+				return fmt.Errorf("%s.%s refers to enum %q which isn't declared or isn't on a string", t.Type, f.Name, f.Enum)
+			}
+			if fields[f.Name] {
+				return fmt.Errorf("%s.%s is declared twice", t.Type, f.Name)
+			}
+			fields[f.Name] = true
+		}
+	}
+	return nil
+}
+
+// goName turns a snake_case OX3 name into an exported Go name
+func goName(name string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' || r == ' ' }) {
+		if w, ok := initialisms[strings.ToLower(word)]; ok {
+			b.WriteString(w)
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+func unexported(name string) string {
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// article is the indefinite article to put before name in doc comments,
+// u is left out as the type names starting with it sound like "you", a user
+func article(name string) string {
+	if name != "" && strings.ContainsRune("aeio", rune(name[0])) {
+		return "an"
+	}
+	return "a"
+}
+
+func goType(f FieldMeta) string {
+	if f.Enum != "" {
+		return f.Enum
+	}
+	return goTypes[f.Type]
+}
+
+// missing is the Go expression telling whether a required field is unset
+func missing(f FieldMeta) string {
+	field := "m." + goName(f.Name)
+	switch f.Type {
+	case "string", "uid", "decimal":
+		return field + ` == ""`
+	case "integer":
+		return field + " == 0"
+	case "uid_list", "string_list", "object":
+		return "len(" + field + ") == 0"
+	case "boolean", "datetime":
+		return field + " == nil"
+	}
+	return "false"
+}
+
+var tmpl = template.Must(template.New("models").Funcs(template.FuncMap{
+	"goName":     goName,
+	"goType":     goType,
+	"unexported": unexported,
+	"missing":    missing,
+	"article":    article,
+}).Parse(`// Code generated by ox3gen from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import "context"
+{{range .Enums}}
+// {{.Name}} is a value OX3 accepts for {{.Name}} fields
+type {{.Name}} string
+
+// {{.Name}} values
+const (
+{{- $enum := .Name}}
+{{- range .Values}}
+	{{$enum}}{{goName .}} {{$enum}} = {{printf "%q" .}}
+{{- end}}
+)
+
+var {{unexported .Name}}Values = []string{ {{- range $i, $v := .Values}}{{if $i}}, {{end}}{{printf "%q" $v}}{{end -}} }
+
+// Valid reports whether v is one of the {{.Name}} values
+func (v {{.Name}}) Valid() bool {
+	switch v {
+	case {{range $i, $v := .Values}}{{if $i}}, {{end}}{{$enum}}{{goName $v}}{{end}}:
+		return true
+	}
+	return false
+}
+{{end}}
+
+// newModel returns an empty model of objectType, nil for types without one
+func newModel(objectType string) validator {
+	switch objectType {
+{{- range .Types}}
+	case {{printf "%q" .Type}}:
+		return &{{.Name}}{}
+{{- end}}
+	}
+	return nil
+}
+{{range .Types}}
+// {{.Name}} is an OX3 {{.Type}}
+type {{.Name}} struct {
+{{- range .Fields}}
+	{{goName .Name}} {{goType .}} ` + "`" + `json:"{{.Name}},omitempty"` + "`" + `
+{{- end}}
+
+	// original is the {{.Type}} as OX3 last returned it, Changes compares against it
+	original Object
+}
+
+func (m *{{.Name}}) track(obj Object) {
+	m.original = obj
+}
+
+// Changes returns the fields set differently from when the {{.Type}} was read from OX3, cleared fields are null.
+// Every set field is a change for {{article .Type}} {{.Type}} that wasn't read from OX3
+func (m *{{.Name}}) Changes() (Object, error) {
+	return changes(m, m.original, {{unexported .Name}}ReadOnly)
+}
+
+// {{unexported .Name}}ReadOnly are the {{.Type}} fields OX3 sets itself
+var {{unexported .Name}}ReadOnly = []string{ {{- $first := true}}{{range .Fields}}{{if .ReadOnly}}{{if not $first}}, {{end}}{{$first = false}}{{printf "%q" .Name}}{{end}}{{end -}} }
+
+// Validate checks the {{.Type}}'s fields, every problem found is listed in the returned ValidationErrors
+func (m *{{.Name}}) Validate() error {
+	var errs ValidationErrors
+{{- range .Fields}}
+{{- if .Required}}
+	errs.required({{printf "%q" .Name}}, {{missing .}})
+{{- end}}
+{{- if .Enum}}
+	errs.oneOf({{printf "%q" .Name}}, string(m.{{goName .Name}}), m.{{goName .Name}}.Valid(), {{unexported .Enum}}Values)
+{{- end}}
+{{- end}}
+	if r, ok := interface{}(m).(ruleValidator); ok {
+		r.validateRules(&errs)
+	}
+	return errs.err()
+}
+
+// {{.Name}}Service reads and writes {{.Type}} objects
+type {{.Name}}Service struct {
+	api ObjectAPI
+}
+
+// New{{.Name}}Service returns the {{.Type}} service working through api, such as an openxtest.Store
+func New{{.Name}}Service(api ObjectAPI) *{{.Name}}Service {
+	return &{{.Name}}Service{api: api}
+}
+
+// {{.Plural}} returns the {{.Type}} service
+func (c *Client) {{.Plural}}() *{{.Name}}Service {
+	return New{{.Name}}Service(c)
+}
+
+// List returns every {{.Type}} matching params
+func (s *{{.Name}}Service) List(ctx context.Context, params map[string]interface{}) ([]*{{.Name}}, error) {
+	objects, err := s.api.List(ctx, {{printf "%q" .Type}}, params)
+	if err != nil {
+		return nil, err
+	}
+	models := make([]*{{.Name}}, len(objects))
+	for i, obj := range objects {
+		models[i] = &{{.Name}}{}
+		if err := decodeModel(obj, models[i]); err != nil {
+			return nil, err
+		}
+	}
+	return models, nil
+}
+
+// Get returns the {{.Type}} with uid
+func (s *{{.Name}}Service) Get(ctx context.Context, uid string) (*{{.Name}}, error) {
+	obj, err := s.api.Fetch(ctx, {{printf "%q" .Type}}, uid)
+	if err != nil {
+		return nil, err
+	}
+	m := &{{.Name}}{}
+	return m, decodeModel(obj, m)
+}
+
+// Create creates m and returns the {{.Type}} OX3 made of it
+func (s *{{.Name}}Service) Create(ctx context.Context, m *{{.Name}}) (*{{.Name}}, error) {
+	fields, err := encodeModel(m, {{unexported .Name}}ReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Create(ctx, {{printf "%q" .Type}}, fields)
+	if err != nil {
+		return nil, err
+	}
+	created := &{{.Name}}{}
+	return created, decodeModel(obj, created)
+}
+
+// Update sends every set field of m to the {{.Type}} with m's uid
+func (s *{{.Name}}Service) Update(ctx context.Context, m *{{.Name}}) (*{{.Name}}, error) {
+	if m.UID == "" {
+		return nil, errMissingUID({{printf "%q" .Type}})
+	}
+	fields, err := encodeModel(m, {{unexported .Name}}ReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, {{printf "%q" .Type}}, m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &{{.Name}}{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Patch sends only m's Changes, after checking the {{.Type}} wasn't modified since m was read.
+// {{goName (article .Type)}} {{.Type}} modified in between comes back as a *ConflictError and nothing is sent
+func (s *{{.Name}}Service) Patch(ctx context.Context, m *{{.Name}}) (*{{.Name}}, error) {
+	if m.UID == "" {
+		return nil, errMissingUID({{printf "%q" .Type}})
+	}
+	fields, err := m.Changes()
+	if err != nil || len(fields) == 0 {
+		return m, err
+	}
+	current, err := s.Get(ctx, m.UID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkConflict({{printf "%q" .Type}}, m.UID, m.original, current.original, fields); err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, {{printf "%q" .Type}}, m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &{{.Name}}{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Delete removes the {{.Type}} with uid
+func (s *{{.Name}}Service) Delete(ctx context.Context, uid string) error {
+	return s.api.Remove(ctx, {{printf "%q" .Type}}, uid)
+}
+{{end}}`))
+
+// generate renders the models of meta as gofmt'ed Go source
+func generate(meta *Metadata, pkg string) ([]byte, error) {
+	for _, t := range meta.Types {
+		if !hasUID(t) {
+			return nil, fmt.Errorf("type %q needs a uid field for its service", t.Type)
+		}
+	}
+
+	var buf bytes.Buffer
+	data := struct {
+		*Metadata
+		Package string
+	}{meta, pkg}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "generated code doesn't parse")
+	}
+	return src, nil
+}
+
+func hasUID(t ModelMeta) bool {
+	for _, f := range t.Fields {
+		if f.Name == "uid" && f.Type == "uid" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// TestGeneratedUpToDate is the -check run, models_gen.go must match the metadata it came from
+func TestGeneratedUpToDate(t *testing.T) {
+	meta, err := loadMetadata("../../openx/metadata/ox3.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// go generate runs from openx
+	meta.Source = "metadata/ox3.json"
+	src, err := generate(meta, "openx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := ioutil.ReadFile("../../openx/models_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, current) {
+		t.Error("openx/models_gen.go is out of date, run go generate ./openx")
+	}
+}
+
+func TestGoName(t *testing.T) {
+	for in, want := range map[string]string{
+		"uid":                "UID",
+		"account_uid":        "AccountUID",
+		"adunit_uids":        "AdUnitUIDs",
+		"click_url":          "ClickURL",
+		"non_guaranteed":     "NonGuaranteed",
+		"Active":             "Active",
+		"parent_account_uid": "ParentAccountUID",
+		"buyer_seat_ids":     "BuyerSeatIDs",
+	} {
+		if got := goName(in); got != want {
+			t.Errorf("goName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestArticle(t *testing.T) {
+	for in, want := range map[string]string{"account": "an", "adunit": "an", "order": "an", "site": "a", "user": "a", "lineitem": "a"} {
+		if got := article(in); got != want {
+			t.Errorf("article(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// TestGenerateBoolean booleans are pointers so a false value isn't dropped by omitempty
+func TestGenerateBoolean(t *testing.T) {
+	meta := &Metadata{Types: []ModelMeta{{Type: "site", Name: "Site", Plural: "Sites", Fields: []FieldMeta{
+		{Name: "uid", Type: "uid"},
+		{Name: "secure", Type: "boolean", Required: true},
+	}}}}
+	src, err := generate(meta, "openx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Secure *bool", `errs.required("secure", m.Secure == nil)`} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("expected %s in the generated source", want)
+		}
+	}
+}
+
+func TestCheckMetadata(t *testing.T) {
+	for _, tc := range []struct {
+		meta Metadata
+		want string
+	}{
+		{Metadata{Enums: []EnumMeta{{Name: "Status"}}}, "needs a name and values"},
+		{Metadata{Types: []ModelMeta{{Type: "site", Name: "Site", Plural: "Sites", Fields: []FieldMeta{{Name: "x", Type: "float"}}}}}, "unknown type"},
+		{Metadata{Types: []ModelMeta{{Type: "site", Name: "Site", Plural: "Sites", Fields: []FieldMeta{{Name: "status", Type: "string", Enum: "Nope"}}}}}, "isn't declared"},
+	} {
+		err := tc.meta.check()
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("got %v, want an error containing %q", err, tc.want)
+		}
+	}
+
+	if _, err := generate(&Metadata{Types: []ModelMeta{{Type: "site", Name: "Site", Plural: "Sites"}}}, "openx"); err == nil {
+		t.Error("expected a type without a uid to be refused")
+	}
+}
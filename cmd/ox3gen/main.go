@@ -0,0 +1,72 @@
+// Command ox3gen generates the typed OX3 models, enums, validation and services in the openx package
+// from a JSON snapshot of OX3's object metadata. It runs through go generate in openx
+//
+//	go generate ./openx
+//
+// With -check nothing is written, it fails when the generated file is out of date with the metadata,
+// which is what CI runs.
+//
+// The snapshot lists enums and, for every object type, its fields
+//
+//	{
+//	  "enums": [{"name": "Status", "values": ["Active", "Inactive"]}],
+//	  "types": [{"type": "site", "name": "Site", "plural": "Sites", "fields": [
+//	    {"name": "name", "type": "string", "required": true},
+//	    {"name": "status", "type": "string", "enum": "Status"}
+//	  ]}]
+//	}
+//
+// Field types are string, uid, uid_list, string_list, integer, decimal, boolean, datetime and object,
+// booleans are generated as *bool so false can be sent.
+// read_only fields are decoded but never sent back to OX3
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+func main() {
+	var (
+		in    string
+		out   string
+		pkg   string
+		check bool
+	)
+	flag.StringVar(&in, "in", "metadata/ox3.json", "metadata snapshot to generate from")
+	flag.StringVar(&out, "out", "models_gen.go", "file to write")
+	flag.StringVar(&pkg, "package", "openx", "package of the generated file")
+	flag.BoolVar(&check, "check", false, "fail when out isn't what would be generated instead of writing it")
+	flag.Parse()
+
+	if err := run(in, out, pkg, check); err != nil {
+		fmt.Fprintln(os.Stderr, "ox3gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(in, out, pkg string, check bool) error {
+	meta, err := loadMetadata(in)
+	if err != nil {
+		return err
+	}
+	src, err := generate(meta, pkg)
+	if err != nil {
+		return err
+	}
+
+	if !check {
+		return ioutil.WriteFile(out, src, 0644)
+	}
+	current, err := ioutil.ReadFile(out)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(current, src) {
+		return fmt.Errorf("%s is out of date with %s, run go generate", out, in)
+	}
+	return nil
+}
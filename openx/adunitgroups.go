@@ -0,0 +1,179 @@
+package openx
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// defaultGroupUnitsPerRequest is the most ad unit uids sent in one request unless WithUnitsPerRequest says otherwise
+const defaultGroupUnitsPerRequest = 1000
+
+// GroupOption configures a membership operation
+type GroupOption func(*groupOptions)
+
+type groupOptions struct {
+	unitsPerRequest int
+}
+
+// WithUnitsPerRequest sets the most ad unit uids sent in one request, 1000 by default. A group whose new
+// member list is longer is changed through its member endpoint instead, in chunks of added and removed units
+func WithUnitsPerRequest(n int) GroupOption {
+	return func(o *groupOptions) {
+		if n > 0 {
+			o.unitsPerRequest = n
+		}
+	}
+}
+
+// GroupChange is what a membership operation did to an ad unit group
+type GroupChange struct {
+	Group *AdUnitGroup
+	// Added and Removed are the units that joined and left the group, in the order they were given
+	Added   []string
+	Removed []string
+	// Requests is how many writes it took, 0 when the group already had the members asked for
+	Requests int
+}
+
+// AddUnits adds ad units to the group, units already in it are left alone
+func (s *AdUnitGroupService) AddUnits(ctx context.Context, uid string, unitUIDs []string, opts ...GroupOption) (*GroupChange, error) {
+	return s.change(ctx, uid, func(current []string) []string {
+		return append(current, unitUIDs...)
+	}, opts)
+}
+
+// RemoveUnits takes ad units out of the group, units that aren't in it are ignored
+func (s *AdUnitGroupService) RemoveUnits(ctx context.Context, uid string, unitUIDs []string, opts ...GroupOption) (*GroupChange, error) {
+	drop := toSet(unitUIDs)
+	return s.change(ctx, uid, func(current []string) []string {
+		kept := []string{}
+		for _, u := range current {
+			if !drop[u] {
+				kept = append(kept, u)
+			}
+		}
+		return kept
+	}, opts)
+}
+
+// ReplaceUnits makes unitUIDs the group's members
+func (s *AdUnitGroupService) ReplaceUnits(ctx context.Context, uid string, unitUIDs []string, opts ...GroupOption) (*GroupChange, error) {
+	return s.change(ctx, uid, func([]string) []string {
+		return append([]string{}, unitUIDs...)
+	}, opts)
+}
+
+// change reads the group, works out the members to add and remove to get to members(current)
+// and sends the smallest update that gets there
+func (s *AdUnitGroupService) change(ctx context.Context, uid string, members func(current []string) []string, opts []GroupOption) (*GroupChange, error) {
+	o := groupOptions{unitsPerRequest: defaultGroupUnitsPerRequest}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	group, err := s.Get(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	current := group.AdUnitUIDs
+	target := unique(members(append([]string(nil), current...)))
+
+	change := &GroupChange{Group: group}
+	have, want := toSet(current), toSet(target)
+	for _, u := range target {
+		if !have[u] {
+			change.Added = append(change.Added, u)
+		}
+	}
+	for _, u := range current {
+		if !want[u] {
+			change.Removed = append(change.Removed, u)
+		}
+	}
+	if len(change.Added) == 0 && len(change.Removed) == 0 {
+		return change, nil
+	}
+
+	if len(target) <= o.unitsPerRequest {
+		obj, err := s.api.Update(ctx, TypeAdUnitGroup, uid, Object{"adunit_uids": target})
+		if err != nil {
+			return nil, errors.Wrapf(err, "Couldn't update the members of ad unit group %s", uid)
+		}
+		change.Requests = 1
+		change.Group = &AdUnitGroup{}
+		return change, decodeModel(obj, change.Group)
+	}
+
+	// the member list doesn't fit in one request, only the difference is sent
+	v, err := verbsOf(s.api, "change the members of a group this large")
+	if err != nil {
+		return nil, err
+	}
+	endpoint := TypeAdUnitGroup + "/" + uid + "/adunit"
+	for _, chunk := range chunks(change.Removed, o.unitsPerRequest) {
+		body, err := encodeBody(Object{"adunit_uids": chunk})
+		if err != nil {
+			return nil, err
+		}
+		res, err := v.DeleteContext(ctx, endpoint, body)
+		if err == nil {
+			_, err = readResponse(res)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "Couldn't remove units from ad unit group %s after %d requests", uid, change.Requests)
+		}
+		change.Requests++
+	}
+	for _, chunk := range chunks(change.Added, o.unitsPerRequest) {
+		body, err := encodeBody(Object{"adunit_uids": chunk})
+		if err != nil {
+			return nil, err
+		}
+		res, err := v.PostContext(ctx, endpoint, body)
+		if err == nil {
+			_, err = readResponse(res)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "Couldn't add units to ad unit group %s after %d requests", uid, change.Requests)
+		}
+		change.Requests++
+	}
+
+	if change.Group, err = s.Get(ctx, uid); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// unique drops repeated values, keeping the first of each
+func unique(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := []string{}
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func chunks(values []string, size int) [][]string {
+	var out [][]string
+	for len(values) > size {
+		out = append(out, values[:size])
+		values = values[size:]
+	}
+	if len(values) > 0 {
+		out = append(out, values)
+	}
+	return out
+}
@@ -0,0 +1,104 @@
+package openx
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestGroupMembership small groups should get their whole member list in one update, only when it changes
+func TestGroupMembership(t *testing.T) {
+	ctx := context.Background()
+	api := &fakeAPI{objects: map[string]Object{
+		"g-1": {"uid": "g-1", "name": "leaderboards", "adunit_uids": []interface{}{"au-1", "au-2"}},
+	}}
+	groups := NewAdUnitGroupService(api)
+
+	change, err := groups.AddUnits(ctx, "g-1", []string{"au-2", "au-3", "au-3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(change.Added, []string{"au-3"}) || change.Removed != nil || change.Requests != 1 {
+		t.Errorf("got %+v", change)
+	}
+	if !reflect.DeepEqual(change.Group.AdUnitUIDs, []string{"au-1", "au-2", "au-3"}) {
+		t.Errorf("got members %v", change.Group.AdUnitUIDs)
+	}
+
+	if change, err = groups.AddUnits(ctx, "g-1", []string{"au-1"}); err != nil || change.Requests != 0 {
+		t.Errorf("adding a member again: got %+v, %v", change, err)
+	}
+
+	change, err = groups.ReplaceUnits(ctx, "g-1", []string{"au-3", "au-4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(change.Added, []string{"au-4"}) || !reflect.DeepEqual(change.Removed, []string{"au-1", "au-2"}) {
+		t.Errorf("got %+v", change)
+	}
+
+	if change, err = groups.RemoveUnits(ctx, "g-1", []string{"au-4", "au-9"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []Object{
+		{"adunit_uids": []string{"au-1", "au-2", "au-3"}},
+		{"adunit_uids": []string{"au-3", "au-4"}},
+		{"adunit_uids": []string{"au-3"}},
+	}
+	if !reflect.DeepEqual(api.updates, want) {
+		t.Errorf("sent %v, want %v", api.updates, want)
+	}
+}
+
+// TestLargeGroup groups too large for one request should only get the difference, in chunks
+func TestLargeGroup(t *testing.T) {
+	members := []string{"au-1", "au-2", "au-3"}
+	var calls []string
+	c := newSessionClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			AdUnitUIDs []string `json:"adunit_uids"`
+		}
+		if r.Body != nil {
+			raw, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(raw, &body)
+		}
+		calls = append(calls, r.Method+" "+strings.Join(body.AdUnitUIDs, ","))
+
+		switch r.Method {
+		case "POST":
+			members = append(members, body.AdUnitUIDs...)
+		case "DELETE":
+			drop := toSet(body.AdUnitUIDs)
+			kept := []string{}
+			for _, u := range members {
+				if !drop[u] {
+					kept = append(kept, u)
+				}
+			}
+			members = kept
+		}
+		json.NewEncoder(w).Encode(Object{"uid": "g-1", "adunit_uids": members})
+	})
+
+	change, err := c.AdUnitGroups().ReplaceUnits(context.Background(), "g-1", []string{"au-3", "au-4", "au-5", "au-6"}, WithUnitsPerRequest(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"GET ", "DELETE au-1,au-2", "POST au-4,au-5", "POST au-6", "GET "}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("got calls %q, want %q", calls, want)
+	}
+	if change.Requests != 3 || !reflect.DeepEqual(change.Group.AdUnitUIDs, []string{"au-3", "au-4", "au-5", "au-6"}) {
+		t.Errorf("got %+v", change)
+	}
+
+	// a store can't take a change that large
+	api := &fakeAPI{objects: map[string]Object{"g-1": {"uid": "g-1", "adunit_uids": []interface{}{"au-1"}}}}
+	if _, err := NewAdUnitGroupService(api).AddUnits(context.Background(), "g-1", []string{"au-2", "au-3"}, WithUnitsPerRequest(2)); err == nil {
+		t.Error("expected an error without a client")
+	}
+}
@@ -0,0 +1,274 @@
+// Package audience uploads first party audience data into OX3 audience segments.
+//
+// Upload streams a file of user ids, one per line, to a segment in chunks so files of any size
+// can be pushed without holding them in memory. Every chunk is retried on its own and the upload
+// carries on past chunks that keep failing, the Summary says what made it in
+//
+//	f, _ := os.Open("users.txt")
+//	summary, err := audience.Upload(ctx, client, segmentUID, f, audience.Options{Progress: func(p audience.Progress) {
+//		log.Printf("%d ids sent", p.Sent)
+//	}})
+package audience
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultChunkSize = 10000
+	defaultAttempts  = 3
+	defaultBackoff   = time.Second
+	// maxLine is the longest line read from an id file
+	maxLine = 64 << 10
+)
+
+// Poster is the access Upload needs, a *openx.Client is one
+type Poster interface {
+	PostFormContext(ctx context.Context, url string, data url.Values) (*http.Response, error)
+}
+
+// Options configures Upload
+type Options struct {
+	// ChunkSize is the number of ids sent per request, 10000 when zero
+	ChunkSize int
+	// Attempts is how many times a chunk is tried before it's given up on, 3 when zero
+	Attempts int
+	// Backoff is the wait before a chunk's second try, it doubles after every try. One second when zero
+	Backoff time.Duration
+	// Remove takes the ids out of the segment instead of adding them
+	Remove bool
+	// Progress is called after every chunk
+	Progress func(Progress)
+}
+
+// Progress is how far an upload got
+type Progress struct {
+	// Chunks sent so far, failed ones included
+	Chunks int
+	// Read is the number of ids read from the file so far
+	Read int
+	// Sent is the number of ids in chunks OX3 took
+	Sent int
+	// Failed is the number of ids in chunks that were given up on
+	Failed int
+}
+
+// ChunkFailure is a chunk that failed every attempt
+type ChunkFailure struct {
+	// Index of the chunk, starting at 0
+	Index int
+	// Line is the line of the file the chunk starts at, starting at 1
+	Line int
+	IDs  int
+	Err  error
+}
+
+// Summary reconciles what was read from the file with what OX3 reported
+type Summary struct {
+	Segment string
+	// Lines read from the file and the blank ones among them, which aren't sent
+	Lines int
+	Blank int
+	// Read is the number of ids read, Lines less Blank
+	Read int
+	// Sent is the number of ids in chunks OX3 took,
+	// Accepted and Rejected split them as OX3 reported, ids it didn't report on count as accepted,
+	// all of a chunk's when OX3 reports neither count and the rest of it when OX3 reports only one
+	Sent     int
+	Accepted int
+	Rejected int
+	// Failed is the number of ids in chunks given up on, they're listed in Failures
+	Failed   int
+	Chunks   int
+	Retries  int
+	Failures []ChunkFailure
+	Duration time.Duration
+}
+
+// Reconciled reports whether every id read is accounted for as accepted, rejected or failed
+func (s *Summary) Reconciled() bool {
+	return s.Read == s.Sent+s.Failed && s.Sent == s.Accepted+s.Rejected
+}
+
+// Err returns nil when every chunk made it, otherwise an error naming the first failure
+func (s *Summary) Err() error {
+	if len(s.Failures) == 0 {
+		return nil
+	}
+	f := s.Failures[0]
+	return errors.Wrapf(f.Err, "%d of %d chunks failed, %d ids not uploaded, first failure at line %d",
+		len(s.Failures), s.Chunks, s.Failed, f.Line)
+}
+
+// String is a one line report of the upload
+func (s *Summary) String() string {
+	return fmt.Sprintf("segment %s: %d ids read (%d blank lines), %d sent in %d chunks, %d accepted, %d rejected, %d failed",
+		s.Segment, s.Read, s.Blank, s.Sent, s.Chunks, s.Accepted, s.Rejected, s.Failed)
+}
+
+// Upload adds the user ids read from r, one per line, to the segment with segmentUID.
+// The error is only for the file or ctx, chunks that fail are reported in the Summary, check its Err
+func Upload(ctx context.Context, api Poster, segmentUID string, r io.Reader, opts Options) (*Summary, error) {
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = defaultChunkSize
+	}
+	if opts.Attempts <= 0 {
+		opts.Attempts = defaultAttempts
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = defaultBackoff
+	}
+
+	start := time.Now()
+	summary := &Summary{Segment: segmentUID}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxLine)
+
+	chunk := make([]string, 0, opts.ChunkSize)
+	chunkLine := 0
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		err := upload(ctx, api, segmentUID, chunk, chunkLine, opts, summary)
+		chunk = chunk[:0]
+		if opts.Progress != nil {
+			opts.Progress(Progress{Chunks: summary.Chunks, Read: summary.Read, Sent: summary.Sent, Failed: summary.Failed})
+		}
+		return err
+	}
+
+	for scanner.Scan() {
+		summary.Lines++
+		id := strings.TrimSpace(scanner.Text())
+		if id == "" {
+			summary.Blank++
+			continue
+		}
+		if len(chunk) == 0 {
+			chunkLine = summary.Lines
+		}
+		chunk = append(chunk, id)
+		summary.Read++
+		if len(chunk) == opts.ChunkSize {
+			if err := flush(); err != nil {
+				summary.Duration = time.Since(start)
+				return summary, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		summary.Duration = time.Since(start)
+		return summary, errors.Wrapf(err, "Couldn't read the ids after line %d", summary.Lines)
+	}
+	err := flush()
+	summary.Duration = time.Since(start)
+	return summary, err
+}
+
+// upload sends one chunk, retrying it, and adds the outcome to summary.
+// Only a cancelled ctx is returned, a chunk that keeps failing is recorded in summary.Failures
+func upload(ctx context.Context, api Poster, segmentUID string, ids []string, line int, opts Options, summary *Summary) error {
+	index := summary.Chunks
+	summary.Chunks++
+
+	form := url.Values{}
+	form.Set("user_ids", strings.Join(ids, "\n"))
+	if opts.Remove {
+		form.Set("action", "remove")
+	} else {
+		form.Set("action", "add")
+	}
+	endpoint := openx.TypeAudienceSegment + "/" + segmentUID + "/users"
+
+	wait := opts.Backoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		var result chunkResult
+		result, err = send(ctx, api, endpoint, form)
+		if err == nil {
+			accepted, rejected := result.count(result.Accepted), result.count(result.Rejected)
+			if result.Accepted == nil || result.Rejected == nil {
+				// ids OX3 didn't report on count as accepted
+				accepted = len(ids) - rejected
+			}
+			summary.Sent += len(ids)
+			summary.Accepted += accepted
+			summary.Rejected += rejected
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if attempt == opts.Attempts || !retryable(err) {
+			break
+		}
+		summary.Retries++
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		wait *= 2
+	}
+
+	summary.Failed += len(ids)
+	summary.Failures = append(summary.Failures, ChunkFailure{Index: index, Line: line, IDs: len(ids), Err: err})
+	return nil
+}
+
+// chunkResult is what OX3 says about a chunk, counts are missing when it doesn't report them
+type chunkResult struct {
+	Accepted *int `json:"accepted"`
+	Rejected *int `json:"rejected"`
+}
+
+func (r chunkResult) count(n *int) int {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
+
+func send(ctx context.Context, api Poster, endpoint string, form url.Values) (chunkResult, error) {
+	var result chunkResult
+	res, err := api.PostFormContext(ctx, endpoint, form)
+	if err != nil {
+		return result, err
+	}
+	if err := openx.CheckResponse(res); err != nil {
+		return result, err
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return result, err
+	}
+	// a body that isn't a report still means the chunk went in
+	json.Unmarshal(body, &result)
+	return result, nil
+}
+
+// retryable reports whether a failed chunk is worth sending again: network errors, 429 and 5xx
+func retryable(err error) bool {
+	apiErr, ok := errors.Cause(err).(*openx.APIError)
+	if !ok {
+		return true
+	}
+	return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+}
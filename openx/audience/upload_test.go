@@ -0,0 +1,167 @@
+package audience
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/marcsantiago/OX3-Go-API-Client/openx/openxtest"
+)
+
+// fakeOX3 answers membership uploads, statuses are used in turn and 200 once they run out
+type fakeOX3 struct {
+	statuses []int
+	report   string
+	posts    []url.Values
+	urls     []string
+	onPost   func()
+}
+
+func (f *fakeOX3) PostFormContext(ctx context.Context, endpoint string, data url.Values) (*http.Response, error) {
+	f.posts = append(f.posts, data)
+	f.urls = append(f.urls, endpoint)
+	if f.onPost != nil {
+		f.onPost()
+	}
+	rec := httptest.NewRecorder()
+	status := http.StatusOK
+	if len(f.statuses) > 0 {
+		status, f.statuses = f.statuses[0], f.statuses[1:]
+	}
+	rec.WriteHeader(status)
+	if status == http.StatusOK {
+		fmt.Fprint(rec, f.report)
+	}
+	res := rec.Result()
+	res.Request = httptest.NewRequest(http.MethodPost, "https://api.example.com/data/1.0/"+endpoint+"?oauth_token=secret", nil)
+	return res, nil
+}
+
+func ids(n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "user-%d\n", i)
+		if i%4 == 0 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// TestUpload ids should be sent in chunks with progress after each one
+func TestUpload(t *testing.T) {
+	api := &fakeOX3{}
+	var progress []Progress
+	summary, err := Upload(context.Background(), api, "seg-1", strings.NewReader(ids(10)), Options{
+		ChunkSize: 4,
+		Progress:  func(p Progress) { progress = append(progress, p) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Err() != nil || !summary.Reconciled() {
+		t.Errorf("got %v, reconciled %v", summary.Err(), summary.Reconciled())
+	}
+	if summary.Read != 10 || summary.Blank != 2 || summary.Chunks != 3 || summary.Accepted != 10 {
+		t.Errorf("got %s", summary)
+	}
+	if len(api.posts) != 3 || api.urls[0] != "audiencesegment/seg-1/users" || api.posts[0].Get("action") != "add" {
+		t.Fatalf("got posts %v to %v", api.posts, api.urls)
+	}
+	if got := api.posts[2].Get("user_ids"); got != "user-9\nuser-10" {
+		t.Errorf("got last chunk %q", got)
+	}
+	if len(progress) != 3 || progress[1] != (Progress{Chunks: 2, Read: 8, Sent: 8}) {
+		t.Errorf("got progress %+v", progress)
+	}
+}
+
+// TestUploadRetries failing chunks should be retried, given up on after Attempts and reported
+func TestUploadRetries(t *testing.T) {
+	// the first chunk goes through on its second try, the second is refused outright,
+	// the third keeps failing
+	api := &fakeOX3{statuses: []int{503, 200, 400, 503, 502, 500}, report: `{"accepted": 3, "rejected": 1}`}
+	summary, err := Upload(context.Background(), api, "seg-1", strings.NewReader(ids(12)), Options{
+		ChunkSize: 4,
+		Attempts:  3,
+		Backoff:   time.Millisecond,
+		Remove:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(api.posts) != 6 || api.posts[0].Get("action") != "remove" {
+		t.Errorf("got %d posts", len(api.posts))
+	}
+	if summary.Sent != 4 || summary.Accepted != 3 || summary.Rejected != 1 || summary.Failed != 8 || summary.Retries != 3 {
+		t.Errorf("got %s, %d retries", summary, summary.Retries)
+	}
+	if !summary.Reconciled() {
+		t.Error("expected the summary to reconcile")
+	}
+	if len(summary.Failures) != 2 || summary.Failures[0].Line != 6 || summary.Failures[1].Index != 2 {
+		t.Errorf("got failures %+v", summary.Failures)
+	}
+	if apiErr, ok := summary.Failures[0].Err.(*openx.APIError); !ok || apiErr.StatusCode != 400 || strings.Contains(apiErr.URL, "secret") {
+		t.Errorf("got failure %v, want a 400 with the url redacted", summary.Failures[0].Err)
+	}
+	if summary.Err() == nil {
+		t.Error("expected an error")
+	}
+}
+
+// TestUploadOneSided ids left out of a report giving only one count should count as accepted
+func TestUploadOneSided(t *testing.T) {
+	for report, want := range map[string][2]int{
+		`{"accepted": 3}`: {4, 0},
+		`{"rejected": 1}`: {3, 1},
+		`{}`:              {4, 0},
+	} {
+		api := &fakeOX3{report: report}
+		summary, err := Upload(context.Background(), api, "seg-1", strings.NewReader(ids(4)), Options{ChunkSize: 4})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if summary.Accepted != want[0] || summary.Rejected != want[1] || !summary.Reconciled() {
+			t.Errorf("%s: got %s, reconciled %v", report, summary, summary.Reconciled())
+		}
+	}
+}
+
+// TestUploadCancel a cancelled upload should stop, even while waiting to retry
+func TestUploadCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	api := &fakeOX3{statuses: []int{503}, onPost: func() { time.AfterFunc(10*time.Millisecond, cancel) }}
+	summary, err := Upload(ctx, api, "seg-1", strings.NewReader(ids(12)), Options{
+		ChunkSize: 4,
+		Backoff:   time.Hour,
+	})
+	if err != context.Canceled {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if len(api.posts) != 1 || summary.Sent != 0 {
+		t.Errorf("got %d posts and %s", len(api.posts), summary)
+	}
+}
+
+// TestListByAccount should only return the account's segments
+func TestListByAccount(t *testing.T) {
+	store := openxtest.NewStore()
+	store.Seed(openx.TypeAudienceSegment,
+		openx.Object{"name": "Buyers", "account_uid": "a"},
+		openx.Object{"name": "Visitors", "account_uid": "b"})
+
+	segments, err := openx.NewAudienceSegmentService(store).ListByAccount(context.Background(), "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 1 || segments[0].Name != "Buyers" {
+		t.Errorf("got %+v", segments)
+	}
+}
@@ -0,0 +1,192 @@
+// Package backup dumps every object of an OX3 account to a directory and recreates them from it.
+//
+// A backup directory looks like
+//
+//	manifest.json
+//	account.json
+//	site/<uid>.json
+//	adunit/<uid>.json
+//	...
+//
+// with one pretty printed JSON file per object, keys sorted, so two backups of the same account
+// only differ where the objects do and can be kept in version control.
+// Creatives are backed up as metadata, the files they point at stay where they are
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/pkg/errors"
+)
+
+const (
+	manifestFile = "manifest.json"
+	accountFile  = "account.json"
+	// formatVersion is bumped when the layout changes in a way older code can't read
+	formatVersion = 1
+)
+
+// Manifest describes a backup
+type Manifest struct {
+	Version int       `json:"version"`
+	Account string    `json:"account_uid"`
+	Created time.Time `json:"created"`
+	// Counts is how many objects of each type were saved
+	Counts map[string]int `json:"counts"`
+}
+
+// Snapshot is the content of a backup
+type Snapshot struct {
+	Manifest Manifest
+	Account  openx.Object
+	// Objects by type, ordered by uid
+	Objects map[string][]openx.Object
+}
+
+// Types returns the object types a backup holds, parents before children
+func Types() []string {
+	var types []string
+	for _, kind := range openx.Kinds() {
+		if kind.Type != openx.TypeAccount {
+			types = append(types, kind.Type)
+		}
+	}
+	return types
+}
+
+// Backup saves the account with accountUID and every object under it into dir, creating dir when needed.
+// Files of an earlier backup in dir are replaced
+func Backup(ctx context.Context, api openx.ObjectAPI, accountUID, dir string) (*Manifest, error) {
+	snapshot, err := Take(ctx, api, accountUID)
+	if err != nil {
+		return nil, err
+	}
+	if err := write(snapshot, dir); err != nil {
+		return nil, err
+	}
+	return &snapshot.Manifest, nil
+}
+
+// Take reads the account with accountUID and every object under it without writing anything
+func Take(ctx context.Context, api openx.ObjectAPI, accountUID string) (*Snapshot, error) {
+	account, err := api.Fetch(ctx, openx.TypeAccount, accountUID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't fetch account %s", accountUID)
+	}
+
+	snapshot := &Snapshot{
+		Manifest: Manifest{Version: formatVersion, Account: accountUID, Created: time.Now().UTC(), Counts: map[string]int{}},
+		Account:  account,
+		Objects:  map[string][]openx.Object{},
+	}
+	filter := map[string]interface{}{"account_uid": accountUID}
+	for _, objectType := range Types() {
+		objects, err := api.List(ctx, objectType, filter)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Couldn't list %s", objectType)
+		}
+		sortByUID(objects)
+		snapshot.Objects[objectType] = objects
+		snapshot.Manifest.Counts[objectType] = len(objects)
+	}
+	return snapshot, nil
+}
+
+func write(snapshot *Snapshot, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrapf(err, "Couldn't create %s", dir)
+	}
+	if err := writeJSON(filepath.Join(dir, accountFile), snapshot.Account); err != nil {
+		return err
+	}
+	for _, objectType := range Types() {
+		typeDir := filepath.Join(dir, objectType)
+		// objects deleted since an earlier backup mustn't linger
+		if err := os.RemoveAll(typeDir); err != nil {
+			return errors.Wrapf(err, "Couldn't clear %s", typeDir)
+		}
+		if err := os.MkdirAll(typeDir, 0755); err != nil {
+			return errors.Wrapf(err, "Couldn't create %s", typeDir)
+		}
+		for _, obj := range snapshot.Objects[objectType] {
+			if obj.UID() == "" {
+				return fmt.Errorf("%s without a uid can't be backed up: %v", objectType, obj)
+			}
+			if err := writeJSON(filepath.Join(typeDir, obj.UID()+".json"), obj); err != nil {
+				return err
+			}
+		}
+	}
+	// the manifest goes last, a backup without one didn't finish
+	return writeJSON(filepath.Join(dir, manifestFile), snapshot.Manifest)
+}
+
+func writeJSON(path string, v interface{}) error {
+	raw, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "Couldn't encode %s", path)
+	}
+	if err := ioutil.WriteFile(path, append(raw, '\n'), 0644); err != nil {
+		return errors.Wrapf(err, "Couldn't write %s", path)
+	}
+	return nil
+}
+
+// Read loads a backup directory
+func Read(dir string) (*Snapshot, error) {
+	snapshot := &Snapshot{Objects: map[string][]openx.Object{}}
+	if err := readJSON(filepath.Join(dir, manifestFile), &snapshot.Manifest); err != nil {
+		return nil, errors.Wrapf(err, "%s isn't a complete backup", dir)
+	}
+	if snapshot.Manifest.Version > formatVersion {
+		return nil, fmt.Errorf("backup %s has format version %d, this version reads up to %d", dir, snapshot.Manifest.Version, formatVersion)
+	}
+	if err := readJSON(filepath.Join(dir, accountFile), &snapshot.Account); err != nil {
+		return nil, err
+	}
+
+	for _, objectType := range Types() {
+		files, err := filepath.Glob(filepath.Join(dir, objectType, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			var obj openx.Object
+			if err := readJSON(file, &obj); err != nil {
+				return nil, err
+			}
+			snapshot.Objects[objectType] = append(snapshot.Objects[objectType], obj)
+		}
+		if got, want := len(snapshot.Objects[objectType]), snapshot.Manifest.Counts[objectType]; got != want {
+			return nil, fmt.Errorf("backup %s has %d %s files, the manifest lists %d", dir, got, objectType, want)
+		}
+	}
+	return snapshot, nil
+}
+
+func readJSON(path string, v interface{}) error {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "Couldn't read the file: %s", path)
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return errors.Wrapf(err, "Couldn't decode %s", path)
+	}
+	return nil
+}
+
+func sortByUID(objects []openx.Object) {
+	sort.Slice(objects, func(i, j int) bool {
+		return strings.Compare(objects[i].UID(), objects[j].UID()) < 0
+	})
+}
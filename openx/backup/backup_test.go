@@ -0,0 +1,214 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/marcsantiago/OX3-Go-API-Client/openx/openxtest"
+)
+
+// seedAccount fills store with a small account, one object of every type
+func seedAccount(t *testing.T, store *openxtest.Store) string {
+	t.Helper()
+	ctx := context.Background()
+	create := func(objectType string, obj openx.Object) openx.Object {
+		created, err := store.Create(ctx, objectType, obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return created
+	}
+
+	account := create(openx.TypeAccount, openx.Object{"name": "publisher"})
+	acct := account.UID()
+	site := create(openx.TypeSite, openx.Object{"name": "example.com", "account_uid": acct})
+	unit := create(openx.TypeAdUnit, openx.Object{"name": "leaderboard", "account_uid": acct, "site_uid": site.UID()})
+	create(openx.TypeAdUnitGroup, openx.Object{"name": "leaderboards", "account_uid": acct, "adunit_uids": []interface{}{unit.UID()}})
+	pkg := create(openx.TypePackage, openx.Object{"name": "premium", "account_uid": acct,
+		"adunit_uids": []interface{}{unit.UID()}, "site_uids": []interface{}{site.UID()}})
+	create(openx.TypeDeal, openx.Object{"name": "premium pmp", "account_uid": acct, "package_uid": pkg.UID()})
+	order := create(openx.TypeOrder, openx.Object{"name": "spring", "account_uid": acct})
+	line := create(openx.TypeLineItem, openx.Object{"name": "spring 728x90", "account_uid": acct, "order_uid": order.UID()})
+	creative := create(openx.TypeCreative, openx.Object{"name": "banner", "account_uid": acct, "uri": "http://cdn.example.com/banner.png"})
+	create(openx.TypeConversionTag, openx.Object{"name": "checkout", "account_uid": acct, "conversion_type": "purchase"})
+	create(openx.TypeAd, openx.Object{"name": "banner ad", "account_uid": acct, "lineitem_uid": line.UID(), "creative_uid": creative.UID()})
+
+	// something from another account that mustn't end up in the backup
+	create(openx.TypeSite, openx.Object{"name": "other.com", "account_uid": "someone-else"})
+	return acct
+}
+
+func listFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(dir, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func TestBackup(t *testing.T) {
+	ctx := context.Background()
+	store := openxtest.NewStore()
+	acct := seedAccount(t, store)
+	dir := t.TempDir()
+
+	manifest, err := Backup(ctx, store, acct, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, objectType := range Types() {
+		if manifest.Counts[objectType] != 1 {
+			t.Errorf("backed up %d %s, want 1", manifest.Counts[objectType], objectType)
+		}
+	}
+
+	files := listFiles(t, dir)
+	if len(files) != len(Types())+2 {
+		t.Errorf("got files %v, want one per object plus the manifest and account", files)
+	}
+	site := store.Objects(openx.TypeSite)[0]
+	before, err := ioutil.ReadFile(filepath.Join(dir, openx.TypeSite, site.UID()+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a second backup lays out the same files and drops deleted objects
+	ad := store.Objects(openx.TypeAd)[0]
+	store.Remove(ctx, openx.TypeAd, ad.UID())
+	if _, err := Backup(ctx, store, acct, dir); err != nil {
+		t.Fatal(err)
+	}
+	after, _ := ioutil.ReadFile(filepath.Join(dir, openx.TypeSite, site.UID()+".json"))
+	if string(before) != string(after) {
+		t.Errorf("unchanged site was written differently:\n%s\n%s", before, after)
+	}
+	if _, err := os.Stat(filepath.Join(dir, openx.TypeAd, ad.UID()+".json")); !os.IsNotExist(err) {
+		t.Error("the deleted ad is still in the backup")
+	}
+
+	snapshot, err := Read(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Account.UID() != acct || len(snapshot.Objects[openx.TypeAd]) != 0 || len(snapshot.Objects[openx.TypeSite]) != 1 {
+		t.Errorf("read back %+v", snapshot)
+	}
+}
+
+func TestReadIncomplete(t *testing.T) {
+	ctx := context.Background()
+	store := openxtest.NewStore()
+	acct := seedAccount(t, store)
+	dir := t.TempDir()
+	if _, err := Backup(ctx, store, acct, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	site := store.Objects(openx.TypeSite)[0]
+	os.Remove(filepath.Join(dir, openx.TypeSite, site.UID()+".json"))
+	if _, err := Read(dir); err == nil {
+		t.Error("expected a backup missing a file to be refused")
+	}
+	os.Remove(filepath.Join(dir, manifestFile))
+	if _, err := Read(dir); err == nil {
+		t.Error("expected a backup without a manifest to be refused")
+	}
+}
+
+func TestRestore(t *testing.T) {
+	ctx := context.Background()
+	source := openxtest.NewStore()
+	acct := seedAccount(t, source)
+	dir := t.TempDir()
+	if _, err := Backup(ctx, source, acct, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	target := openxtest.NewStore()
+	// offsets the uids the target hands out from the source's
+	target.Seed(openx.TypeAccount, openx.Object{"name": "a"}, openx.Object{"name": "b"}, openx.Object{"name": "c"})
+	newAcct := target.Objects(openx.TypeAccount)[1].UID()
+
+	report, err := Restore(ctx, target, dir, RestoreOptions{Account: newAcct})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Total() != len(Types()) {
+		t.Errorf("created %v, want one object per type", report.Created)
+	}
+
+	calls := target.Calls()
+	want := []string{"create site", "create adunit", "create adunitgroup", "create package", "create deal", "create order", "create lineitem", "create creative", "create conversiontag", "create ad"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("got calls %v, want parents first %v", calls, want)
+	}
+
+	site := target.Objects(openx.TypeSite)[0]
+	unit := target.Objects(openx.TypeAdUnit)[0]
+	group := target.Objects(openx.TypeAdUnitGroup)[0]
+	line := target.Objects(openx.TypeLineItem)[0]
+	creative := target.Objects(openx.TypeCreative)[0]
+	ad := target.Objects(openx.TypeAd)[0]
+	if site.String("account_uid") != newAcct || ad.String("account_uid") != newAcct {
+		t.Errorf("objects weren't moved to the target account: %v %v", site, ad)
+	}
+	if unit.String("site_uid") != site.UID() {
+		t.Errorf("ad unit points at %s, want the restored site %s", unit.String("site_uid"), site.UID())
+	}
+	if !reflect.DeepEqual(group["adunit_uids"], []interface{}{unit.UID()}) {
+		t.Errorf("group members %v, want the restored ad unit %s", group["adunit_uids"], unit.UID())
+	}
+	if ad.String("lineitem_uid") != line.UID() || ad.String("creative_uid") != creative.UID() {
+		t.Errorf("ad references weren't remapped: %v", ad)
+	}
+	pkg := target.Objects(openx.TypePackage)[0]
+	deal := target.Objects(openx.TypeDeal)[0]
+	if !reflect.DeepEqual(pkg["site_uids"], []interface{}{site.UID()}) || deal.String("package_uid") != pkg.UID() {
+		t.Errorf("package and deal references weren't remapped: %v %v", pkg, deal)
+	}
+	if creative.String("uri") != "http://cdn.example.com/banner.png" {
+		t.Errorf("creative metadata was lost: %v", creative)
+	}
+
+	oldSite := source.Objects(openx.TypeSite)[0]
+	if report.UIDs[oldSite.UID()] != site.UID() || report.UIDs[acct] != newAcct {
+		t.Errorf("uid map %v is missing the site or account", report.UIDs)
+	}
+}
+
+func TestRestoreFailure(t *testing.T) {
+	ctx := context.Background()
+	source := openxtest.NewStore()
+	acct := seedAccount(t, source)
+	dir := t.TempDir()
+	if _, err := Backup(ctx, source, acct, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	target := openxtest.NewStore()
+	boom := errors.New("boom")
+	target.FailOn("create", openx.TypeOrder, boom)
+	report, err := Restore(ctx, target, dir, RestoreOptions{})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if report.FailedType != openx.TypeOrder || report.Total() != 5 {
+		t.Errorf("got report %+v, want a failed order after 5 objects", report)
+	}
+}
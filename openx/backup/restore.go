@@ -0,0 +1,123 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/pkg/errors"
+)
+
+// RestoreOptions controls a Restore
+type RestoreOptions struct {
+	// Account receives the objects, the backed up account when empty
+	Account string
+	// Types limits the restore to some object types, everything in the backup when empty.
+	// References to objects of types left out keep their backed up uid
+	Types []string
+	// Omit are extra fields dropped from every object before it's created
+	Omit []string
+}
+
+// RestoreReport describes what Restore did
+type RestoreReport struct {
+	// Created counts the objects created by type
+	Created map[string]int
+	// UIDs maps every backed up uid to the uid of the object recreated from it,
+	// the backed up account maps to the target account
+	UIDs map[string]string
+	// Failed is the backed up object that couldn't be created, nil when everything was restored
+	Failed openx.Object
+	// FailedType is the type of Failed
+	FailedType string
+}
+
+// Total is how many objects were created
+func (r *RestoreReport) Total() int {
+	var n int
+	for _, count := range r.Created {
+		n += count
+	}
+	return n
+}
+
+// Restore recreates the objects of a backup, parents before children.
+// Objects come back with new uids and every reference to a restored object is rewritten to its new uid.
+// Restore stops at the first object OX3 refuses, what was created before it stays and is listed in the report
+func Restore(ctx context.Context, api openx.ObjectAPI, dir string, opts RestoreOptions) (*RestoreReport, error) {
+	snapshot, err := Read(dir)
+	if err != nil {
+		return nil, err
+	}
+	return RestoreSnapshot(ctx, api, snapshot, opts)
+}
+
+// RestoreSnapshot is Restore for a snapshot already in memory
+func RestoreSnapshot(ctx context.Context, api openx.ObjectAPI, snapshot *Snapshot, opts RestoreOptions) (*RestoreReport, error) {
+	target := opts.Account
+	if target == "" {
+		target = snapshot.Manifest.Account
+	}
+	report := &RestoreReport{
+		Created: map[string]int{},
+		UIDs:    map[string]string{snapshot.Manifest.Account: target},
+	}
+
+	wanted := map[string]bool{}
+	for _, objectType := range opts.Types {
+		wanted[objectType] = true
+	}
+	omit := append(append([]string(nil), openx.GeneratedFields...), opts.Omit...)
+
+	for _, kind := range openx.Kinds() {
+		if kind.Type == openx.TypeAccount || (len(wanted) > 0 && !wanted[kind.Type]) {
+			continue
+		}
+		for _, obj := range snapshot.Objects[kind.Type] {
+			fields := obj.Clone()
+			for _, f := range omit {
+				delete(fields, f)
+			}
+			remap(fields, kind, report.UIDs)
+
+			created, err := api.Create(ctx, kind.Type, fields)
+			if err != nil {
+				report.Failed = obj
+				report.FailedType = kind.Type
+				return report, errors.Wrapf(err, "Couldn't restore %s %s, %d objects were created before it",
+					kind.Type, obj.UID(), report.Total())
+			}
+			report.UIDs[obj.UID()] = created.UID()
+			report.Created[kind.Type]++
+		}
+	}
+	return report, nil
+}
+
+// remap rewrites the references of fields to the uids of restored objects
+func remap(fields openx.Object, kind openx.Kind, uids map[string]string) {
+	for _, ref := range kind.References {
+		v, ok := fields[ref.Field]
+		if !ok {
+			continue
+		}
+		if !ref.Many {
+			if uid, ok := uids[fmt.Sprint(v)]; ok {
+				fields[ref.Field] = uid
+			}
+			continue
+		}
+		list, ok := v.([]interface{})
+		if !ok {
+			continue
+		}
+		remapped := make([]interface{}, len(list))
+		for i, item := range list {
+			remapped[i] = item
+			if uid, ok := uids[fmt.Sprint(item)]; ok {
+				remapped[i] = uid
+			}
+		}
+		fields[ref.Field] = remapped
+	}
+}
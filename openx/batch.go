@@ -0,0 +1,244 @@
+package openx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const defaultBatchConcurrency = 4
+
+// ErrSkipped is the error on batch results that never ran because a fail fast batch stopped early
+var ErrSkipped = errors.New("operation skipped, the batch stopped after an earlier failure")
+
+// Operation is a single call made by Batch
+type Operation struct {
+	// Method is one of GET, POST, PUT or DELETE
+	Method string
+	// URL is the endpoint, as passed to Get, Post, Put or Delete
+	URL string
+	// Params are the url parameters of a GET
+	Params map[string]interface{}
+	// Body is sent as JSON with POST, PUT and DELETE, []byte and json.RawMessage are sent untouched
+	Body interface{}
+}
+
+// BatchResult is the outcome of one Operation
+type BatchResult struct {
+	// Index of the operation in the slice passed to Batch
+	Index      int
+	Operation  Operation
+	StatusCode int
+	// Body of a successful response
+	Body []byte
+	// Err is an *APIError for non 2xx responses, ErrSkipped or the transport error
+	Err error
+}
+
+// BatchOptions configures Batch
+type BatchOptions struct {
+	// Concurrency is the number of operations in flight at once, defaults to 4.
+	// Requests still respect the limit set with WithRateLimit
+	Concurrency int
+	// FailFast stops the batch at the first failure, operations that haven't started are skipped
+	// and the ones already running are left to finish
+	FailFast bool
+	// Progress is called after every operation finishes, calls are never concurrent
+	Progress func(done, total int, result BatchResult)
+}
+
+// BatchReport holds a result for every operation in the order they were given
+type BatchReport struct {
+	Results   []BatchResult
+	Succeeded int
+	Failed    int
+	Skipped   int
+}
+
+// Err returns nil when every operation succeeded, otherwise an error summarising the failures
+func (r *BatchReport) Err() error {
+	if r.Failed == 0 && r.Skipped == 0 {
+		return nil
+	}
+	for _, res := range r.Results {
+		if res.Err != nil && res.Err != ErrSkipped {
+			return errors.Wrapf(res.Err, "%d of %d operations failed, %d skipped, first failure at index %d",
+				r.Failed, len(r.Results), r.Skipped, res.Index)
+		}
+	}
+	return fmt.Errorf("%d of %d operations skipped", r.Skipped, len(r.Results))
+}
+
+// Failures returns the results of operations that ran and failed
+func (r *BatchReport) Failures() []BatchResult {
+	var failures []BatchResult
+	for _, res := range r.Results {
+		if res.Err != nil && res.Err != ErrSkipped {
+			failures = append(failures, res)
+		}
+	}
+	return failures
+}
+
+// SkippedResults returns the results of operations that never ran
+func (r *BatchReport) SkippedResults() []BatchResult {
+	var skipped []BatchResult
+	for _, res := range r.Results {
+		if res.Err == ErrSkipped {
+			skipped = append(skipped, res)
+		}
+	}
+	return skipped
+}
+
+// Batch runs ops with bounded concurrency and reports the outcome of each.
+// Cancelling ctx stops the batch, operations that haven't started are skipped
+func (c *Client) Batch(ctx context.Context, ops []Operation, opts BatchOptions) *BatchReport {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	if concurrency > len(ops) {
+		concurrency = len(ops)
+	}
+
+	// stop is closed at the first failure of a fail fast batch, operations already running finish on ctx
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+	stopped := func() bool {
+		select {
+		case <-stop:
+			return true
+		default:
+			return false
+		}
+	}
+
+	jobs := make(chan int)
+	results := make(chan BatchResult)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil || stopped() {
+					// left for the report to mark as skipped
+					continue
+				}
+				res := c.runOperation(ctx, i, ops[i])
+				if res.Err != nil && opts.FailFast {
+					// stop before picking up another job so nothing new starts
+					stopOnce.Do(func() { close(stop) })
+				}
+				results <- res
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for i := range ops {
+			select {
+			case jobs <- i:
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	report := &BatchReport{Results: make([]BatchResult, len(ops))}
+	ran := make([]bool, len(ops))
+	done := 0
+	for res := range results {
+		done++
+		ran[res.Index] = true
+		report.Results[res.Index] = res
+		if res.Err != nil {
+			report.Failed++
+		} else {
+			report.Succeeded++
+		}
+		if opts.Progress != nil {
+			opts.Progress(done, len(ops), res)
+		}
+	}
+
+	for i := range ops {
+		if !ran[i] {
+			report.Results[i] = BatchResult{Index: i, Operation: ops[i], Err: ErrSkipped}
+			report.Skipped++
+		}
+	}
+	return report
+}
+
+func (c *Client) runOperation(ctx context.Context, index int, op Operation) BatchResult {
+	result := BatchResult{Index: index, Operation: op}
+
+	res, err := c.send(ctx, op)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.StatusCode = res.StatusCode
+	if err := checkResponse(res); err != nil {
+		result.Err = err
+		return result
+	}
+	defer res.Body.Close()
+
+	result.Body, result.Err = ioutil.ReadAll(res.Body)
+	return result
+}
+
+func (c *Client) send(ctx context.Context, op Operation) (*http.Response, error) {
+	if op.Method == "GET" {
+		return c.GetContext(ctx, op.URL, op.Params)
+	}
+
+	body, err := encodeBody(op.Body)
+	if err != nil {
+		return nil, err
+	}
+	switch op.Method {
+	case "POST":
+		return c.PostContext(ctx, op.URL, body)
+	case "PUT":
+		return c.PutContext(ctx, op.URL, body)
+	case "DELETE":
+		return c.DeleteContext(ctx, op.URL, body)
+	}
+	return nil, fmt.Errorf("unsupported batch method %q", op.Method)
+}
+
+// encodeBody turns a request payload into a reader, nil stays nil
+func encodeBody(v interface{}) (io.Reader, error) {
+	switch b := v.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return bytes.NewReader(b), nil
+	case json.RawMessage:
+		return bytes.NewReader(b), nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "Couldn't encode the request body")
+	}
+	return bytes.NewReader(raw), nil
+}
@@ -0,0 +1,125 @@
+package openx
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func pauseOps(n int) []Operation {
+	ops := make([]Operation, n)
+	for i := range ops {
+		ops[i] = Operation{Method: "PUT", URL: "/lineitem/" + strconv.Itoa(i), Body: map[string]string{"status": "Paused"}}
+	}
+	return ops
+}
+
+// TestBatchConcurrency no more than Concurrency operations should ever be in flight
+func TestBatchConcurrency(t *testing.T) {
+	var inFlight, peak int32
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write(body)
+	}))
+
+	var progress int
+	report := c.Batch(context.Background(), pauseOps(20), BatchOptions{
+		Concurrency: 3,
+		Progress: func(done, total int, res BatchResult) {
+			progress++
+			if done != progress || total != 20 {
+				t.Errorf("unexpected progress %d/%d", done, total)
+			}
+		},
+	})
+	if err := report.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if report.Succeeded != 20 || progress != 20 {
+		t.Fatalf("expected 20 successes and progress calls, got %d and %d", report.Succeeded, progress)
+	}
+	if peak > 3 {
+		t.Fatalf("expected at most 3 requests in flight, saw %d", peak)
+	}
+	for i, res := range report.Results {
+		if res.Index != i || string(res.Body) != `{"status":"Paused"}` {
+			t.Fatalf("unexpected result %d: %+v", i, res)
+		}
+	}
+}
+
+// TestBatchContinueOnError failures are reported per item and don't stop the batch
+func TestBatchContinueOnError(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/2") {
+			http.Error(w, "bad line item", http.StatusBadRequest)
+		}
+	}))
+
+	report := c.Batch(context.Background(), pauseOps(5), BatchOptions{Concurrency: 2})
+	if report.Succeeded != 4 || report.Failed != 1 || report.Skipped != 0 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	apiErr, ok := report.Results[2].Err.(*APIError)
+	if !ok || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected an APIError for index 2, got %v", report.Results[2].Err)
+	}
+	if report.Err() == nil || len(report.Failures()) != 1 {
+		t.Fatal("expected the report to carry the failure")
+	}
+}
+
+// TestBatchFailFast operations after the first failure are skipped
+func TestBatchFailFast(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+
+	report := c.Batch(context.Background(), pauseOps(50), BatchOptions{Concurrency: 1, FailFast: true})
+	if report.Failed != 1 || report.Skipped != 49 {
+		t.Fatalf("expected 1 failure and 49 skipped, got %+v", report)
+	}
+	if report.Results[49].Err != ErrSkipped {
+		t.Fatalf("expected the last operation to be skipped, got %v", report.Results[49].Err)
+	}
+	if len(report.Failures()) != 1 || len(report.SkippedResults()) != 49 {
+		t.Fatalf("expected skipped operations apart from failures, got %d and %d", len(report.Failures()), len(report.SkippedResults()))
+	}
+}
+
+// TestBatchFailFastInFlight a failure shouldn't abort the operations already running
+func TestBatchFailFastInFlight(t *testing.T) {
+	started := make(chan struct{})
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/0"):
+			<-started
+			w.WriteHeader(http.StatusForbidden)
+		case strings.HasSuffix(r.URL.Path, "/1"):
+			close(started)
+			time.Sleep(50 * time.Millisecond)
+		}
+	}))
+
+	report := c.Batch(context.Background(), pauseOps(10), BatchOptions{Concurrency: 2, FailFast: true})
+	if report.Results[1].Err != nil {
+		t.Fatalf("expected the operation in flight to finish, got %v", report.Results[1].Err)
+	}
+	if report.Failed != 1 || report.Succeeded != 1 || report.Skipped != 8 {
+		t.Fatalf("expected 1 failure, 1 success and 8 skipped, got %+v", report)
+	}
+}
@@ -0,0 +1,105 @@
+package openx
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Pixel snippet formats
+const (
+	PixelJS    = "js"
+	PixelImage = "image"
+)
+
+// conversionPath is where the delivery servers count conversions
+const conversionPath = "/w/1.0/cv"
+
+// PixelOptions configures the snippet of a conversion tag
+type PixelOptions struct {
+	// DeliveryDomain is the instance's ad serving domain, such as ox-d.example.servedbyopenx.com
+	DeliveryDomain string
+	// Value and Currency override the tag's own for this page, a basket total for instance
+	Value    Decimal
+	Currency string
+	// OrderID is passed along so repeated conversions for the same order are only counted once
+	OrderID string
+	// Insecure serves the pixel over http, it's https otherwise
+	Insecure bool
+}
+
+// Snippet renders the HTML advertisers paste on their conversion page, format is PixelJS or PixelImage.
+// The JS snippet falls back to the image pixel for browsers without JavaScript
+func (m *ConversionTag) Snippet(format string, opts PixelOptions) (string, error) {
+	if m.ID == 0 {
+		return "", errors.New("Couldn't render the pixel: the conversion tag has no id, create it first")
+	}
+	if opts.DeliveryDomain == "" {
+		return "", errors.New("Couldn't render the pixel: no delivery domain")
+	}
+	var errs ValidationErrors
+	errs.amount("value", opts.Value)
+	errs.currency("currency", opts.Currency)
+	if err := errs.err(); err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("cnid", strconv.FormatInt(int64(m.ID), 10))
+	value, currency := m.Value, m.Currency
+	if opts.Value != "" {
+		value = opts.Value
+	}
+	if opts.Currency != "" {
+		currency = opts.Currency
+	}
+	if value != "" {
+		params.Set("cnv", string(value))
+	}
+	if currency != "" {
+		params.Set("cnc", currency)
+	}
+	if opts.OrderID != "" {
+		params.Set("cnr", opts.OrderID)
+	}
+	src := deliveryURL(opts.DeliveryDomain, conversionPath, opts.Insecure, params)
+	image := fmt.Sprintf(`<img src="%s" width="1" height="1" style="display:none" alt="" />`, html.EscapeString(src))
+
+	switch strings.ToLower(format) {
+	case PixelImage:
+		return fmt.Sprintf("<!-- OpenX conversion tag: %s -->\n%s\n", html.EscapeString(m.Name), image), nil
+	case PixelJS:
+		// the random parameter keeps the pixel from being served out of a cache
+		return fmt.Sprintf(`<!-- OpenX conversion tag: %s -->
+<script type="text/javascript">
+(function() {
+  var s = document.createElement("script");
+  s.async = true;
+  s.src = %s + "&cb=" + Math.floor(Math.random() * 1e9);
+  document.getElementsByTagName("head")[0].appendChild(s);
+})();
+</script>
+<noscript>%s</noscript>
+`, html.EscapeString(m.Name), jsString(src), image), nil
+	}
+	return "", errors.Errorf("Couldn't render the pixel: unknown format %q, want %s or %s", format, PixelJS, PixelImage)
+}
+
+// deliveryURL is a url on the ad serving domain
+func deliveryURL(domain, path string, insecure bool, params url.Values) string {
+	u := url.URL{Scheme: "https", Host: domainReplacer.Replace(domain), Path: path, RawQuery: params.Encode()}
+	if insecure {
+		u.Scheme = "http"
+	}
+	return u.String()
+}
+
+// jsString quotes s as a JavaScript string that's also safe inside a script element
+func jsString(s string) string {
+	quoted := strconv.Quote(s)
+	return strings.NewReplacer("<", `\u003c`, ">", `\u003e`).Replace(quoted)
+}
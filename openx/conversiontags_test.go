@@ -0,0 +1,71 @@
+package openx
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestConversionTagSnippet the pixel should carry the tag's id, value and order and escape them
+func TestConversionTagSnippet(t *testing.T) {
+	tag := &ConversionTag{ID: 42, Name: `Checkout <"thanks">`, Value: "19.99", Currency: "USD"}
+
+	image, err := tag.Snippet(PixelImage, PixelOptions{DeliveryDomain: "https://ox-d.example.servedbyopenx.com/", OrderID: "A&B"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<img src="https://ox-d.example.servedbyopenx.com/w/1.0/cv?cnc=USD&amp;cnid=42&amp;cnr=A%26B&amp;cnv=19.99"`,
+		`Checkout &lt;&#34;thanks&#34;&gt;`,
+	} {
+		if !strings.Contains(image, want) {
+			t.Errorf("image snippet %q doesn't contain %q", image, want)
+		}
+	}
+
+	js, err := tag.Snippet("JS", PixelOptions{DeliveryDomain: "ox-d.example.servedbyopenx.com", Value: "5", Currency: "EUR", Insecure: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`s.src = "http://ox-d.example.servedbyopenx.com/w/1.0/cv?cnc=EUR&cnid=42&cnv=5"`,
+		`<noscript><img src="http://ox-d.example.servedbyopenx.com/w/1.0/cv?cnc=EUR&amp;cnid=42&amp;cnv=5"`,
+	} {
+		if !strings.Contains(js, want) {
+			t.Errorf("js snippet %q doesn't contain %q", js, want)
+		}
+	}
+}
+
+// TestConversionTagSnippetErrors unsaved tags, missing domains, bad values and formats should be refused
+func TestConversionTagSnippetErrors(t *testing.T) {
+	opts := PixelOptions{DeliveryDomain: "ox-d.example.servedbyopenx.com"}
+	tests := []struct {
+		tag    ConversionTag
+		format string
+		opts   PixelOptions
+	}{
+		{ConversionTag{}, PixelImage, opts},
+		{ConversionTag{ID: 1}, PixelImage, PixelOptions{}},
+		{ConversionTag{ID: 1}, "flash", opts},
+		{ConversionTag{ID: 1}, PixelJS, PixelOptions{DeliveryDomain: opts.DeliveryDomain, Value: "-1"}},
+		{ConversionTag{ID: 1}, PixelJS, PixelOptions{DeliveryDomain: opts.DeliveryDomain, Currency: "dollars"}},
+	}
+	for i, tt := range tests {
+		if _, err := tt.tag.Snippet(tt.format, tt.opts); err == nil {
+			t.Errorf("%d: got no error", i)
+		}
+	}
+}
+
+// TestConversionTagValidate negative windows and values without a currency should fail validation
+func TestConversionTagValidate(t *testing.T) {
+	tag := &ConversionTag{Name: "signup", AccountUID: "acct-1", ConversionType: "signup", Value: "3", ClickWindowDays: -1}
+	err := tag.Validate()
+	errs, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("got %v, want ValidationErrors", err)
+	}
+	if got := strings.Join(errs.Fields(), ","); got != "currency,click_window_days" {
+		t.Errorf("got fields %s", got)
+	}
+}
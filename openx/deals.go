@@ -0,0 +1,156 @@
+package openx
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Where a deal is in its life, as Deal.State reports it
+const (
+	DealPending  = "pending"
+	DealLive     = "live"
+	DealEnded    = "ended"
+	DealInactive = "inactive"
+)
+
+// DealStatus is a deal's status at a glance
+type DealStatus struct {
+	UID    string
+	DealID string
+	Name   string
+	Status Status
+	// State is DealPending, DealLive, DealEnded or DealInactive
+	State      string
+	FloorPrice Decimal
+	Currency   string
+}
+
+// State tells whether the deal is live at now: inactive unless its status is Active,
+// then pending before its start date, ended after its end date and live in between
+func (m *Deal) State(now time.Time) string {
+	switch {
+	case m.Status != StatusActive:
+		return DealInactive
+	case m.StartDate != nil && !m.StartDate.IsZero() && now.Before(m.StartDate.Time):
+		return DealPending
+	case m.EndDate != nil && !m.EndDate.IsZero() && !now.Before(m.EndDate.Time):
+		return DealEnded
+	}
+	return DealLive
+}
+
+// Statuses lists the status of every deal matching params, account_uid for instance
+func (s *DealService) Statuses(ctx context.Context, params map[string]interface{}) ([]DealStatus, error) {
+	deals, err := s.List(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	statuses := make([]DealStatus, len(deals))
+	for i, d := range deals {
+		statuses[i] = DealStatus{
+			UID:        d.UID,
+			DealID:     d.DealID,
+			Name:       d.Name,
+			Status:     d.Status,
+			State:      d.State(now),
+			FloorPrice: d.FloorPrice,
+			Currency:   d.Currency,
+		}
+	}
+	return statuses, nil
+}
+
+// SetFloor sets the deal's floor price, an empty currency leaves the deal's currency as it is
+func (s *DealService) SetFloor(ctx context.Context, uid string, price Decimal, currency string) (*Deal, error) {
+	var errs ValidationErrors
+	errs.amount("floor_price", price)
+	errs.currency("currency", currency)
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+	fields := Object{"floor_price": string(price)}
+	if currency != "" {
+		fields["currency"] = currency
+	}
+	return s.update(ctx, uid, fields, "set the floor price of")
+}
+
+// SetBuyerSeats replaces the buyer seat ids allowed to bid on the deal
+func (s *DealService) SetBuyerSeats(ctx context.Context, uid string, seats []string) (*Deal, error) {
+	return s.update(ctx, uid, Object{"buyer_seat_ids": seats}, "set the buyer seats of")
+}
+
+// AttachPackage sets the package of ad units and sites the deal is sold on
+func (s *DealService) AttachPackage(ctx context.Context, uid, packageUID string) (*Deal, error) {
+	return s.update(ctx, uid, Object{"package_uid": packageUID}, "attach package "+packageUID+" to")
+}
+
+func (s *DealService) update(ctx context.Context, uid string, fields Object, what string) (*Deal, error) {
+	obj, err := s.api.Update(ctx, TypeDeal, uid, fields)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't %s deal %s", what, uid)
+	}
+	updated := &Deal{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Attach adds ad units and sites to the package, only sending the lists that change
+func (s *PackageService) Attach(ctx context.Context, uid string, adUnitUIDs, siteUIDs []string) (*Package, error) {
+	return s.edit(ctx, uid, func(uids, add []string) []string {
+		have := make(map[string]bool, len(uids))
+		for _, u := range uids {
+			have[u] = true
+		}
+		for _, u := range add {
+			if !have[u] {
+				have[u] = true
+				uids = append(uids, u)
+			}
+		}
+		return uids
+	}, adUnitUIDs, siteUIDs)
+}
+
+// Detach removes ad units and sites from the package, only sending the lists that change
+func (s *PackageService) Detach(ctx context.Context, uid string, adUnitUIDs, siteUIDs []string) (*Package, error) {
+	return s.edit(ctx, uid, func(uids, remove []string) []string {
+		drop := make(map[string]bool, len(remove))
+		for _, u := range remove {
+			drop[u] = true
+		}
+		kept := []string{}
+		for _, u := range uids {
+			if !drop[u] {
+				kept = append(kept, u)
+			}
+		}
+		return kept
+	}, adUnitUIDs, siteUIDs)
+}
+
+// edit applies change to the package's ad unit and site lists and sends the ones that differ
+func (s *PackageService) edit(ctx context.Context, uid string, change func(uids, with []string) []string, adUnitUIDs, siteUIDs []string) (*Package, error) {
+	p, err := s.Get(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	fields := Object{}
+	if adUnits := change(append([]string(nil), p.AdUnitUIDs...), adUnitUIDs); len(adUnits) != len(p.AdUnitUIDs) {
+		fields["adunit_uids"] = adUnits
+	}
+	if sites := change(append([]string(nil), p.SiteUIDs...), siteUIDs); len(sites) != len(p.SiteUIDs) {
+		fields["site_uids"] = sites
+	}
+	if len(fields) == 0 {
+		return p, nil
+	}
+	obj, err := s.api.Update(ctx, TypePackage, uid, fields)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't update the inventory of package %s", uid)
+	}
+	updated := &Package{}
+	return updated, decodeModel(obj, updated)
+}
@@ -0,0 +1,153 @@
+package openx
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// listAPI is a fakeAPI that can list its objects
+type listAPI struct {
+	*fakeAPI
+}
+
+func (l listAPI) List(ctx context.Context, objectType string, params map[string]interface{}) ([]Object, error) {
+	var objects []Object
+	for _, uid := range []string{"deal-1", "deal-2", "deal-3"} {
+		if obj, ok := l.objects[uid]; ok {
+			objects = append(objects, obj.Clone())
+		}
+	}
+	return objects, nil
+}
+
+// TestDealState deals should only be live while active and within their dates
+func TestDealState(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	start, end := NewDate(now.AddDate(0, -1, 0)), NewDate(now.AddDate(0, 1, 0))
+	tests := []struct {
+		deal Deal
+		want string
+	}{
+		{Deal{Status: StatusActive}, DealLive},
+		{Deal{Status: StatusActive, StartDate: start, EndDate: end}, DealLive},
+		{Deal{Status: StatusActive, StartDate: end}, DealPending},
+		{Deal{Status: StatusActive, EndDate: start}, DealEnded},
+		{Deal{Status: StatusInactive, StartDate: start}, DealInactive},
+	}
+	for i, tt := range tests {
+		if got := tt.deal.State(now); got != tt.want {
+			t.Errorf("%d: got %s, want %s", i, got, tt.want)
+		}
+	}
+}
+
+// TestDealUpdates setting the floor, seats and package should only send those fields
+func TestDealUpdates(t *testing.T) {
+	ctx := context.Background()
+	api := listAPI{&fakeAPI{objects: map[string]Object{
+		"deal-1": {"uid": "deal-1", "name": "Premium", "type": "private_auction", "status": "Active", "currency": "USD"},
+		"deal-2": {"uid": "deal-2", "name": "Old", "type": "preferred_deal", "status": "Inactive"},
+	}}}
+	deals := NewDealService(api)
+
+	if _, err := deals.SetFloor(ctx, "deal-1", "2.50", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := deals.SetBuyerSeats(ctx, "deal-1", []string{"seat-1", "seat-2"}); err != nil {
+		t.Fatal(err)
+	}
+	deal, err := deals.AttachPackage(ctx, "deal-1", "pkg-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deal.FloorPrice != "2.50" || deal.Currency != "USD" || deal.PackageUID != "pkg-1" || !reflect.DeepEqual(deal.BuyerSeatIDs, []string{"seat-1", "seat-2"}) {
+		t.Errorf("got %+v", deal)
+	}
+	want := []Object{{"floor_price": "2.50"}, {"buyer_seat_ids": []string{"seat-1", "seat-2"}}, {"package_uid": "pkg-1"}}
+	if !reflect.DeepEqual(api.updates, want) {
+		t.Errorf("sent %v, want %v", api.updates, want)
+	}
+
+	if _, err := deals.SetFloor(ctx, "deal-1", "-1", "usd"); err == nil {
+		t.Error("expected a negative floor in lower case currency to be refused")
+	}
+	if len(api.updates) != 3 {
+		t.Error("an invalid floor was sent")
+	}
+
+	statuses, err := deals.Statuses(ctx, map[string]interface{}{"account_uid": "acct"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 || statuses[0].State != DealLive || statuses[1].State != DealInactive {
+		t.Errorf("got %+v", statuses)
+	}
+}
+
+// TestSetFloorWithValidation a floor without a currency should reach OX3 on a validating client, the deal keeps its own
+func TestSetFloorWithValidation(t *testing.T) {
+	var sent []string
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		sent = append(sent, r.Method+" "+r.URL.Path+" "+string(body))
+		w.Write([]byte(`{"uid": "deal-1", "floor_price": "2.50", "currency": "USD"}`))
+	}), WithValidation())
+
+	deal, err := c.Deals().SetFloor(context.Background(), "deal-1", "2.50", "")
+	if err != nil {
+		t.Fatalf("floor without a currency was refused: %v", err)
+	}
+	if deal.FloorPrice != "2.50" || deal.Currency != "USD" {
+		t.Errorf("got %+v", deal)
+	}
+	if want := []string{"PUT " + apiPath + "deal/deal-1 " + `{"floor_price":"2.50"}`}; !reflect.DeepEqual(sent, want) {
+		t.Errorf("sent %v, want %v", sent, want)
+	}
+}
+
+// TestPackageInventory attaching and detaching should only send the lists that change
+func TestPackageInventory(t *testing.T) {
+	ctx := context.Background()
+	api := &fakeAPI{objects: map[string]Object{
+		"pkg-1": {"uid": "pkg-1", "name": "Sports", "adunit_uids": []interface{}{"au-1"}},
+	}}
+	packages := NewPackageService(api)
+
+	p, err := packages.Attach(ctx, "pkg-1", []string{"au-1", "au-2"}, []string{"site-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p.AdUnitUIDs, []string{"au-1", "au-2"}) || !reflect.DeepEqual(p.SiteUIDs, []string{"site-1"}) {
+		t.Errorf("got %+v", p)
+	}
+	if _, err := packages.Attach(ctx, "pkg-1", []string{"au-2"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := packages.Detach(ctx, "pkg-1", []string{"au-1"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Object{
+		{"adunit_uids": []string{"au-1", "au-2"}, "site_uids": []string{"site-1"}},
+		{"adunit_uids": []string{"au-2"}},
+	}
+	if !reflect.DeepEqual(api.updates, want) {
+		t.Errorf("sent %v, want %v", api.updates, want)
+	}
+}
+
+// TestDealValidation programmatic guaranteed deals need a floor and seats can't be blank
+func TestDealValidation(t *testing.T) {
+	d := &Deal{Name: "PG", AccountUID: "acct", Type: DealTypeProgrammaticGuaranteed, BuyerSeatIDs: []string{"seat-1", " "}}
+	verrs, ok := d.Validate().(ValidationErrors)
+	if !ok {
+		t.Fatal("expected ValidationErrors")
+	}
+	if got := verrs.Fields(); !reflect.DeepEqual(got, []string{"floor_price", "buyer_seat_ids[1]"}) {
+		t.Errorf("got %v", got)
+	}
+}
@@ -0,0 +1,205 @@
+// Package diff compares two snapshots of an OX3 account, from backup directories or read live,
+// and reports the objects added, removed or modified with the old and new value of every changed field.
+// Objects are matched by type and uid
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/marcsantiago/OX3-Go-API-Client/openx/backup"
+)
+
+// DefaultIgnore are the fields OX3 changes on its own, left out of a comparison unless Options.Ignore is set
+var DefaultIgnore = []string{"modified_date"}
+
+// Kind is what happened to an object between two snapshots
+type Kind string
+
+// Kinds of change
+const (
+	Added    Kind = "added"
+	Removed  Kind = "removed"
+	Modified Kind = "modified"
+)
+
+// FieldChange is a field whose value differs, Old or New is nil when the field is missing on that side
+type FieldChange struct {
+	// Field is the path of the field, nested object fields are joined with dots
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// Change is an object that differs between two snapshots
+type Change struct {
+	Kind Kind   `json:"kind"`
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+	Name string `json:"name,omitempty"`
+	// Fields lists the changed fields of a modified object, ordered by path
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// Report is every difference between two snapshots, ordered by type, parents first, then uid
+type Report struct {
+	Changes []Change `json:"changes"`
+}
+
+// Empty reports whether the snapshots match
+func (r *Report) Empty() bool {
+	return len(r.Changes) == 0
+}
+
+// Count returns how many objects had kind of change
+func (r *Report) Count(kind Kind) int {
+	var n int
+	for _, c := range r.Changes {
+		if c.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// Options controls a comparison
+type Options struct {
+	// Ignore are fields left out of the comparison at any depth, DefaultIgnore when nil
+	Ignore []string
+	// Types limits the comparison to some object types, all of them when empty
+	Types []string
+}
+
+// Compare reports how to get from old to new
+func Compare(old, new *backup.Snapshot, opts Options) *Report {
+	ignore := opts.Ignore
+	if ignore == nil {
+		ignore = DefaultIgnore
+	}
+	ignored := map[string]bool{}
+	for _, f := range ignore {
+		ignored[f] = true
+	}
+	wanted := map[string]bool{}
+	for _, t := range opts.Types {
+		wanted[t] = true
+	}
+
+	report := &Report{}
+	for _, kind := range openx.Kinds() {
+		if len(wanted) > 0 && !wanted[kind.Type] {
+			continue
+		}
+		report.Changes = append(report.Changes, compareType(kind.Type, objectsOf(old, kind.Type), objectsOf(new, kind.Type), ignored)...)
+	}
+	return report
+}
+
+// objectsOf indexes a snapshot's objects of objectType by uid, the account counts as one
+func objectsOf(s *backup.Snapshot, objectType string) map[string]openx.Object {
+	objects := map[string]openx.Object{}
+	if objectType == openx.TypeAccount {
+		if s.Account != nil {
+			objects[s.Account.UID()] = s.Account
+		}
+		return objects
+	}
+	for _, obj := range s.Objects[objectType] {
+		objects[obj.UID()] = obj
+	}
+	return objects
+}
+
+func compareType(objectType string, old, new map[string]openx.Object, ignored map[string]bool) []Change {
+	uids := make([]string, 0, len(old)+len(new))
+	for uid := range old {
+		uids = append(uids, uid)
+	}
+	for uid := range new {
+		if _, ok := old[uid]; !ok {
+			uids = append(uids, uid)
+		}
+	}
+	sort.Strings(uids)
+
+	var changes []Change
+	for _, uid := range uids {
+		before, hadIt := old[uid]
+		after, hasIt := new[uid]
+		switch {
+		case !hadIt:
+			changes = append(changes, Change{Kind: Added, Type: objectType, UID: uid, Name: after.String("name")})
+		case !hasIt:
+			changes = append(changes, Change{Kind: Removed, Type: objectType, UID: uid, Name: before.String("name")})
+		default:
+			var fields []FieldChange
+			compareMaps("", before, after, ignored, &fields)
+			if len(fields) > 0 {
+				changes = append(changes, Change{Kind: Modified, Type: objectType, UID: uid, Name: after.String("name"), Fields: fields})
+			}
+		}
+	}
+	return changes
+}
+
+// compareMaps appends the differences between two objects, walking into nested objects.
+// Lists are compared whole
+func compareMaps(prefix string, old, new map[string]interface{}, ignored map[string]bool, out *[]FieldChange) {
+	keys := make([]string, 0, len(old)+len(new))
+	for k := range old {
+		keys = append(keys, k)
+	}
+	for k := range new {
+		if _, ok := old[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if ignored[k] {
+			continue
+		}
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		o, n := old[k], new[k]
+		om, oIsMap := asMap(o)
+		nm, nIsMap := asMap(n)
+		if oIsMap && nIsMap {
+			compareMaps(path, om, nm, ignored, out)
+			continue
+		}
+		if !reflect.DeepEqual(o, n) {
+			*out = append(*out, FieldChange{Field: path, Old: o, New: n})
+		}
+	}
+}
+
+func asMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case openx.Object:
+		return m, true
+	}
+	return nil, false
+}
+
+// summary is the closing line of every format
+func (r *Report) summary() string {
+	return fmt.Sprintf("%d added, %d removed, %d modified.", r.Count(Added), r.Count(Removed), r.Count(Modified))
+}
+
+// label names an object in the output
+func (c Change) label() string {
+	label := c.Type + " " + c.UID
+	if c.Name != "" {
+		label += " (" + strings.ReplaceAll(c.Name, "\n", " ") + ")"
+	}
+	return label
+}
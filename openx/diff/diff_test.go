@@ -0,0 +1,130 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/marcsantiago/OX3-Go-API-Client/openx/backup"
+)
+
+func snapshot(account openx.Object, objects map[string][]openx.Object) *backup.Snapshot {
+	return &backup.Snapshot{Manifest: backup.Manifest{Account: account.UID()}, Account: account, Objects: objects}
+}
+
+func testSnapshots() (*backup.Snapshot, *backup.Snapshot) {
+	account := openx.Object{"uid": "acct", "name": "publisher"}
+	old := snapshot(account, map[string][]openx.Object{
+		openx.TypeSite: {
+			{"uid": "s1", "name": "example.com", "url": "http://example.com", "modified_date": "2024-01-01 00:00:00"},
+			{"uid": "s2", "name": "gone.com"},
+		},
+		openx.TypeLineItem: {
+			{"uid": "l1", "name": "spring", "targeting": map[string]interface{}{"geo": "US", "os": "ios"}},
+		},
+	})
+	new := snapshot(account, map[string][]openx.Object{
+		openx.TypeSite: {
+			{"uid": "s1", "name": "example.com", "url": "https://example.com", "modified_date": "2024-02-01 00:00:00"},
+			{"uid": "s3", "name": "new.com"},
+		},
+		openx.TypeLineItem: {
+			{"uid": "l1", "name": "spring", "targeting": map[string]interface{}{"geo": "CA", "os": "ios"}, "budget": 100.0},
+		},
+	})
+	return old, new
+}
+
+func TestCompare(t *testing.T) {
+	old, new := testSnapshots()
+	report := Compare(old, new, Options{})
+
+	want := []Change{
+		{Kind: Modified, Type: openx.TypeSite, UID: "s1", Name: "example.com", Fields: []FieldChange{
+			{Field: "url", Old: "http://example.com", New: "https://example.com"},
+		}},
+		{Kind: Removed, Type: openx.TypeSite, UID: "s2", Name: "gone.com"},
+		{Kind: Added, Type: openx.TypeSite, UID: "s3", Name: "new.com"},
+		{Kind: Modified, Type: openx.TypeLineItem, UID: "l1", Name: "spring", Fields: []FieldChange{
+			{Field: "budget", New: 100.0},
+			{Field: "targeting.geo", Old: "US", New: "CA"},
+		}},
+	}
+	if !reflect.DeepEqual(report.Changes, want) {
+		t.Errorf("got %+v\nwant %+v", report.Changes, want)
+	}
+
+	if r := Compare(old, old, Options{}); !r.Empty() {
+		t.Errorf("a snapshot differs from itself: %+v", r.Changes)
+	}
+
+	// with nothing ignored the modified_date shows up
+	r := Compare(old, new, Options{Ignore: []string{}, Types: []string{openx.TypeSite}})
+	if len(r.Changes) != 3 || len(r.Changes[0].Fields) != 2 || r.Changes[0].Fields[0].Field != "modified_date" {
+		t.Errorf("got %+v, want modified_date compared and only sites", r.Changes)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	old, new := testSnapshots()
+	report := Compare(old, new, Options{})
+
+	var text bytes.Buffer
+	if err := report.Write(&text, Text); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`~ site s1 (example.com)`,
+		`    url: "http://example.com" => "https://example.com"`,
+		`- site s2 (gone.com)`,
+		`+ site s3 (new.com)`,
+		`    budget: (unset) => 100`,
+		`1 added, 1 removed, 2 modified.`,
+	} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("text output is missing %q:\n%s", want, text.String())
+		}
+	}
+
+	var md bytes.Buffer
+	if err := report.Write(&md, Markdown); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"### Modified site s1 (example.com)", "| `targeting.geo` | `\"US\"` | `\"CA\"` |", "| `budget` | _unset_ | `100` |"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown output is missing %q:\n%s", want, md.String())
+		}
+	}
+
+	var js bytes.Buffer
+	if err := report.Write(&js, JSON); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Changes) != 4 || decoded.Changes[3].Fields[1].Old != "US" {
+		t.Errorf("JSON output decoded to %+v", decoded)
+	}
+
+	var empty bytes.Buffer
+	(&Report{}).Write(&empty, JSON)
+	if !strings.Contains(empty.String(), `"changes": []`) {
+		t.Errorf("empty report encoded as %s", empty.String())
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]Format{"text": Text, "JSON": JSON, "md": Markdown, "markdown": Markdown} {
+		if got, err := ParseFormat(name); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v", name, got, err)
+		}
+	}
+	if _, err := ParseFormat("yaml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
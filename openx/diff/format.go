@@ -0,0 +1,110 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format is an output format for a Report
+type Format string
+
+// Supported formats
+const (
+	Text     Format = "text"
+	JSON     Format = "json"
+	Markdown Format = "markdown"
+)
+
+// ParseFormat checks a format name such as one given on the command line
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case Text, JSON, Markdown:
+		return f, nil
+	case "md":
+		return Markdown, nil
+	}
+	return "", fmt.Errorf("unknown diff format %q, use text, json or markdown", name)
+}
+
+// Write renders the report in format
+func (r *Report) Write(w io.Writer, format Format) error {
+	switch format {
+	case Text, "":
+		return r.writeText(w)
+	case JSON:
+		return r.writeJSON(w)
+	case Markdown:
+		return r.writeMarkdown(w)
+	}
+	return fmt.Errorf("unknown diff format %q", format)
+}
+
+func (r *Report) writeText(w io.Writer) error {
+	symbols := map[Kind]string{Added: "+", Removed: "-", Modified: "~"}
+	var b strings.Builder
+	for _, c := range r.Changes {
+		fmt.Fprintf(&b, "%s %s\n", symbols[c.Kind], c.label())
+		for _, f := range c.Fields {
+			fmt.Fprintf(&b, "    %s: %s => %s\n", f.Field, formatValue(f.Old), formatValue(f.New))
+		}
+	}
+	if len(r.Changes) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString(r.summary() + "\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (r *Report) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	out := *r
+	if out.Changes == nil {
+		out.Changes = []Change{}
+	}
+	return enc.Encode(out)
+}
+
+func (r *Report) writeMarkdown(w io.Writer) error {
+	headings := map[Kind]string{Added: "Added", Removed: "Removed", Modified: "Modified"}
+	var b strings.Builder
+	b.WriteString("## OX3 diff\n\n" + r.summary() + "\n")
+	for _, c := range r.Changes {
+		fmt.Fprintf(&b, "\n### %s %s\n", headings[c.Kind], markdownEscape(c.label()))
+		if len(c.Fields) == 0 {
+			continue
+		}
+		b.WriteString("\n| Field | Old | New |\n| --- | --- | --- |\n")
+		for _, f := range c.Fields {
+			fmt.Fprintf(&b, "| `%s` | %s | %s |\n", f.Field, markdownCell(f.Old), markdownCell(f.New))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func formatValue(v interface{}) string {
+	if v == nil {
+		return "(unset)"
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(raw)
+}
+
+func markdownCell(v interface{}) string {
+	if v == nil {
+		return "_unset_"
+	}
+	// pipes would end the cell even inside code spans
+	return "`" + strings.NewReplacer("`", "'", "|", `\|`).Replace(formatValue(v)) + "`"
+}
+
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`").Replace(s)
+}
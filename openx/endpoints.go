@@ -0,0 +1,52 @@
+package openx
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// SSO handshake steps reported by SSOStep
+const (
+	SSORequestToken = "request_token"
+	SSOLogin        = "login"
+	SSOAccessToken  = "access_token"
+)
+
+var (
+	numericID = regexp.MustCompile(`^[0-9]+$`)
+	uuidID    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// SSOStep reports which step of the SSO handshake req belongs to,
+// an empty string means req is a regular API call
+func SSOStep(req *http.Request) string {
+	u := *req.URL
+	u.RawQuery = ""
+	switch u.String() {
+	case requestTokenURL:
+		return SSORequestToken
+	case authorizationURL:
+		return SSOLogin
+	case accessTokenURL:
+		return SSOAccessToken
+	}
+	return ""
+}
+
+// EndpointTemplate turns a request url into a low cardinality endpoint name,
+// the api path is dropped and ids are replaced so /data/1.0/lineitem/12345 becomes /lineitem/{id}
+func EndpointTemplate(u *url.URL) string {
+	p := strings.TrimPrefix(u.Path, strings.TrimSuffix(apiPath, "/"))
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		if numericID.MatchString(s) || uuidID.MatchString(s) {
+			segments[i] = "{id}"
+		}
+	}
+	if p = strings.Join(segments, "/"); p == "" {
+		return "/"
+	}
+	return p
+}
@@ -0,0 +1,41 @@
+package openx
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestEndpointTemplate(t *testing.T) {
+	var cases = []struct {
+		URL  string
+		Want string
+	}{
+		{"http://ox.example.com/data/1.0/lineitem/12345", "/lineitem/{id}"},
+		{"http://ox.example.com/data/1.0/account", "/account"},
+		{"http://ox.example.com/data/1.0/adunit/6003a3f8-f0e7-fff1-8123-0c6a5b1e2e7f/targeting", "/adunit/{id}/targeting"},
+		{"https://sso.openx.com/api/index/token", "/api/index/token"},
+		{"http://ox.example.com", "/"},
+	}
+	for _, c := range cases {
+		u, _ := url.Parse(c.URL)
+		if got := EndpointTemplate(u); got != c.Want {
+			t.Errorf("EndpointTemplate(%s) = %s, expected %s", c.URL, got, c.Want)
+		}
+	}
+}
+
+func TestSSOStep(t *testing.T) {
+	var cases = map[string]string{
+		requestTokenURL:                       SSORequestToken,
+		authorizationURL:                      SSOLogin,
+		accessTokenURL + "?oauth_token=token": SSOAccessToken,
+		"http://ox.example.com/data/1.0/site": "",
+	}
+	for u, want := range cases {
+		req, _ := http.NewRequest("POST", u, nil)
+		if got := SSOStep(req); got != want {
+			t.Errorf("SSOStep(%s) = %q, expected %q", u, got, want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package openx
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// maxErrorBody is how much of a failed response body is kept on an APIError
+const maxErrorBody = 4 << 10
+
+// APIError is returned by helpers that check the response for you when OX3 answers with a non 2xx status
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       []byte
+}
+
+func (e *APIError) Error() string {
+	if len(e.Body) == 0 {
+		return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("%s %s: %d %s: %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode), e.Body)
+}
+
+// CheckResponse turns a non 2xx response into an *APIError with the url's secrets redacted,
+// the body is closed in that case. It's for packages sending requests through a Client's verb methods
+func CheckResponse(res *http.Response) error {
+	return checkResponse(res)
+}
+
+// checkResponse turns a non 2xx response into an *APIError, the body is closed in that case
+func checkResponse(res *http.Response) error {
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+	defer res.Body.Close()
+	body, _ := ioutil.ReadAll(io.LimitReader(res.Body, maxErrorBody))
+	err := &APIError{StatusCode: res.StatusCode, Body: body}
+	if res.Request != nil {
+		err.Method = res.Request.Method
+		err.URL = redactURL(res.Request.URL)
+	}
+	return err
+}
@@ -0,0 +1,224 @@
+package openx
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// forecastEndpoint takes forecast requests, results are read from forecast/{id}
+const forecastEndpoint = "forecast"
+
+// Forecast statuses
+const (
+	ForecastPending = "pending"
+	ForecastReady   = "ready"
+	ForecastFailed  = "failed"
+)
+
+const (
+	defaultForecastPollInterval    = 2 * time.Second
+	defaultForecastMaxPollInterval = 30 * time.Second
+)
+
+// ForecastRequest is the line item like spec a forecast is made for
+type ForecastRequest struct {
+	AccountUID string `json:"account_uid,omitempty"`
+	// Targeting has the shape of a line item's targeting
+	Targeting    map[string]interface{} `json:"targeting,omitempty"`
+	StartDate    *Date                  `json:"start_date,omitempty"`
+	EndDate      *Date                  `json:"end_date,omitempty"`
+	PricingModel PricingModel           `json:"pricing_model,omitempty"`
+	Price        Decimal                `json:"price,omitempty"`
+	Currency     string                 `json:"currency,omitempty"`
+	// Impressions is the goal the forecast is checked against, 0 just asks what's available
+	Impressions Int `json:"impressions,omitempty"`
+	// Breakdowns are the dimensions results are split by, such as day or a targeting dimension like geo
+	Breakdowns []string `json:"breakdowns,omitempty"`
+}
+
+// ForecastRequestFor is a forecast request for the dates, pricing and targeting of a line item
+func ForecastRequestFor(li *LineItem) *ForecastRequest {
+	return &ForecastRequest{
+		AccountUID:   li.AccountUID,
+		Targeting:    li.Targeting,
+		StartDate:    li.StartDate,
+		EndDate:      li.EndDate,
+		PricingModel: li.PricingModel,
+		Price:        li.Price,
+		Currency:     li.Currency,
+	}
+}
+
+// Validate checks the request with the rules line items follow
+func (r *ForecastRequest) Validate() error {
+	var errs ValidationErrors
+	errs.required("start_date", r.StartDate == nil)
+	errs.dateOrder("start_date", r.StartDate, "end_date", r.EndDate)
+	errs.oneOf("pricing_model", string(r.PricingModel), r.PricingModel.Valid(), pricingModelValues)
+	errs.amount("price", r.Price)
+	errs.currency("currency", r.Currency)
+	errs.priced("price", r.Price, "currency", r.Currency)
+	errs.targeting("targeting", r.Targeting)
+	if r.Impressions < 0 {
+		errs.add("impressions", RuleAmount, "can't be negative")
+	}
+	return errs.err()
+}
+
+// Availability is how many impressions a forecast found, in total or for one breakdown value
+type Availability struct {
+	// Dimension and Key say which breakdown value it's for, day and 2024-06-01 for instance, both empty for the total
+	Dimension string `json:"dimension,omitempty"`
+	Key       string `json:"key,omitempty"`
+	// Available impressions are free to book, Contended ones are already wanted by other line items
+	Available Int `json:"available"`
+	Contended Int `json:"contended"`
+}
+
+// Forecast is the result of a forecast request
+type Forecast struct {
+	ID     string `json:"forecast_id"`
+	Status string `json:"status"`
+	// Message says why a forecast failed
+	Message string `json:"message,omitempty"`
+	Availability
+	Breakdowns []Availability `json:"breakdowns,omitempty"`
+	// Impressions is the goal of the request
+	Impressions Int `json:"impressions,omitempty"`
+}
+
+// CanDeliver reports whether the available impressions cover n
+func (f *Forecast) CanDeliver(n int64) bool {
+	return int64(f.Available) >= n
+}
+
+// Breakdown returns the breakdown values of dimension in the order OX3 gave them
+func (f *Forecast) Breakdown(dimension string) []Availability {
+	var values []Availability
+	for _, b := range f.Breakdowns {
+		if b.Dimension == dimension {
+			values = append(values, b)
+		}
+	}
+	return values
+}
+
+// ForecastService asks OX3 how many impressions it can deliver
+type ForecastService struct {
+	api ObjectAPI
+	// PollInterval is the first wait between checks of a pending forecast, it doubles after every check
+	// up to MaxPollInterval. 2 seconds and 30 seconds when zero
+	PollInterval    time.Duration
+	MaxPollInterval time.Duration
+}
+
+// NewForecastService returns a forecast service sending requests through api
+func NewForecastService(api ObjectAPI) *ForecastService {
+	return &ForecastService{api: api, PollInterval: defaultForecastPollInterval, MaxPollInterval: defaultForecastMaxPollInterval}
+}
+
+// Forecasts returns the forecast service
+func (c *Client) Forecasts() *ForecastService {
+	return NewForecastService(c)
+}
+
+// Run submits req and waits for its result
+func (s *ForecastService) Run(ctx context.Context, req *ForecastRequest) (*Forecast, error) {
+	id, err := s.Submit(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.Wait(ctx, id)
+}
+
+// Submit validates req and sends it, the returned id is for Result and Wait
+func (s *ForecastService) Submit(ctx context.Context, req *ForecastRequest) (string, error) {
+	if err := req.Validate(); err != nil {
+		return "", err
+	}
+	v, err := verbsOf(s.api, "request forecasts")
+	if err != nil {
+		return "", err
+	}
+	body, err := encodeBody(req)
+	if err != nil {
+		return "", err
+	}
+	res, err := v.PostContext(ctx, forecastEndpoint, body)
+	if err != nil {
+		return "", errors.Wrap(err, "Couldn't request the forecast")
+	}
+	raw, err := readResponse(res)
+	if err != nil {
+		return "", errors.Wrap(err, "Couldn't request the forecast")
+	}
+	var f Forecast
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return "", errors.Wrap(err, "Couldn't decode the forecast request")
+	}
+	if f.ID == "" {
+		return "", errors.New("Couldn't request the forecast: OX3 returned no forecast_id")
+	}
+	return f.ID, nil
+}
+
+// Result checks on the forecast with id once, it's pending until its Status is ForecastReady
+func (s *ForecastService) Result(ctx context.Context, id string) (*Forecast, error) {
+	v, err := verbsOf(s.api, "read forecasts")
+	if err != nil {
+		return nil, err
+	}
+	res, err := v.GetContext(ctx, forecastEndpoint+"/"+id, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't get forecast %s", id)
+	}
+	raw, err := readResponse(res)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't get forecast %s", id)
+	}
+	f := &Forecast{}
+	if err := json.Unmarshal(raw, f); err != nil {
+		return nil, errors.Wrapf(err, "Couldn't decode forecast %s", id)
+	}
+	if f.ID == "" {
+		f.ID = id
+	}
+	return f, nil
+}
+
+// Wait checks on the forecast with id until it's ready, failed or ctx is done, put a deadline on ctx to bound it
+func (s *ForecastService) Wait(ctx context.Context, id string) (*Forecast, error) {
+	wait, ceiling := s.PollInterval, s.MaxPollInterval
+	if wait <= 0 {
+		wait = defaultForecastPollInterval
+	}
+	if ceiling <= 0 {
+		ceiling = defaultForecastMaxPollInterval
+	}
+	for {
+		f, err := s.Result(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		switch f.Status {
+		case ForecastReady:
+			return f, nil
+		case ForecastFailed:
+			return f, errors.Errorf("Forecast %s failed: %s", id, f.Message)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Wrapf(ctx.Err(), "Couldn't wait for forecast %s", id)
+		case <-timer.C:
+		}
+		if wait *= 2; wait > ceiling {
+			wait = ceiling
+		}
+	}
+}
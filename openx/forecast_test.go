@@ -0,0 +1,106 @@
+package openx
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestForecastRun the request should be posted and polled until ready, with breakdowns decoded
+func TestForecastRun(t *testing.T) {
+	var posted map[string]interface{}
+	polls := 0
+	c := newSessionClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/forecast"):
+			raw, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(raw, &posted)
+			w.Write([]byte(`{"forecast_id": "f-1", "status": "pending"}`))
+		case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/forecast/f-1"):
+			if polls++; polls < 3 {
+				w.Write([]byte(`{"forecast_id": "f-1", "status": "pending"}`))
+				return
+			}
+			w.Write([]byte(`{"forecast_id": "f-1", "status": "ready", "available": "150000", "contended": 20000,
+				"breakdowns": [{"dimension": "day", "key": "2024-06-01", "available": 70000, "contended": 5000},
+					{"dimension": "day", "key": "2024-06-02", "available": 80000, "contended": 15000},
+					{"dimension": "geo", "key": "US", "available": 150000, "contended": 20000}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	li := &LineItem{
+		AccountUID:   "acct-1",
+		StartDate:    NewDate(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)),
+		EndDate:      NewDate(time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)),
+		PricingModel: PricingModelCPM,
+		Price:        "2.00",
+		Currency:     "USD",
+		Targeting:    map[string]interface{}{"geo": map[string]interface{}{"op": "INTERSECTS", "val": []interface{}{"US"}}},
+	}
+	req := ForecastRequestFor(li)
+	req.Impressions = 100000
+	req.Breakdowns = []string{"day", "geo"}
+
+	forecasts := c.Forecasts()
+	forecasts.PollInterval = time.Millisecond
+	f, err := forecasts.Run(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if polls != 3 {
+		t.Errorf("got %d polls, want 3", polls)
+	}
+	if posted["account_uid"] != "acct-1" || posted["impressions"] != float64(100000) || posted["targeting"] == nil {
+		t.Errorf("posted %v", posted)
+	}
+	if f.Available != 150000 || f.Contended != 20000 || !f.CanDeliver(100000) || f.CanDeliver(200000) {
+		t.Errorf("got forecast %+v", f)
+	}
+	days := f.Breakdown("day")
+	if len(days) != 2 || days[1].Key != "2024-06-02" || days[1].Contended != 15000 {
+		t.Errorf("got day breakdown %+v", days)
+	}
+}
+
+// TestForecastFailures invalid requests shouldn't be sent, failed and unfinished forecasts should be errors
+func TestForecastFailures(t *testing.T) {
+	posts := 0
+	c := newSessionClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST":
+			posts++
+		case strings.HasSuffix(r.URL.Path, "/forecast/bad"):
+			w.Write([]byte(`{"status": "failed", "message": "no inventory matches the targeting"}`))
+		default:
+			w.Write([]byte(`{"status": "pending"}`))
+		}
+	})
+	forecasts := c.Forecasts()
+	forecasts.PollInterval = time.Millisecond
+	ctx := context.Background()
+
+	_, err := forecasts.Submit(ctx, &ForecastRequest{Price: "1", Targeting: map[string]interface{}{"geo": "US"}})
+	errs, ok := err.(ValidationErrors)
+	if !ok || strings.Join(errs.Fields(), ",") != "start_date,currency,targeting.geo" {
+		t.Errorf("got %v, want validation errors", err)
+	}
+	if posts != 0 {
+		t.Errorf("got %d posts for an invalid request", posts)
+	}
+
+	if _, err := forecasts.Wait(ctx, "bad"); err == nil || !strings.Contains(err.Error(), "no inventory matches") {
+		t.Errorf("got %v, want the failure message", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := forecasts.Wait(ctx, "slow"); err == nil {
+		t.Error("expected waiting past the deadline to fail")
+	}
+}
@@ -0,0 +1,315 @@
+// Package httpcache caches OX3 GET responses through openx middleware.
+//
+// Responses are kept in memory, least recently used first out, and optionally on disk so they survive restarts.
+// How long a response stays fresh is set per endpoint, once stale it's revalidated with its ETag or
+// Last-Modified when OX3 sent one and served again if OX3 answers 304 Not Modified.
+// A successful POST, PUT or DELETE drops every cached response for the resource it wrote to,
+// the resources under it and the collection it belongs to
+//
+//	cache, err := httpcache.New(httpcache.Options{
+//		TTL:  time.Minute,
+//		TTLs: map[string]time.Duration{"/account": time.Hour, "/report/{id}": 0},
+//		Dir:  "/var/cache/ox3",
+//	})
+//	client, err := openx.NewClient(creds, false, openx.WithMiddleware(cache.Middleware()))
+//
+// The cache key is the request method and URL with its query sorted, give clients logged in with different credentials their own Cache and Dir
+package httpcache
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Options configures a Cache
+type Options struct {
+	// TTL is how long responses stay fresh, for endpoints TTLs doesn't list. Zero caches nothing by default
+	TTL time.Duration
+	// TTLs overrides TTL by endpoint template as openx.EndpointTemplate names them, /site/{id} for instance.
+	// A zero TTL keeps an endpoint out of the cache
+	TTLs map[string]time.Duration
+	// MaxEntries caps the responses kept in memory, 1000 when zero
+	MaxEntries int
+	// Dir keeps responses on disk too when set, it's created when missing
+	Dir string
+}
+
+// Stats counts what the cache did
+type Stats struct {
+	// Hits are requests answered from the cache without asking OX3
+	Hits int64
+	// Revalidations are stale responses OX3 confirmed with a 304
+	Revalidations int64
+	// Misses are cacheable requests that went to OX3
+	Misses int64
+	// Invalidations are responses dropped because of a write
+	Invalidations int64
+	// Evictions are responses dropped from memory to stay under MaxEntries
+	Evictions int64
+}
+
+// Cache is a GET response cache shared by the clients using its middleware
+type Cache struct {
+	opts   Options
+	now    func() time.Time
+	mu     sync.Mutex
+	memory *lru
+	disk   *disk
+	stats  Stats
+}
+
+// New returns a Cache, failing when Dir can't be created
+func New(opts Options) (*Cache, error) {
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = 1000
+	}
+	c := &Cache{opts: opts, now: time.Now}
+	c.memory = newLRU(opts.MaxEntries, func() { atomic.AddInt64(&c.stats.Evictions, 1) })
+	if opts.Dir != "" {
+		d, err := newDisk(opts.Dir)
+		if err != nil {
+			return nil, err
+		}
+		c.disk = d
+	}
+	return c, nil
+}
+
+// Stats returns the counters so far
+func (c *Cache) Stats() Stats {
+	return Stats{
+		Hits:          atomic.LoadInt64(&c.stats.Hits),
+		Revalidations: atomic.LoadInt64(&c.stats.Revalidations),
+		Misses:        atomic.LoadInt64(&c.stats.Misses),
+		Invalidations: atomic.LoadInt64(&c.stats.Invalidations),
+		Evictions:     atomic.LoadInt64(&c.stats.Evictions),
+	}
+}
+
+// Purge drops every cached response
+func (c *Cache) Purge() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.memory.purge()
+	if c.disk != nil {
+		return c.disk.purge()
+	}
+	return nil
+}
+
+// Middleware serves GETs from the cache and invalidates it on writes.
+// Add it before openx.Retry so a cached response skips the retries too
+func (c *Cache) Middleware() openx.Middleware {
+	return func(next openx.RoundTripFunc) openx.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if openx.SSOStep(req) != "" {
+				return next(req)
+			}
+			switch req.Method {
+			case http.MethodGet:
+				return c.get(req, next)
+			case http.MethodHead, http.MethodOptions:
+				return next(req)
+			}
+
+			res, err := next(req)
+			if err == nil && res.StatusCode >= 200 && res.StatusCode < 300 {
+				c.invalidate(req.URL)
+			}
+			return res, err
+		}
+	}
+}
+
+func (c *Cache) ttl(u *url.URL) time.Duration {
+	if ttl, ok := c.opts.TTLs[openx.EndpointTemplate(u)]; ok {
+		return ttl
+	}
+	return c.opts.TTL
+}
+
+func (c *Cache) get(req *http.Request, next openx.RoundTripFunc) (*http.Response, error) {
+	ttl := c.ttl(req.URL)
+	if ttl <= 0 {
+		return next(req)
+	}
+	key := cacheKey(req)
+	now := c.now()
+
+	e := c.lookup(key)
+	if e != nil && now.Before(e.Expires) {
+		atomic.AddInt64(&c.stats.Hits, 1)
+		return e.response(req), nil
+	}
+
+	out := req
+	if e != nil && (e.Header.Get("ETag") != "" || e.Header.Get("Last-Modified") != "") {
+		out = req.Clone(req.Context())
+		if etag := e.Header.Get("ETag"); etag != "" {
+			out.Header.Set("If-None-Match", etag)
+		}
+		if modified := e.Header.Get("Last-Modified"); modified != "" {
+			out.Header.Set("If-Modified-Since", modified)
+		}
+	}
+
+	res, err := next(out)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotModified && e != nil {
+		res.Body.Close()
+		atomic.AddInt64(&c.stats.Revalidations, 1)
+		// entries are shared with concurrent readers, the refreshed one is a copy
+		fresh := *e
+		fresh.Expires = now.Add(ttl)
+		c.store(key, &fresh)
+		return fresh.response(req), nil
+	}
+	atomic.AddInt64(&c.stats.Misses, 1)
+	if res.StatusCode != http.StatusOK || noStore(res.Header) {
+		return res, nil
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+	c.store(key, &entry{
+		Key:     key,
+		Status:  res.StatusCode,
+		Header:  res.Header.Clone(),
+		Body:    body,
+		Expires: now.Add(ttl),
+	})
+	return res, nil
+}
+
+func noStore(h http.Header) bool {
+	cc := strings.ToLower(h.Get("Cache-Control"))
+	return strings.Contains(cc, "no-store") || strings.Contains(cc, "private")
+}
+
+func (c *Cache) lookup(key string) *entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e := c.memory.get(key); e != nil {
+		return e
+	}
+	if c.disk == nil {
+		return nil
+	}
+	e := c.disk.get(key)
+	if e != nil {
+		c.memory.put(key, e)
+	}
+	return e
+}
+
+func (c *Cache) store(key string, e *entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.memory.put(key, e)
+	if c.disk != nil {
+		// a response that can't be written to disk is still cached in memory
+		c.disk.put(key, e)
+	}
+}
+
+// cacheKey is the method and the url with its query parameters sorted,
+// GetContext builds queries from maps so the same parameters come in any order
+func cacheKey(req *http.Request) string {
+	u := *req.URL
+	u.Fragment = ""
+	u.RawQuery = u.Query().Encode()
+	return req.Method + " " + u.String()
+}
+
+// keyURL is the url of a cache key
+func keyURL(key string) (*url.URL, error) {
+	_, raw, ok := strings.Cut(key, " ")
+	if !ok {
+		return nil, fmt.Errorf("%q isn't a cache key", key)
+	}
+	return url.Parse(raw)
+}
+
+// invalidate drops the responses a write to u may have changed:
+// u itself, everything under it and the collection it's in, whatever their query
+func (c *Cache) invalidate(u *url.URL) {
+	p := path.Clean("/" + u.Path)
+	parent := path.Dir(p)
+	affected := func(key string) bool {
+		k, err := keyURL(key)
+		if err != nil || k.Host != u.Host {
+			return false
+		}
+		kp := path.Clean("/" + k.Path)
+		return kp == p || strings.HasPrefix(kp, p+"/") || kp == parent
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := c.memory.removeIf(affected)
+	if c.disk != nil {
+		if d := c.disk.invalidate(u.Host, p); d > n {
+			n = d
+		}
+	}
+	atomic.AddInt64(&c.stats.Invalidations, int64(n))
+}
+
+// entry is a cached response
+type entry struct {
+	Key     string      `json:"key"`
+	Status  int         `json:"status"`
+	Header  http.Header `json:"header"`
+	Body    []byte      `json:"body"`
+	Expires time.Time   `json:"expires"`
+}
+
+func (e *entry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// Collectors returns Prometheus counters reading the cache's Stats, register them alongside metrics.Metrics
+func (c *Cache) Collectors() []prometheus.Collector {
+	counter := func(name, help string, v *int64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: "ox3",
+			Subsystem: "cache",
+			Name:      name,
+			Help:      help,
+		}, func() float64 { return float64(atomic.LoadInt64(v)) })
+	}
+	return []prometheus.Collector{
+		counter("hits_total", "OX3 GETs answered from the cache.", &c.stats.Hits),
+		counter("revalidations_total", "Stale cached OX3 responses confirmed with a 304.", &c.stats.Revalidations),
+		counter("misses_total", "Cacheable OX3 GETs sent to OX3.", &c.stats.Misses),
+		counter("invalidations_total", "Cached OX3 responses dropped because of a write.", &c.stats.Invalidations),
+		counter("evictions_total", "Cached OX3 responses dropped from memory to stay under MaxEntries.", &c.stats.Evictions),
+	}
+}
@@ -0,0 +1,261 @@
+package httpcache
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+)
+
+const api = "https://api.example.com/data/1.0"
+
+// fakeOX3 answers with a body counting the calls for each path, sending an ETag
+// and honouring If-None-Match when etags is set
+type fakeOX3 struct {
+	etags bool
+	calls map[string]int
+	seen  []*http.Request
+}
+
+func (f *fakeOX3) roundTrip(req *http.Request) (*http.Response, error) {
+	if f.calls == nil {
+		f.calls = map[string]int{}
+	}
+	f.seen = append(f.seen, req)
+	rec := httptest.NewRecorder()
+	if req.Method != http.MethodGet {
+		f.calls[req.URL.Path]++
+		rec.WriteHeader(http.StatusOK)
+		return rec.Result(), nil
+	}
+	etag := fmt.Sprintf(`"%d"`, f.calls[req.URL.Path])
+	if f.etags {
+		if req.Header.Get("If-None-Match") == etag {
+			rec.WriteHeader(http.StatusNotModified)
+			return rec.Result(), nil
+		}
+		rec.Header().Set("ETag", etag)
+	}
+	rec.WriteHeader(http.StatusOK)
+	fmt.Fprintf(rec, "%s %d", req.URL.Path, f.calls[req.URL.Path])
+	return rec.Result(), nil
+}
+
+func newTestCache(t *testing.T, opts Options) (*Cache, *fakeOX3, *http.Client, *time.Time) {
+	c, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	f := &fakeOX3{}
+	return c, f, &http.Client{Transport: c.Middleware()(f.roundTrip)}, &now
+}
+
+func get(t *testing.T, client *http.Client, path string) string {
+	res, err := client.Get(api + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: got status %d", path, res.StatusCode)
+	}
+	return string(body)
+}
+
+func write(t *testing.T, client *http.Client, method, path string) {
+	req, _ := http.NewRequest(method, api+path, strings.NewReader("{}"))
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+}
+
+// TestHitsWithinTTL a response should be served from memory until its ttl runs out
+func TestHitsWithinTTL(t *testing.T) {
+	c, f, client, now := newTestCache(t, Options{TTL: time.Minute})
+
+	get(t, client, "/site/1")
+	get(t, client, "/site/1")
+	if len(f.seen) != 1 {
+		t.Fatalf("got %d requests to OX3, want 1", len(f.seen))
+	}
+
+	*now = now.Add(2 * time.Minute)
+	get(t, client, "/site/1")
+	if len(f.seen) != 2 {
+		t.Fatalf("got %d requests to OX3 after the ttl, want 2", len(f.seen))
+	}
+	if s := c.Stats(); s.Hits != 1 || s.Misses != 2 {
+		t.Errorf("got %+v", s)
+	}
+}
+
+// TestQueryOrder a list with the same parameters in another order, as GetContext builds them from a map,
+// should be served from the same entry
+func TestQueryOrder(t *testing.T) {
+	for _, dir := range []string{"", "disk"} {
+		t.Run("dir="+dir, func(t *testing.T) {
+			opts := Options{TTL: time.Minute}
+			if dir != "" {
+				opts.Dir = t.TempDir()
+			}
+			_, f, client, _ := newTestCache(t, opts)
+
+			get(t, client, "/site?account_uid=a&limit=500&status=Active")
+			get(t, client, "/site?status=Active&account_uid=a&limit=500")
+			if len(f.seen) != 1 {
+				t.Fatalf("got %d requests to OX3, want 1", len(f.seen))
+			}
+			get(t, client, "/site?status=Inactive&account_uid=a&limit=500")
+			if len(f.seen) != 2 {
+				t.Fatalf("got %d requests to OX3 for other parameters, want 2", len(f.seen))
+			}
+		})
+	}
+}
+
+// TestEndpointTTLs a zero ttl for an endpoint should keep it out of the cache
+func TestEndpointTTLs(t *testing.T) {
+	_, f, client, _ := newTestCache(t, Options{TTL: time.Minute, TTLs: map[string]time.Duration{"/report/{id}": 0}})
+
+	get(t, client, "/report/7")
+	get(t, client, "/report/7")
+	get(t, client, "/account")
+	get(t, client, "/account")
+	if len(f.seen) != 3 {
+		t.Errorf("got %d requests to OX3, want 3", len(f.seen))
+	}
+}
+
+// TestRevalidation a stale response with an ETag should be confirmed with If-None-Match and served again
+func TestRevalidation(t *testing.T) {
+	c, f, client, now := newTestCache(t, Options{TTL: time.Minute})
+	f.etags = true
+
+	first := get(t, client, "/site/1")
+	*now = now.Add(2 * time.Minute)
+	if got := get(t, client, "/site/1"); got != first {
+		t.Errorf("got %q after a 304, want %q", got, first)
+	}
+	if inm := f.seen[1].Header.Get("If-None-Match"); inm != `"0"` {
+		t.Errorf("got If-None-Match %q", inm)
+	}
+	// the refreshed entry is fresh again
+	get(t, client, "/site/1")
+	if len(f.seen) != 2 {
+		t.Errorf("got %d requests to OX3, want 2", len(f.seen))
+	}
+	if s := c.Stats(); s.Revalidations != 1 || s.Hits != 1 {
+		t.Errorf("got %+v", s)
+	}
+}
+
+// TestInvalidation a write should drop the resource, what's under it and its collection but nothing else
+func TestInvalidation(t *testing.T) {
+	for _, dir := range []string{"", "disk"} {
+		t.Run("dir="+dir, func(t *testing.T) {
+			opts := Options{TTL: time.Hour}
+			if dir != "" {
+				opts.Dir = t.TempDir()
+			}
+			c, f, client, _ := newTestCache(t, opts)
+
+			paths := []string{"/site/1", "/site/1/adunit", "/site?account_uid=a", "/site/2", "/account"}
+			for _, p := range paths {
+				get(t, client, p)
+			}
+			write(t, client, http.MethodPut, "/site/1")
+
+			for _, p := range paths {
+				get(t, client, p)
+			}
+			want := map[string]int{"/site/1": 2, "/site/1/adunit": 2, "/site": 2, "/site/2": 1, "/account": 1}
+			got := map[string]int{}
+			for _, req := range f.seen {
+				if req.Method == http.MethodGet {
+					got[strings.TrimPrefix(req.URL.Path, "/data/1.0")]++
+				}
+			}
+			for p, n := range want {
+				if got[p] != n {
+					t.Errorf("%s: got %d GETs to OX3, want %d", p, got[p], n)
+				}
+			}
+			if s := c.Stats(); s.Invalidations != 3 {
+				t.Errorf("got %d invalidations, want 3", s.Invalidations)
+			}
+		})
+	}
+}
+
+// TestDisk responses on disk should outlive the Cache that stored them
+func TestDisk(t *testing.T) {
+	dir := t.TempDir()
+	_, _, client, _ := newTestCache(t, Options{TTL: time.Hour, Dir: dir})
+	first := get(t, client, "/site/1?fields=name")
+
+	c, f, client, _ := newTestCache(t, Options{TTL: time.Hour, Dir: dir})
+	if got := get(t, client, "/site/1?fields=name"); got != first {
+		t.Errorf("got %q, want %q", got, first)
+	}
+	if len(f.seen) != 0 || c.Stats().Hits != 1 {
+		t.Errorf("got %d requests to OX3 and %+v", len(f.seen), c.Stats())
+	}
+
+	if err := c.Purge(); err != nil {
+		t.Fatal(err)
+	}
+	get(t, client, "/site/1?fields=name")
+	if len(f.seen) != 1 {
+		t.Errorf("got %d requests to OX3 after Purge, want 1", len(f.seen))
+	}
+}
+
+// TestEviction memory should keep the most recently used entries
+func TestEviction(t *testing.T) {
+	c, f, client, _ := newTestCache(t, Options{TTL: time.Hour, MaxEntries: 2})
+
+	get(t, client, "/site/1")
+	get(t, client, "/site/2")
+	get(t, client, "/site/1")
+	get(t, client, "/site/3")
+	get(t, client, "/site/1")
+	get(t, client, "/site/2")
+	if len(f.seen) != 4 {
+		t.Errorf("got %d requests to OX3, want 4", len(f.seen))
+	}
+	if s := c.Stats(); s.Evictions != 2 {
+		t.Errorf("got %d evictions, want 2", s.Evictions)
+	}
+}
+
+// TestSkipsSSO the SSO handshake should always reach OX3
+func TestSkipsSSO(t *testing.T) {
+	_, f, client, _ := newTestCache(t, Options{TTL: time.Hour})
+	req, _ := http.NewRequest(http.MethodGet, "https://sso.openx.com/login/process", nil)
+	if openx.SSOStep(req) == "" {
+		t.Fatal("not an SSO request")
+	}
+	for i := 0; i < 2; i++ {
+		res, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+	if len(f.seen) != 2 {
+		t.Errorf("got %d requests to OX3, want 2", len(f.seen))
+	}
+}
@@ -0,0 +1,179 @@
+package httpcache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// lru keeps the most recently used entries in memory
+type lru struct {
+	max     int
+	order   *list.List
+	items   map[string]*list.Element
+	onEvict func()
+}
+
+type lruItem struct {
+	key   string
+	entry *entry
+}
+
+func newLRU(max int, onEvict func()) *lru {
+	return &lru{max: max, order: list.New(), items: map[string]*list.Element{}, onEvict: onEvict}
+}
+
+func (l *lru) get(key string) *entry {
+	el, ok := l.items[key]
+	if !ok {
+		return nil
+	}
+	l.order.MoveToFront(el)
+	return el.Value.(*lruItem).entry
+}
+
+func (l *lru) put(key string, e *entry) {
+	if el, ok := l.items[key]; ok {
+		el.Value.(*lruItem).entry = e
+		l.order.MoveToFront(el)
+		return
+	}
+	l.items[key] = l.order.PushFront(&lruItem{key: key, entry: e})
+	for l.order.Len() > l.max {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.items, oldest.Value.(*lruItem).key)
+		l.onEvict()
+	}
+}
+
+func (l *lru) removeIf(match func(key string) bool) int {
+	var n int
+	for key, el := range l.items {
+		if match(key) {
+			l.order.Remove(el)
+			delete(l.items, key)
+			n++
+		}
+	}
+	return n
+}
+
+func (l *lru) purge() {
+	l.order.Init()
+	l.items = map[string]*list.Element{}
+}
+
+// disk keeps entries as JSON files laid out like the URLs they came from,
+// <dir>/<host>/<path segments>/<hash of the url>.json, so a write only touches the directories of its resource
+type disk struct {
+	dir string
+}
+
+func newDisk(dir string) (*disk, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrapf(err, "Couldn't create the cache directory %s", dir)
+	}
+	return &disk{dir: dir}, nil
+}
+
+// resourceDir is the directory holding the entries of host and path p
+func (d *disk) resourceDir(host, p string) string {
+	parts := []string{d.dir, url.PathEscape(host)}
+	for _, segment := range strings.Split(strings.Trim(path.Clean("/"+p), "/"), "/") {
+		if segment != "" {
+			parts = append(parts, url.PathEscape(segment))
+		}
+	}
+	return filepath.Join(parts...)
+}
+
+func (d *disk) file(key string) (string, error) {
+	u, err := keyURL(key)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.resourceDir(u.Host, u.Path), hex.EncodeToString(sum[:])+".json"), nil
+}
+
+func (d *disk) get(key string) *entry {
+	name, err := d.file(key)
+	if err != nil {
+		return nil
+	}
+	raw, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil
+	}
+	var e entry
+	// a corrupt file is a miss, the next store replaces it
+	if json.Unmarshal(raw, &e) != nil || e.Key != key {
+		return nil
+	}
+	return &e
+}
+
+func (d *disk) put(key string, e *entry) error {
+	name, err := d.file(key)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0700); err != nil {
+		return err
+	}
+	tmp := name + ".tmp"
+	if err := ioutil.WriteFile(tmp, raw, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+// invalidate removes the entries of p, of everything under p and of p's collection, returning how many
+func (d *disk) invalidate(host, p string) int {
+	n := 0
+	own := d.resourceDir(host, p)
+	filepath.Walk(own, func(name string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && strings.HasSuffix(name, ".json") {
+			n++
+		}
+		return nil
+	})
+	os.RemoveAll(own)
+
+	// only the collection's own entries, not those of its other members
+	parent := d.resourceDir(host, path.Dir(path.Clean("/"+p)))
+	files, _ := filepath.Glob(filepath.Join(parent, "*.json"))
+	for _, name := range files {
+		if os.Remove(name) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+func (d *disk) purge() error {
+	entries, err := ioutil.ReadDir(d.dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := os.RemoveAll(filepath.Join(d.dir, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
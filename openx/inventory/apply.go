@@ -0,0 +1,156 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/pkg/errors"
+)
+
+// RollbackFailure is a change that couldn't be undone
+type RollbackFailure struct {
+	Change Change
+	Err    error
+}
+
+// ApplyReport describes what Apply did
+type ApplyReport struct {
+	// Applied are the changes that went through, in order
+	Applied []Change
+	// Failed is the change that stopped the apply, nil when everything succeeded
+	Failed *Change
+	Err    error
+	// RolledBack are the applied changes that were undone after the failure, in the order they were undone.
+	// Deleted objects are recreated from their last known fields and come back with a new uid
+	RolledBack []Change
+	// RollbackFailures are applied changes that couldn't be undone and need a hand
+	RollbackFailures []RollbackFailure
+}
+
+// Apply carries out plan in order. When a change fails the changes already applied are rolled back
+// in reverse order and the returned error describes the failure
+func Apply(ctx context.Context, api openx.ObjectAPI, plan *Plan) (*ApplyReport, error) {
+	uids := make(map[string]string, len(plan.uids))
+	for k, v := range plan.uids {
+		uids[k] = v
+	}
+
+	report := &ApplyReport{}
+	// undo holds a rollback step for every applied change,
+	// remap tracks the new uids of objects recreated while rolling back so their children point at them
+	var undo []func(context.Context) error
+	remap := map[string]string{}
+
+	for i := range plan.Changes {
+		change := plan.Changes[i]
+		rollback, err := apply(ctx, api, &change, uids, remap)
+		if err != nil {
+			report.Failed = &change
+			report.Err = err
+			break
+		}
+		report.Applied = append(report.Applied, change)
+		undo = append(undo, rollback)
+	}
+
+	if report.Failed == nil {
+		return report, nil
+	}
+
+	// the rollback still runs when ctx was what stopped the apply
+	rollbackCtx := context.WithoutCancel(ctx)
+	for i := len(undo) - 1; i >= 0; i-- {
+		if err := undo[i](rollbackCtx); err != nil {
+			report.RollbackFailures = append(report.RollbackFailures, RollbackFailure{Change: report.Applied[i], Err: err})
+			continue
+		}
+		report.RolledBack = append(report.RolledBack, report.Applied[i])
+	}
+
+	return report, errors.Wrapf(report.Err, "Couldn't %s %s %q, rolled back %d of %d applied changes",
+		report.Failed.Action, report.Failed.Type, report.Failed.Key, len(report.RolledBack), len(report.Applied))
+}
+
+// apply makes a single change, filling in uids of objects created earlier in the plan,
+// and returns the step that undoes it
+func apply(ctx context.Context, api openx.ObjectAPI, change *Change, uids, remap map[string]string) (func(context.Context) error, error) {
+	fields := change.Fields.Clone()
+	if change.parent != "" {
+		uid, ok := uids[change.parent]
+		if !ok {
+			return nil, fmt.Errorf("site %q has no uid", change.parent)
+		}
+		fields["site_uid"] = uid
+	}
+	if change.members != nil {
+		members := make([]interface{}, len(change.members))
+		for i, key := range change.members {
+			uid, ok := uids[key]
+			if !ok {
+				return nil, fmt.Errorf("ad unit %q has no uid", key)
+			}
+			members[i] = uid
+		}
+		fields[groupMembersField] = members
+	}
+	change.Fields = fields
+
+	switch change.Action {
+	case Create:
+		created, err := api.Create(ctx, change.Type, fields)
+		if err != nil {
+			return nil, err
+		}
+		change.UID = created.UID()
+		uids[change.Key] = change.UID
+		return func(ctx context.Context) error {
+			return api.Remove(ctx, change.Type, change.UID)
+		}, nil
+
+	case Update:
+		if _, err := api.Update(ctx, change.Type, change.UID, fields); err != nil {
+			return nil, err
+		}
+		previous := openx.Object{}
+		for k := range fields {
+			previous[k] = change.Before[k]
+		}
+		return func(ctx context.Context) error {
+			_, err := api.Update(ctx, change.Type, change.UID, previous)
+			return err
+		}, nil
+
+	case Delete:
+		if err := api.Remove(ctx, change.Type, change.UID); err != nil {
+			return nil, err
+		}
+		delete(uids, change.Key)
+		return func(ctx context.Context) error {
+			restore := change.Before.Clone()
+			for _, f := range openx.GeneratedFields {
+				delete(restore, f)
+			}
+			if uid, ok := remap[restore.String("site_uid")]; ok {
+				restore["site_uid"] = uid
+			}
+			if members, ok := restore[groupMembersField].([]interface{}); ok {
+				remapped := make([]interface{}, len(members))
+				for i, m := range members {
+					remapped[i] = m
+					if uid, ok := remap[fmt.Sprint(m)]; ok {
+						remapped[i] = uid
+					}
+				}
+				restore[groupMembersField] = remapped
+			}
+			created, err := api.Create(ctx, change.Type, restore)
+			if err != nil {
+				return err
+			}
+			remap[change.UID] = created.UID()
+			return nil
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown action %q", change.Action)
+}
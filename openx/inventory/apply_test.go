@@ -0,0 +1,98 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+)
+
+// TestApply applying a plan should leave nothing left to plan
+func TestApply(t *testing.T) {
+	spec, err := Parse([]byte(exampleSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := seededStore()
+	ctx := context.Background()
+
+	plan, err := Compute(ctx, store, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Apply(ctx, store, plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Applied) != len(plan.Changes) {
+		t.Fatalf("expected every change to be applied, got %d of %d", len(report.Applied), len(plan.Changes))
+	}
+
+	// the new ad unit hangs off the existing site and joined the group
+	var sidebar openx.Object
+	for _, unit := range store.Objects(openx.TypeAdUnit) {
+		if unit.String("name") == "sidebar" {
+			sidebar = unit
+		}
+	}
+	if sidebar == nil || sidebar.String("site_uid") != "site-1" {
+		t.Fatalf("expected sidebar under site-1, got %v", sidebar)
+	}
+	group := store.Objects(openx.TypeAdUnitGroup)[0]
+	if members := group["adunit_uids"].([]interface{}); len(members) != 2 || members[1] != sidebar.UID() {
+		t.Fatalf("unexpected group members %v", members)
+	}
+
+	again, err := Compute(ctx, store, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !again.Empty() {
+		t.Fatalf("expected an empty plan after apply, got %+v", again.Changes)
+	}
+}
+
+// TestApplyRollback a failure mid apply undoes what was already done
+func TestApplyRollback(t *testing.T) {
+	spec, err := Parse([]byte(exampleSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := seededStore()
+	ctx := context.Background()
+
+	plan, err := Compute(ctx, store, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := len(store.Objects(openx.TypeAdUnit))
+
+	// the site is deleted last, failing it means its ad unit and footer must be recreated
+	boom := errors.New("boom")
+	store.FailOn("remove", openx.TypeSite, boom)
+	report, err := Apply(ctx, store, plan)
+	if err == nil || report.Err != boom {
+		t.Fatalf("expected the apply to fail with boom, got %v", err)
+	}
+	if report.Failed.Type != openx.TypeSite || len(report.RollbackFailures) != 0 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if len(report.RolledBack) != len(report.Applied) {
+		t.Fatalf("expected every applied change to be rolled back, got %d of %d", len(report.RolledBack), len(report.Applied))
+	}
+
+	sites := store.Objects(openx.TypeSite)
+	if sites[0].String("url") != "http://old.example.com" {
+		t.Fatalf("expected the site update to be undone, got %v", sites[0])
+	}
+	units := store.Objects(openx.TypeAdUnit)
+	if len(units) != before {
+		t.Fatalf("expected %d ad units after rollback, got %d", before, len(units))
+	}
+	for _, unit := range units {
+		if unit.String("name") == "sidebar" {
+			t.Fatal("expected the new ad unit to be removed")
+		}
+	}
+}
@@ -0,0 +1,325 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/pkg/errors"
+)
+
+// groupMembersField is the ad unit group field listing its ad unit uids
+const groupMembersField = "adunit_uids"
+
+// Action is what a Change does to an object
+type Action string
+
+// Actions a plan can take
+const (
+	Create Action = "create"
+	Update Action = "update"
+	Delete Action = "delete"
+)
+
+// Change is one step of a Plan
+type Change struct {
+	Action Action
+	// Type is the OX3 object type, one of openx.TypeSite, openx.TypeAdUnit or openx.TypeAdUnitGroup
+	Type string
+	// Key names the object, "<site>/<ad unit>" for ad units
+	Key string
+	// UID of the object being updated or deleted
+	UID string
+	// Fields are sent to OX3, every field of a new object or only the changed ones of an update
+	Fields openx.Object
+	// Before is the object as OX3 had it, nil for creates
+	Before openx.Object
+
+	// parent is the key of the site a new ad unit belongs to
+	parent string
+	// members are the ad unit keys an ad unit group should hold
+	members []string
+}
+
+// Plan is the ordered list of changes that brings OX3 in line with a Spec.
+// Creates and updates come first, parents before children, then deletes, children before parents
+type Plan struct {
+	Account string
+	Changes []Change
+
+	// uids of the objects that already exist by key
+	uids map[string]string
+}
+
+// Empty reports whether OX3 already matches the spec
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// Count returns how many changes take action
+func (p *Plan) Count(action Action) int {
+	var n int
+	for _, c := range p.Changes {
+		if c.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Write prints the plan for review
+func (p *Plan) Write(w io.Writer) error {
+	symbols := map[Action]string{Create: "+", Update: "~", Delete: "-"}
+	for _, c := range p.Changes {
+		header := fmt.Sprintf("%s %s %q", symbols[c.Action], c.Type, c.Key)
+		if c.UID != "" {
+			header += " (" + c.UID + ")"
+		}
+		if _, err := fmt.Fprintln(w, header); err != nil {
+			return err
+		}
+
+		if c.Action == Delete {
+			continue
+		}
+		for _, field := range sortedKeys(c.Fields) {
+			line := "    " + field + ": "
+			if c.Action == Update {
+				line += formatValue(c.Before[field]) + " => "
+			}
+			line += formatValue(c.Fields[field])
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+		if len(c.members) > 0 {
+			if _, err := fmt.Fprintf(w, "    ad units: %v\n", c.members); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "\nPlan: %d to create, %d to update, %d to delete.\n",
+		p.Count(Create), p.Count(Update), p.Count(Delete))
+	return err
+}
+
+// Compute reads the current state of spec's account through api and plans the changes needed to reach spec
+func Compute(ctx context.Context, api openx.ObjectAPI, spec *Spec) (*Plan, error) {
+	filter := map[string]interface{}{"account_uid": spec.Account}
+	sites, err := api.List(ctx, openx.TypeSite, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "Couldn't list sites")
+	}
+	units, err := api.List(ctx, openx.TypeAdUnit, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "Couldn't list ad units")
+	}
+	groups, err := api.List(ctx, openx.TypeAdUnitGroup, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "Couldn't list ad unit groups")
+	}
+
+	plan := &Plan{Account: spec.Account, uids: map[string]string{}}
+
+	// index what exists by key
+	currentSites := map[string]openx.Object{}
+	siteNames := map[string]string{}
+	for _, site := range sites {
+		currentSites[site.String("name")] = site
+		siteNames[site.UID()] = site.String("name")
+	}
+	currentUnits := map[string]openx.Object{}
+	unitKeys := map[string]string{}
+	for _, unit := range units {
+		site, ok := siteNames[unit.String("site_uid")]
+		if !ok {
+			continue
+		}
+		key := adUnitKey(site, unit.String("name"))
+		currentUnits[key] = unit
+		unitKeys[unit.UID()] = key
+	}
+	currentGroups := map[string]openx.Object{}
+	for _, group := range groups {
+		currentGroups[group.String("name")] = group
+	}
+
+	// sites and their ad units
+	for _, site := range spec.Sites {
+		current, exists := currentSites[site.Name]
+		if !exists {
+			fields := openx.Object{"name": site.Name, "account_uid": spec.Account}
+			merge(fields, site.Fields)
+			plan.Changes = append(plan.Changes, Change{Action: Create, Type: openx.TypeSite, Key: site.Name, Fields: fields})
+		} else {
+			plan.uids[site.Name] = current.UID()
+			if changed := diffFields(site.Fields, current); len(changed) > 0 {
+				plan.Changes = append(plan.Changes, Change{Action: Update, Type: openx.TypeSite, Key: site.Name, UID: current.UID(), Fields: changed, Before: current})
+			}
+		}
+
+		for _, unit := range site.AdUnits {
+			key := adUnitKey(site.Name, unit.Name)
+			current, exists := currentUnits[key]
+			if !exists {
+				fields := openx.Object{"name": unit.Name, "account_uid": spec.Account}
+				merge(fields, unit.Fields)
+				plan.Changes = append(plan.Changes, Change{Action: Create, Type: openx.TypeAdUnit, Key: key, Fields: fields, parent: site.Name})
+				continue
+			}
+			plan.uids[key] = current.UID()
+			if changed := diffFields(unit.Fields, current); len(changed) > 0 {
+				plan.Changes = append(plan.Changes, Change{Action: Update, Type: openx.TypeAdUnit, Key: key, UID: current.UID(), Fields: changed, Before: current})
+			}
+		}
+	}
+
+	// ad unit groups
+	for _, group := range spec.AdUnitGroups {
+		current, exists := currentGroups[group.Name]
+		if !exists {
+			fields := openx.Object{"name": group.Name, "account_uid": spec.Account}
+			merge(fields, group.Fields)
+			plan.Changes = append(plan.Changes, Change{Action: Create, Type: openx.TypeAdUnitGroup, Key: group.Name, Fields: fields, members: group.AdUnits})
+			continue
+		}
+		plan.uids[group.Name] = current.UID()
+		changed := diffFields(group.Fields, current)
+		var members []string
+		if !sameMembers(group.AdUnits, current, plan.uids) {
+			members = group.AdUnits
+		}
+		if len(changed) > 0 || members != nil {
+			plan.Changes = append(plan.Changes, Change{Action: Update, Type: openx.TypeAdUnitGroup, Key: group.Name, UID: current.UID(), Fields: changed, Before: current, members: members})
+		}
+	}
+
+	if !spec.Prune {
+		return plan, nil
+	}
+
+	// deletes run children first
+	var deletes []Change
+	wantGroups := map[string]bool{}
+	for _, group := range spec.AdUnitGroups {
+		wantGroups[group.Name] = true
+	}
+	for _, group := range groups {
+		if !wantGroups[group.String("name")] {
+			deletes = append(deletes, Change{Action: Delete, Type: openx.TypeAdUnitGroup, Key: group.String("name"), UID: group.UID(), Before: group})
+		}
+	}
+
+	wantUnits := map[string]bool{}
+	wantSites := map[string]bool{}
+	for _, site := range spec.Sites {
+		wantSites[site.Name] = true
+		for _, unit := range site.AdUnits {
+			wantUnits[adUnitKey(site.Name, unit.Name)] = true
+		}
+	}
+	for _, unit := range units {
+		key, ok := unitKeys[unit.UID()]
+		if ok && !wantUnits[key] {
+			deletes = append(deletes, Change{Action: Delete, Type: openx.TypeAdUnit, Key: key, UID: unit.UID(), Before: unit})
+		}
+	}
+	for _, site := range sites {
+		if !wantSites[site.String("name")] {
+			deletes = append(deletes, Change{Action: Delete, Type: openx.TypeSite, Key: site.String("name"), UID: site.UID(), Before: site})
+		}
+	}
+
+	plan.Changes = append(plan.Changes, deletes...)
+	return plan, nil
+}
+
+// diffFields returns the desired fields whose value differs from current
+func diffFields(desired map[string]interface{}, current openx.Object) openx.Object {
+	changed := openx.Object{}
+	for k, v := range desired {
+		if !equal(v, current[k]) {
+			changed[k] = v
+		}
+	}
+	return changed
+}
+
+// sameMembers compares the ad units a group should hold with the ones it has,
+// a member that doesn't exist yet always counts as a difference
+func sameMembers(want []string, group openx.Object, uids map[string]string) bool {
+	have := map[string]bool{}
+	if list, ok := group[groupMembersField].([]interface{}); ok {
+		for _, uid := range list {
+			have[fmt.Sprint(uid)] = true
+		}
+	}
+	if len(have) != len(want) {
+		return false
+	}
+	for _, key := range want {
+		uid, ok := uids[key]
+		if !ok || !have[uid] {
+			return false
+		}
+	}
+	return true
+}
+
+// equal compares a value from YAML with one decoded from JSON,
+// scalars are compared as text since OX3 returns many numbers as strings
+func equal(desired, current interface{}) bool {
+	d, c := jsonValue(desired), jsonValue(current)
+	switch d.(type) {
+	case map[string]interface{}, []interface{}:
+		return reflect.DeepEqual(d, c)
+	}
+	if d == nil || c == nil {
+		return d == c
+	}
+	return fmt.Sprint(d) == fmt.Sprint(c)
+}
+
+// jsonValue round trips v through JSON so both sides of a comparison share types
+func jsonValue(v interface{}) interface{} {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+func merge(dst openx.Object, src map[string]interface{}) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+func formatValue(v interface{}) string {
+	if v == nil {
+		return "(unset)"
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(raw)
+}
+
+func sortedKeys(m openx.Object) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,90 @@
+package inventory
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/marcsantiago/OX3-Go-API-Client/openx/openxtest"
+)
+
+func seededStore() *openxtest.Store {
+	store := openxtest.NewStore()
+	store.Seed(openx.TypeSite,
+		openx.Object{"uid": "site-1", "name": "example.com", "account_uid": "acct", "url": "http://old.example.com"},
+		openx.Object{"uid": "site-2", "name": "retired.com", "account_uid": "acct"},
+	)
+	store.Seed(openx.TypeAdUnit,
+		openx.Object{"uid": "unit-1", "name": "leaderboard", "site_uid": "site-1", "account_uid": "acct", "type": "web", "primary_size": "728x90", "tag_settings": map[string]interface{}{"secure": true}},
+		openx.Object{"uid": "unit-2", "name": "footer", "site_uid": "site-1", "account_uid": "acct"},
+		openx.Object{"uid": "unit-3", "name": "old", "site_uid": "site-2", "account_uid": "acct"},
+	)
+	store.Seed(openx.TypeAdUnitGroup,
+		openx.Object{"uid": "group-1", "name": "banners", "account_uid": "acct", "adunit_uids": []interface{}{"unit-1"}},
+	)
+	return store
+}
+
+func TestCompute(t *testing.T) {
+	spec, err := Parse([]byte(exampleSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := Compute(context.Background(), seededStore(), spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, c := range plan.Changes {
+		got = append(got, string(c.Action)+" "+c.Type+" "+c.Key)
+	}
+	want := []string{
+		"update site example.com",
+		"create adunit example.com/sidebar",
+		"update adunitgroup banners",
+		"delete adunit example.com/footer",
+		"delete adunit retired.com/old",
+		"delete site retired.com",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected plan:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if plan.Changes[0].Fields["url"] != "http://example.com" || len(plan.Changes[0].Fields) != 1 {
+		t.Fatalf("expected only the url to change, got %v", plan.Changes[0].Fields)
+	}
+
+	var buf bytes.Buffer
+	if err := plan.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		`~ site "example.com" (site-1)`,
+		`    url: "http://old.example.com" => "http://example.com"`,
+		`+ adunit "example.com/sidebar"`,
+		`- site "retired.com" (site-2)`,
+		"Plan: 1 to create, 2 to update, 3 to delete.",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("expected %q in plan output:\n%s", line, out)
+		}
+	}
+}
+
+// TestComputeNoPrune objects missing from the spec are left alone unless prune is set
+func TestComputeNoPrune(t *testing.T) {
+	spec, err := Parse([]byte(strings.Replace(exampleSpec, "prune: true", "prune: false", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := Compute(context.Background(), seededStore(), spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Count(Delete) != 0 {
+		t.Fatalf("expected no deletes without prune, got %d", plan.Count(Delete))
+	}
+}
@@ -0,0 +1,189 @@
+// Package inventory manages OX3 sites, ad units and ad unit groups declaratively.
+// A Spec read from YAML describes the desired objects, Compute diffs it against what OX3 holds
+// and Apply carries the resulting Plan out in dependency order.
+//
+//	account_uid: 6003a3f8-f0e7-fff1-8123-0c6a5b1e2e7f
+//	prune: false
+//	sites:
+//	  - name: example.com
+//	    url: http://example.com
+//	    ad_units:
+//	      - name: leaderboard
+//	        type: web
+//	        primary_size: 728x90
+//	ad_unit_groups:
+//	  - name: leaderboards
+//	    ad_units: [example.com/leaderboard]
+//
+// Objects are matched to OX3 by name, ad units by site and name.
+// Every other key is sent to OX3 as an object field
+package inventory
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Spec is the desired state of an account's inventory
+type Spec struct {
+	Account string `yaml:"account_uid"`
+	// Prune deletes objects that exist in OX3 but not in the spec
+	Prune        bool          `yaml:"prune"`
+	Sites        []Site        `yaml:"sites"`
+	AdUnitGroups []AdUnitGroup `yaml:"ad_unit_groups"`
+}
+
+// Site is a desired site and the ad units under it
+type Site struct {
+	Name    string                 `yaml:"name"`
+	AdUnits []AdUnit               `yaml:"ad_units"`
+	Fields  map[string]interface{} `yaml:",inline"`
+}
+
+// AdUnit is a desired ad unit
+type AdUnit struct {
+	Name   string                 `yaml:"name"`
+	Fields map[string]interface{} `yaml:",inline"`
+}
+
+// AdUnitGroup is a desired ad unit group, members are named "<site>/<ad unit>"
+type AdUnitGroup struct {
+	Name    string                 `yaml:"name"`
+	AdUnits []string               `yaml:"ad_units"`
+	Fields  map[string]interface{} `yaml:",inline"`
+}
+
+// Load reads and validates a spec from a YAML file
+func Load(path string) (*Spec, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't read the file: %s", path)
+	}
+	return Parse(raw)
+}
+
+// Parse decodes and validates a YAML spec
+func Parse(raw []byte) (*Spec, error) {
+	var spec Spec
+	if err := yaml.UnmarshalStrict(raw, &spec); err != nil {
+		return nil, errors.Wrap(err, "Couldn't parse the inventory spec")
+	}
+	for i := range spec.Sites {
+		spec.Sites[i].Fields = normalizeMap(spec.Sites[i].Fields)
+		for j := range spec.Sites[i].AdUnits {
+			spec.Sites[i].AdUnits[j].Fields = normalizeMap(spec.Sites[i].AdUnits[j].Fields)
+		}
+	}
+	for i := range spec.AdUnitGroups {
+		spec.AdUnitGroups[i].Fields = normalizeMap(spec.AdUnitGroups[i].Fields)
+	}
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// adUnitKey names an ad unit the way ad unit groups refer to it
+func adUnitKey(site, adUnit string) string {
+	return site + "/" + adUnit
+}
+
+func (s *Spec) validate() error {
+	if strings.TrimSpace(s.Account) == "" {
+		return errors.New("account_uid cannot be empty")
+	}
+
+	units := map[string]bool{}
+	sites := map[string]bool{}
+	for _, site := range s.Sites {
+		if strings.TrimSpace(site.Name) == "" {
+			return errors.New("every site needs a name")
+		}
+		if sites[site.Name] {
+			return fmt.Errorf("site %q is declared twice", site.Name)
+		}
+		sites[site.Name] = true
+		if err := checkReserved(site.Fields, "site "+site.Name); err != nil {
+			return err
+		}
+
+		for _, unit := range site.AdUnits {
+			key := adUnitKey(site.Name, unit.Name)
+			if strings.TrimSpace(unit.Name) == "" {
+				return fmt.Errorf("every ad unit needs a name, site %q has one without", site.Name)
+			}
+			if units[key] {
+				return fmt.Errorf("ad unit %q is declared twice", key)
+			}
+			units[key] = true
+			if err := checkReserved(unit.Fields, "ad unit "+key); err != nil {
+				return err
+			}
+		}
+	}
+
+	groups := map[string]bool{}
+	for _, group := range s.AdUnitGroups {
+		if strings.TrimSpace(group.Name) == "" {
+			return errors.New("every ad unit group needs a name")
+		}
+		if groups[group.Name] {
+			return fmt.Errorf("ad unit group %q is declared twice", group.Name)
+		}
+		groups[group.Name] = true
+		if err := checkReserved(group.Fields, "ad unit group "+group.Name); err != nil {
+			return err
+		}
+		for _, member := range group.AdUnits {
+			if !units[member] {
+				return fmt.Errorf("ad unit group %q refers to %q which isn't declared", group.Name, member)
+			}
+		}
+	}
+	return nil
+}
+
+// reservedFields are filled in by the plan and can't be set in a spec
+var reservedFields = []string{"uid", "id", "account_uid", "site_uid", groupMembersField}
+
+func checkReserved(fields map[string]interface{}, what string) error {
+	for _, f := range reservedFields {
+		if _, ok := fields[f]; ok {
+			return fmt.Errorf("%s sets %q which is managed by the plan", what, f)
+		}
+	}
+	return nil
+}
+
+// normalizeMap converts the map[interface{}]interface{} values yaml produces into JSON friendly maps
+func normalizeMap(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = normalize(v)
+	}
+	return out
+}
+
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			out[fmt.Sprint(k)] = normalize(v)
+		}
+		return out
+	case map[string]interface{}:
+		return normalizeMap(t)
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, v := range t {
+			out[i] = normalize(v)
+		}
+		return out
+	}
+	return v
+}
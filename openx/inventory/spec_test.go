@@ -0,0 +1,65 @@
+package inventory
+
+import (
+	"strings"
+	"testing"
+)
+
+const exampleSpec = `
+account_uid: acct
+prune: true
+sites:
+  - name: example.com
+    url: http://example.com
+    ad_units:
+      - name: leaderboard
+        type: web
+        primary_size: 728x90
+        tag_settings:
+          secure: true
+      - name: sidebar
+        primary_size: 300x250
+ad_unit_groups:
+  - name: banners
+    ad_units: [example.com/leaderboard, example.com/sidebar]
+`
+
+func TestParse(t *testing.T) {
+	spec, err := Parse([]byte(exampleSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Account != "acct" || !spec.Prune || len(spec.Sites) != 1 || len(spec.AdUnitGroups) != 1 {
+		t.Fatalf("unexpected spec %+v", spec)
+	}
+	unit := spec.Sites[0].AdUnits[0]
+	if unit.Fields["primary_size"] != "728x90" {
+		t.Fatalf("expected inline fields, got %v", unit.Fields)
+	}
+	if settings, ok := unit.Fields["tag_settings"].(map[string]interface{}); !ok || settings["secure"] != true {
+		t.Fatalf("expected nested maps to be JSON friendly, got %#v", unit.Fields["tag_settings"])
+	}
+}
+
+// TestParseInvalid specs that can't be planned are rejected up front
+func TestParseInvalid(t *testing.T) {
+	var cases = []struct {
+		Name string
+		Spec string
+		Want string
+	}{
+		{"No Account", "sites: []", "account_uid"},
+		{"Duplicate Site", "account_uid: a\nsites: [{name: x}, {name: x}]", "declared twice"},
+		{"Unnamed Ad Unit", "account_uid: a\nsites: [{name: x, ad_units: [{type: web}]}]", "needs a name"},
+		{"Unknown Member", "account_uid: a\nad_unit_groups: [{name: g, ad_units: [x/y]}]", "isn't declared"},
+		{"Reserved Field", "account_uid: a\nsites: [{name: x, uid: abc}]", "managed by the plan"},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			_, err := Parse([]byte(c.Spec))
+			if err == nil || !strings.Contains(err.Error(), c.Want) {
+				t.Fatalf("expected an error containing %q, got %v", c.Want, err)
+			}
+		})
+	}
+}
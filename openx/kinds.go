@@ -0,0 +1,73 @@
+package openx
+
+// Reference is a field holding the uid of another object
+type Reference struct {
+	Field string
+	Type  string
+	// Many is set when the field holds a list of uids
+	Many bool
+}
+
+// Kind describes an OX3 object type and the objects it refers to
+type Kind struct {
+	Type       string
+	References []Reference
+}
+
+// Kinds returns every object type the package knows how to walk, parents before children.
+// Accounts also refer to each other through parent_account_uid
+func Kinds() []Kind {
+	return []Kind{
+		{Type: TypeAccount, References: []Reference{
+			{Field: "parent_account_uid", Type: TypeAccount},
+		}},
+		{Type: TypeSite, References: []Reference{
+			{Field: "account_uid", Type: TypeAccount},
+		}},
+		{Type: TypeAdUnit, References: []Reference{
+			{Field: "account_uid", Type: TypeAccount},
+			{Field: "site_uid", Type: TypeSite},
+		}},
+		{Type: TypeAdUnitGroup, References: []Reference{
+			{Field: "account_uid", Type: TypeAccount},
+			{Field: "adunit_uids", Type: TypeAdUnit, Many: true},
+		}},
+		{Type: TypePackage, References: []Reference{
+			{Field: "account_uid", Type: TypeAccount},
+			{Field: "adunit_uids", Type: TypeAdUnit, Many: true},
+			{Field: "site_uids", Type: TypeSite, Many: true},
+		}},
+		{Type: TypeDeal, References: []Reference{
+			{Field: "account_uid", Type: TypeAccount},
+			{Field: "package_uid", Type: TypePackage},
+		}},
+		{Type: TypeOrder, References: []Reference{
+			{Field: "account_uid", Type: TypeAccount},
+		}},
+		{Type: TypeLineItem, References: []Reference{
+			{Field: "account_uid", Type: TypeAccount},
+			{Field: "order_uid", Type: TypeOrder},
+		}},
+		{Type: TypeCreative, References: []Reference{
+			{Field: "account_uid", Type: TypeAccount},
+		}},
+		{Type: TypeConversionTag, References: []Reference{
+			{Field: "account_uid", Type: TypeAccount},
+		}},
+		{Type: TypeAd, References: []Reference{
+			{Field: "account_uid", Type: TypeAccount},
+			{Field: "lineitem_uid", Type: TypeLineItem},
+			{Field: "creative_uid", Type: TypeCreative},
+		}},
+	}
+}
+
+// KindOf returns the Kind for objectType
+func KindOf(objectType string) (Kind, bool) {
+	for _, k := range Kinds() {
+		if k.Type == objectType {
+			return k, true
+		}
+	}
+	return Kind{}, false
+}
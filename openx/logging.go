@@ -0,0 +1,264 @@
+package openx
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	// sensitiveKeys are attribute, header, query and form keys whose values never make it into a log line
+	sensitiveKeys = map[string]bool{
+		"authorization":       true,
+		"cookie":              true,
+		"set-cookie":          true,
+		"password":            true,
+		"consumer_secrect":    true,
+		"consumer_secret":     true,
+		"oauth_token":         true,
+		"oauth_token_secret":  true,
+		"oauth_signature":     true,
+		"oauth_verifier":      true,
+		"openx3_access_token": true,
+		"token":               true,
+		"secret":              true,
+	}
+
+	// requestIDHeaders are checked in order for the id OX3 assigns to a request
+	requestIDHeaders = []string{
+		"X-Request-Id",
+		"X-Openx-Request-Id",
+		"X-Ox-Request-Id",
+	}
+)
+
+// Option configures optional behaviour of a Client created with NewClient or NewClientFromFile
+type Option func(*Client)
+
+// WithLogger sets the logger used by the client.
+// Requests and responses are logged at debug level, every record passes through redaction first
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		if logger != nil {
+			c.logger = logger
+		}
+	}
+}
+
+// defaultLogger returns the logger used when WithLogger isn't passed,
+// debug switches it to a stderr text logger at debug level
+func defaultLogger(debug bool) *slog.Logger {
+	if debug {
+		return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	return slog.Default()
+}
+
+// discardLogger is handed out when a Client wasn't built through NewClient
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func (c *Client) log() *slog.Logger {
+	if c.logger == nil {
+		return discardLogger
+	}
+	return c.logger
+}
+
+func isSensitive(key string) bool {
+	return sensitiveKeys[strings.ToLower(key)]
+}
+
+// redactingHandler wraps another slog.Handler and scrubs credentials out of every record
+type redactingHandler struct {
+	next slog.Handler
+}
+
+func newRedactingHandler(next slog.Handler) slog.Handler {
+	if h, ok := next.(*redactingHandler); ok {
+		return h
+	}
+	return &redactingHandler{next: next}
+}
+
+func (h *redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	clean := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		clean.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, clean)
+}
+
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clean := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		clean[i] = redactAttr(a)
+	}
+	return &redactingHandler{next: h.next.WithAttrs(clean)}
+}
+
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{next: h.next.WithGroup(name)}
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	if isSensitive(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindGroup:
+		group := v.Group()
+		clean := make([]any, len(group))
+		for i, g := range group {
+			clean[i] = redactAttr(g)
+		}
+		return slog.Group(a.Key, clean...)
+	case slog.KindString:
+		return slog.String(a.Key, redactString(v.String()))
+	case slog.KindAny:
+		switch t := v.Any().(type) {
+		case http.Header:
+			return slog.Any(a.Key, redactHeader(t))
+		case url.Values:
+			return slog.Any(a.Key, redactValues(t))
+		case *url.URL:
+			return slog.String(a.Key, redactURL(t))
+		case error:
+			return slog.String(a.Key, redactError(t))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+// redactString scrubs strings that look like a url or an encoded form
+func redactString(s string) string {
+	if !strings.Contains(s, "=") {
+		return s
+	}
+	if u, err := url.Parse(s); err == nil && u.RawQuery != "" {
+		return redactURL(u)
+	}
+	if vals, err := url.ParseQuery(s); err == nil {
+		for key := range vals {
+			if isSensitive(key) {
+				return redactValues(vals).Encode()
+			}
+		}
+	}
+	return s
+}
+
+// redactError scrubs the urls in an error's message, a *url.Error quotes the whole request url
+func redactError(err error) string {
+	words := strings.Split(err.Error(), " ")
+	for i, w := range words {
+		trimmed := strings.Trim(w, `"':,`)
+		if clean := redactString(trimmed); clean != trimmed {
+			words[i] = strings.Replace(w, trimmed, clean, 1)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func redactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	clean := *u
+	clean.User = nil
+	if clean.RawQuery != "" {
+		clean.RawQuery = redactValues(clean.Query()).Encode()
+	}
+	return clean.String()
+}
+
+func redactValues(vals url.Values) url.Values {
+	clean := make(url.Values, len(vals))
+	for key, v := range vals {
+		if isSensitive(key) {
+			clean[key] = []string{redacted}
+			continue
+		}
+		clean[key] = v
+	}
+	return clean
+}
+
+func redactHeader(header http.Header) http.Header {
+	clean := make(http.Header, len(header))
+	for key, v := range header {
+		if isSensitive(key) {
+			clean[key] = []string{redacted}
+			continue
+		}
+		clean[key] = v
+	}
+	return clean
+}
+
+func requestID(header http.Header) string {
+	for _, key := range requestIDHeaders {
+		if id := header.Get(key); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// loggingTransport logs every round trip at debug level with its timing, status and OX3 request id
+type loggingTransport struct {
+	next   http.RoundTripper
+	logger *slog.Logger
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if !t.logger.Enabled(req.Context(), slog.LevelDebug) {
+		return next.RoundTrip(req)
+	}
+
+	t.logger.DebugContext(req.Context(), "ox3 request",
+		"method", req.Method,
+		"url", req.URL,
+		"headers", req.Header,
+	)
+
+	start := time.Now()
+	res, err := next.RoundTrip(req)
+	elapsed := time.Since(start)
+	if err != nil {
+		t.logger.DebugContext(req.Context(), "ox3 request failed",
+			"method", req.Method,
+			"url", req.URL,
+			"duration", elapsed,
+			"error", redactError(err),
+		)
+		return res, err
+	}
+
+	t.logger.DebugContext(req.Context(), "ox3 response",
+		"method", req.Method,
+		"url", req.URL,
+		"status", res.StatusCode,
+		"duration", elapsed,
+		"request_id", requestID(res.Header),
+		"headers", res.Header,
+	)
+	return res, nil
+}
@@ -0,0 +1,114 @@
+package openx
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newBufferLogger(buf *bytes.Buffer) *slog.Logger {
+	h := slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	return slog.New(newRedactingHandler(h))
+}
+
+// TestRedaction ensures secrets never make it into a log line
+func TestRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newBufferLogger(&buf)
+
+	u, _ := url.Parse("https://sso.openx.com/login/process?oauth_token=abc123&foo=bar")
+	logger.Info("test",
+		"password", "hunter2",
+		"url", u,
+		"raw", "https://example.com/?oauth_verifier=v3r1f13r&x=1",
+		"form", "email=me%40example.com&password=hunter2",
+		"headers", http.Header{"Authorization": {`OAuth oauth_signature="s1gn4tur3"`}, "Cookie": {"openx3_access_token=t0k3n"}, "Accept": {"application/json"}},
+		slog.Group("creds", "consumer_secrect", "shh", "email", "me@example.com"),
+		"error", &url.Error{Op: "Get", URL: "https://api.openx.com/data/1.0/account?oauth_token=abc123&foo=bar", Err: io.EOF},
+	)
+	logger.With("oauth_token", "abc123").Info("with")
+
+	out := buf.String()
+	for _, secret := range []string{"hunter2", "abc123", "v3r1f13r", "s1gn4tur3", "t0k3n", "shh"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("secret %q leaked into log output:\n%s", secret, out)
+		}
+	}
+	for _, keep := range []string{"foo=bar", "x=1", "application/json", "me@example.com"} {
+		if !strings.Contains(out, keep) {
+			t.Errorf("expected %q to survive redaction:\n%s", keep, out)
+		}
+	}
+}
+
+// TestLoggingTransport checks the debug request and response lines
+func TestLoggingTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-42")
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	client := &http.Client{Transport: &loggingTransport{logger: newBufferLogger(&buf)}}
+	req, _ := http.NewRequest("GET", srv.URL+"/data/1.0/account?oauth_token=secret", nil)
+	req.Header.Set("Authorization", "OAuth oauth_signature=\"secret\"")
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	out := buf.String()
+	if strings.Contains(out, "secret") {
+		t.Fatalf("secret leaked into log output:\n%s", out)
+	}
+	for _, want := range []string{"ox3 request", "ox3 response", "status=418", "request_id=req-42", "duration="} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in log output:\n%s", want, out)
+		}
+	}
+}
+
+// TestLoggingTransportError a failed round trip shouldn't leak the url's tokens through the error
+func TestLoggingTransportError(t *testing.T) {
+	var buf bytes.Buffer
+	next := RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, &url.Error{Op: req.Method, URL: req.URL.String(), Err: io.ErrUnexpectedEOF}
+	})
+	transport := &loggingTransport{next: next, logger: newBufferLogger(&buf)}
+	req, _ := http.NewRequest("GET", "https://api.openx.com/data/1.0/account?oauth_token=secret", nil)
+	if _, err := transport.RoundTrip(req); err == nil {
+		t.Fatal("expected the round trip to fail")
+	}
+	out := buf.String()
+	if strings.Contains(out, "oauth_token=secret") {
+		t.Fatalf("secret leaked into log output:\n%s", out)
+	}
+	if !strings.Contains(out, "ox3 request failed") || !strings.Contains(out, "unexpected EOF") {
+		t.Errorf("expected the failure in log output:\n%s", out)
+	}
+}
+
+// TestLoggingTransportLevel nothing should be logged above debug
+func TestLoggingTransportLevel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	client := &http.Client{Transport: &loggingTransport{logger: logger}}
+	res, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if buf.Len() != 0 {
+		t.Fatalf("expected no output at info level, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,193 @@
+package openx
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Actions a permission can allow
+const (
+	ActionRead   = "read"
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Grant is a role a user holds on an account with the role's details
+type Grant struct {
+	AccountUID  string
+	RoleUID     string
+	RoleName    string
+	Permissions []string
+}
+
+// Me is the user a client is logged in as, what they hold and what they can see
+type Me struct {
+	UID        string `json:"uid"`
+	ID         Int    `json:"id"`
+	Email      string `json:"email"`
+	FirstName  string `json:"first_name"`
+	LastName   string `json:"last_name"`
+	AccountUID string `json:"account_uid"`
+	// Grants are the user's roles by account
+	Grants []Grant `json:"-"`
+	// Accounts are the accounts the user can see
+	Accounts []*Account `json:"-"`
+}
+
+// meRole is what Me keeps of a role
+type meRole struct {
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+}
+
+// meCache keeps Me for the client's session
+type meCache struct {
+	mu sync.Mutex
+	me *Me
+}
+
+// Me returns the user the client is logged in as. It's read from OX3 the first time it's asked for
+// and kept for the rest of the session
+func (c *Client) Me(ctx context.Context) (*Me, error) {
+	c.me.mu.Lock()
+	defer c.me.mu.Unlock()
+	if c.me.me != nil {
+		return c.me.me, nil
+	}
+
+	session, err := c.SessionContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserUID == "" {
+		return nil, errors.New("OX3 didn't say who the session belongs to")
+	}
+	user, err := c.Fetch(ctx, TypeUser, session.UserUID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Couldn't get the current user")
+	}
+	me := &Me{}
+	if err := decodeModel(user, me); err != nil {
+		return nil, errors.Wrap(err, "Couldn't decode the current user")
+	}
+
+	assignments, err := c.Users().Roles(ctx, session.UserUID)
+	if err != nil {
+		return nil, err
+	}
+	roles := map[string]*meRole{}
+	for _, a := range assignments {
+		role, ok := roles[a.RoleUID]
+		if !ok {
+			obj, err := c.Fetch(ctx, TypeRole, a.RoleUID)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Couldn't get role %s", a.RoleUID)
+			}
+			role = &meRole{}
+			if err := decodeModel(obj, role); err != nil {
+				return nil, errors.Wrapf(err, "Couldn't decode role %s", a.RoleUID)
+			}
+			roles[a.RoleUID] = role
+		}
+		me.Grants = append(me.Grants, Grant{
+			AccountUID:  a.AccountUID,
+			RoleUID:     a.RoleUID,
+			RoleName:    role.Name,
+			Permissions: role.Permissions,
+		})
+	}
+
+	if me.Accounts, err = c.Accounts().List(ctx, nil); err != nil {
+		return nil, errors.Wrap(err, "Couldn't list the current user's accounts")
+	}
+	c.me.me = me
+	return me, nil
+}
+
+// Can reports whether the user the client is logged in as may take action on objects of objectType
+// in an account, by uid or id. Check before writing to fail fast rather than on a 403
+//
+//	if ok, err := client.Can(ctx, openx.ActionCreate, openx.TypeLineItem, accountUID); err != nil || !ok {
+func (c *Client) Can(ctx context.Context, action, objectType, accountID string) (bool, error) {
+	me, err := c.Me(ctx)
+	if err != nil {
+		return false, err
+	}
+	return me.Can(action, objectType, accountID), nil
+}
+
+// Account returns the account with the uid or id given, nil when the user can't see it
+func (m *Me) Account(accountID string) *Account {
+	for _, a := range m.Accounts {
+		if a.UID == accountID || (a.ID != 0 && strconv.FormatInt(int64(a.ID), 10) == accountID) {
+			return a
+		}
+	}
+	return nil
+}
+
+// Permissions lists what the user may do on an account, the permissions of roles held on
+// the account and on every account above it
+func (m *Me) Permissions(accountID string) []string {
+	var perms []string
+	seen := map[string]bool{}
+	for _, uid := range m.lineage(accountID) {
+		for _, g := range m.Grants {
+			if g.AccountUID != uid {
+				continue
+			}
+			for _, p := range g.Permissions {
+				if !seen[p] {
+					seen[p] = true
+					perms = append(perms, p)
+				}
+			}
+		}
+	}
+	return perms
+}
+
+// Can reports whether the user may take action on objects of objectType in an account, by uid or id.
+// Permissions are written "<object type>:<action>", either side can be * and a bare type allows every action,
+// so "lineitem:create", "lineitem", "lineitem:*" and "*:*" all allow creating line items
+func (m *Me) Can(action, objectType, accountID string) bool {
+	for _, p := range m.Permissions(accountID) {
+		t, a := p, "*"
+		if i := strings.Index(p, ":"); i >= 0 {
+			t, a = p[:i], p[i+1:]
+		}
+		if (t == "*" || t == objectType) && (a == "*" || a == action) {
+			return true
+		}
+	}
+	return false
+}
+
+// lineage is the account's uid followed by the uids of the accounts above it
+func (m *Me) lineage(accountID string) []string {
+	account := m.Account(accountID)
+	if account == nil {
+		// the user may hold a role on an account missing from the list
+		return []string{accountID}
+	}
+	var uids []string
+	seen := map[string]bool{}
+	for account != nil && !seen[account.UID] {
+		seen[account.UID] = true
+		uids = append(uids, account.UID)
+		if account.ParentAccountUID == "" {
+			break
+		}
+		parent := m.Account(account.ParentAccountUID)
+		if parent == nil {
+			uids = append(uids, account.ParentAccountUID)
+		}
+		account = parent
+	}
+	return uids
+}
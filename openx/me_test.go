@@ -0,0 +1,99 @@
+package openx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// meHandler serves a user holding a trafficker role on the network and a read only role on
+// an advertiser outside of it
+func meHandler(calls map[string]int) http.HandlerFunc {
+	responses := map[string]string{
+		"/data/1.0/session":        `{"user_uid": "u-1", "account_uid": "net"}`,
+		"/data/1.0/user/u-1":       `{"uid": "u-1", "id": "7", "email": "me@example.com", "account_uid": "net"}`,
+		"/data/1.0/user/u-1/role":  `[{"account_uid": "net", "role_uid": "r-traffic"}, {"account_uid": "other", "role_uid": "r-read"}]`,
+		"/data/1.0/role/r-traffic": `{"uid": "r-traffic", "name": "Trafficker", "permissions": ["lineitem:*", "order:read", "ad"]}`,
+		"/data/1.0/role/r-read":    `{"uid": "r-read", "name": "Viewer", "permissions": ["*:read"]}`,
+		"/data/1.0/account": `{"total_count": 3, "objects": [
+			{"uid": "net", "id": 1, "name": "Network"},
+			{"uid": "pub", "id": 2, "name": "Publisher", "parent_account_uid": "net"},
+			{"uid": "other", "id": 3, "name": "Other"}]}`,
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		calls[r.URL.Path]++
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, body)
+	}
+}
+
+// TestMe should gather the user, their roles and accounts once per session
+func TestMe(t *testing.T) {
+	calls := map[string]int{}
+	c := newSessionClient(t, meHandler(calls))
+
+	me, err := c.Me(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if me.Email != "me@example.com" || me.ID != 7 || me.UID != "u-1" {
+		t.Errorf("got user %+v", me)
+	}
+	if len(me.Grants) != 2 || me.Grants[0].RoleName != "Trafficker" || me.Grants[1].AccountUID != "other" {
+		t.Errorf("got grants %+v", me.Grants)
+	}
+	if len(me.Accounts) != 3 {
+		t.Errorf("got %d accounts", len(me.Accounts))
+	}
+
+	if _, err := c.Me(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for path, n := range calls {
+		if n != 1 {
+			t.Errorf("%s was requested %d times, want once", path, n)
+		}
+	}
+}
+
+// TestCan roles should cover the account they're held on and every account under it
+func TestCan(t *testing.T) {
+	c := newSessionClient(t, meHandler(map[string]int{}))
+	me, err := c.Me(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		action, objectType, account string
+		want                        bool
+	}{
+		{ActionCreate, TypeLineItem, "net", true},
+		{ActionDelete, TypeLineItem, "pub", true},
+		{ActionCreate, TypeLineItem, "2", true},
+		{ActionRead, TypeOrder, "pub", true},
+		{ActionUpdate, TypeOrder, "pub", false},
+		{ActionUpdate, TypeAd, "pub", true},
+		{ActionRead, TypeSite, "other", true},
+		{ActionCreate, TypeLineItem, "other", false},
+		{ActionRead, TypeSite, "unknown", false},
+	}
+	for _, tt := range tests {
+		if got := me.Can(tt.action, tt.objectType, tt.account); got != tt.want {
+			t.Errorf("Can(%s, %s, %s) = %v, want %v", tt.action, tt.objectType, tt.account, got, tt.want)
+		}
+	}
+
+	if got, want := me.Permissions("pub"), []string{"lineitem:*", "order:read", "ad"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got permissions %v, want %v", got, want)
+	}
+	if ok, err := c.Can(context.Background(), ActionRead, TypeOrder, "other"); err != nil || !ok {
+		t.Errorf("Client.Can: got %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,111 @@
+// Package metrics exposes Prometheus metrics for OX3 API usage through openx middleware
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "ox3"
+
+// Metrics holds the collectors registered for a set of clients,
+// one Metrics can be shared by any number of clients
+type Metrics struct {
+	requests     *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+	retries      *prometheus.CounterVec
+	tokenRefresh *prometheus.CounterVec
+	inFlight     prometheus.Gauge
+	collectors   []prometheus.Collector
+}
+
+// New creates the OX3 collectors and registers them on reg,
+// a nil reg leaves registration up to the caller through Collectors
+func New(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "OX3 requests by method, endpoint and status class.",
+		}, []string{"method", "endpoint", "status_class"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "OX3 request latency by method and endpoint.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "endpoint"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "request_retries_total",
+			Help:      "OX3 requests sent again by the Retry middleware.",
+		}, []string{"method", "endpoint"}),
+		tokenRefresh: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "token_refreshes_total",
+			Help:      "OX3 access tokens requested through the SSO handshake by result.",
+		}, []string{"result"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "requests_in_flight",
+			Help:      "OX3 requests currently waiting on a response.",
+		}),
+	}
+	m.collectors = []prometheus.Collector{m.requests, m.duration, m.retries, m.tokenRefresh, m.inFlight}
+
+	if reg != nil {
+		for _, c := range m.collectors {
+			if err := reg.Register(c); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+// Collectors returns every collector owned by m
+func (m *Metrics) Collectors() []prometheus.Collector {
+	return m.collectors
+}
+
+// Middleware records every request the client makes.
+// Add it after openx.Retry so each attempt is observed and retries are counted
+//
+//	openx.WithMiddleware(openx.Retry(3, time.Second), m.Middleware())
+func (m *Metrics) Middleware() openx.Middleware {
+	return func(next openx.RoundTripFunc) openx.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			endpoint := openx.EndpointTemplate(req.URL)
+			if openx.RetryAttempt(req.Context()) > 1 {
+				m.retries.WithLabelValues(req.Method, endpoint).Inc()
+			}
+
+			m.inFlight.Inc()
+			start := time.Now()
+			res, err := next(req)
+			m.duration.WithLabelValues(req.Method, endpoint).Observe(time.Since(start).Seconds())
+			m.inFlight.Dec()
+
+			class := statusClass(res, err)
+			m.requests.WithLabelValues(req.Method, endpoint, class).Inc()
+			if openx.SSOStep(req) == openx.SSOAccessToken {
+				result := "success"
+				if class != "2xx" {
+					result = "failure"
+				}
+				m.tokenRefresh.WithLabelValues(result).Inc()
+			}
+			return res, err
+		}
+	}
+}
+
+func statusClass(res *http.Response, err error) string {
+	if err != nil || res == nil {
+		return "error"
+	}
+	return strconv.Itoa(res.StatusCode/100) + "xx"
+}
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func fakeServer(statuses ...int) openx.RoundTripFunc {
+	var calls int
+	return func(req *http.Request) (*http.Response, error) {
+		rec := httptest.NewRecorder()
+		rec.WriteHeader(statuses[calls%len(statuses)])
+		calls++
+		return rec.Result(), nil
+	}
+}
+
+func counterValue(t *testing.T, reg *prometheus.Registry, name string, labels map[string]string) float64 {
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			if matches(m, labels) {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func matches(m *dto.Metric, labels map[string]string) bool {
+	for _, l := range m.GetLabel() {
+		if want, ok := labels[l.GetName()]; ok && want != l.GetValue() {
+			return false
+		}
+	}
+	return true
+}
+
+// TestMiddleware requests, retries and token refreshes should all be counted
+func TestMiddleware(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := New(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transport := openx.Chain(openx.Retry(3, time.Millisecond), m.Middleware())(fakeServer(503, 200))
+	client := &http.Client{Transport: transport}
+
+	res, err := client.Get("http://example.com/data/1.0/lineitem/12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	res, err = client.Post("https://sso.openx.com/api/index/token", "application/x-www-form-urlencoded", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if v := counterValue(t, reg, "ox3_requests_total", map[string]string{"endpoint": "/lineitem/{id}", "status_class": "5xx"}); v != 1 {
+		t.Errorf("expected one 5xx lineitem request, got %v", v)
+	}
+	if v := counterValue(t, reg, "ox3_requests_total", map[string]string{"endpoint": "/lineitem/{id}", "status_class": "2xx"}); v != 1 {
+		t.Errorf("expected one 2xx lineitem request, got %v", v)
+	}
+	if v := counterValue(t, reg, "ox3_request_retries_total", map[string]string{"method": "GET"}); v != 1 {
+		t.Errorf("expected one retry, got %v", v)
+	}
+	if v := counterValue(t, reg, "ox3_token_refreshes_total", map[string]string{"result": "failure"}); v != 1 {
+		t.Errorf("expected one failed token refresh, got %v", v)
+	}
+}
+
+// TestDoubleRegister registering twice on the same registry should fail instead of panicking
+func TestDoubleRegister(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	if _, err := New(reg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(reg); err == nil {
+		t.Fatal("expected an error registering the collectors twice")
+	}
+}
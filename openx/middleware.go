@@ -0,0 +1,151 @@
+package openx
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RoundTripFunc performs a single HTTP round trip, it satisfies http.RoundTripper
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req)
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps a RoundTripFunc with cross-cutting behaviour
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// WithMiddleware adds middleware to every request the client makes, including the SSO login.
+// Middleware run in the order they are given across all WithMiddleware options:
+// the first one sees the request first and the response last.
+// Requests reach middleware already signed, so middleware may add headers but must not change
+// the method, url or form body of a request
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, mw...)
+	}
+}
+
+// Chain composes middleware into one, the first middleware is the outermost
+func Chain(mw ...Middleware) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		for i := len(mw) - 1; i >= 0; i-- {
+			next = mw[i](next)
+		}
+		return next
+	}
+}
+
+// SetHeader sets a header on every request, replacing any value already there
+func SetHeader(key, value string) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			// round trippers must not mutate the request they are handed
+			r := req.Clone(req.Context())
+			r.Header.Set(key, value)
+			return next(r)
+		}
+	}
+}
+
+// UserAgent sets the User-Agent header on every request,
+// an empty agent identifies the client as OX3-Go-API-Client with the package version
+func UserAgent(agent string) Middleware {
+	if agent == "" {
+		agent = "OX3-Go-API-Client/" + version
+	}
+	return SetHeader("User-Agent", agent)
+}
+
+type attemptKey struct{}
+
+// RetryAttempt returns which try a request under Retry is on, starting at 1.
+// Middleware added after Retry can use it to tell retries apart from first attempts
+func RetryAttempt(ctx context.Context) int {
+	if attempt, ok := ctx.Value(attemptKey{}).(int); ok {
+		return attempt
+	}
+	return 1
+}
+
+// Retry retries idempotent requests that fail with a network error, 429, 502, 503 or 504.
+// attempts is the total number of tries, backoff doubles after every try and a Retry-After header
+// sent by OX3 takes priority
+func Retry(attempts int, backoff time.Duration) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if attempts <= 1 || !retryable(req) {
+				return next(req)
+			}
+
+			wait := backoff
+			for attempt := 1; ; attempt++ {
+				r := req.Clone(context.WithValue(req.Context(), attemptKey{}, attempt))
+				if attempt > 1 && req.GetBody != nil {
+					body, err := req.GetBody()
+					if err != nil {
+						return nil, err
+					}
+					r.Body = body
+				}
+
+				res, err := next(r)
+				if attempt == attempts || !shouldRetry(res, err) {
+					return res, err
+				}
+
+				delay := wait
+				if res != nil {
+					if after := retryAfter(res); after > 0 {
+						delay = after
+					}
+					io.Copy(ioutil.Discard, res.Body)
+					res.Body.Close()
+				}
+				wait *= 2
+
+				timer := time.NewTimer(delay)
+				select {
+				case <-req.Context().Done():
+					timer.Stop()
+					return nil, req.Context().Err()
+				case <-timer.C:
+				}
+			}
+		}
+	}
+}
+
+// retryable reports whether req can be sent again without side effects
+func retryable(req *http.Request) bool {
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func shouldRetry(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func retryAfter(res *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(res.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,106 @@
+package openx
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func recordMiddleware(name string, calls *[]string) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			*calls = append(*calls, name+" in")
+			res, err := next(req)
+			*calls = append(*calls, name+" out")
+			return res, err
+		}
+	}
+}
+
+// TestMiddlewareOrder the first middleware given should see the request first and the response last
+func TestMiddlewareOrder(t *testing.T) {
+	var calls []string
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "server")
+	}),
+		WithMiddleware(recordMiddleware("a", &calls), recordMiddleware("b", &calls)),
+		WithMiddleware(recordMiddleware("c", &calls)),
+	)
+
+	res, err := c.Get("/account", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	want := "a in,b in,c in,server,c out,b out,a out"
+	if got := strings.Join(calls, ","); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+}
+
+// TestSetHeader headers set by middleware should reach the server
+func TestSetHeader(t *testing.T) {
+	var tenant, agent string
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant = r.Header.Get("X-Tenant")
+		agent = r.Header.Get("User-Agent")
+	}), WithMiddleware(SetHeader("X-Tenant", "acme"), UserAgent("")))
+
+	res, err := c.Post("/lineitem", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if tenant != "acme" {
+		t.Errorf("expected X-Tenant acme, got %q", tenant)
+	}
+	if agent != "OX3-Go-API-Client/"+version {
+		t.Errorf("unexpected User-Agent %q", agent)
+	}
+}
+
+// TestRetry a 503 should be retried until the server recovers
+func TestRetry(t *testing.T) {
+	var hits int32
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}), WithMiddleware(Retry(3, time.Millisecond)))
+
+	res, err := c.Put("/lineitem/1", strings.NewReader(`{"status":"Paused"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.StatusCode)
+	}
+	if hits != 3 {
+		t.Fatalf("expected 3 attempts, got %d", hits)
+	}
+}
+
+// TestRetryPost POST isn't idempotent so it must only be sent once
+func TestRetryPost(t *testing.T) {
+	var hits int32
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}), WithMiddleware(Retry(3, time.Millisecond)))
+
+	res, err := c.Post("/lineitem", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if hits != 1 {
+		t.Fatalf("expected 1 attempt, got %d", hits)
+	}
+}
@@ -0,0 +1,333 @@
+// Package mirror keeps a local SQLite copy of OX3 objects.
+//
+// Every object type gets its own table with the fields worth querying as columns
+// and the whole object as JSON in data. Sync walks each type through an openx.ObjectAPI,
+// the first time in full and afterwards only the objects modified since the type's watermark,
+// the newest modified_date seen so far. Watermarks are kept per type and set of List parameters.
+//
+// The package doesn't pick a driver, import one such as github.com/mattn/go-sqlite3
+// and hand Open the *sql.DB
+//
+//	db, err := sql.Open("sqlite3", "ox3.db")
+//	m, err := mirror.Open(ctx, db)
+//	results, err := m.Sync(ctx, client, mirror.Options{})
+package mirror
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/pkg/errors"
+)
+
+// Mirror is a local copy of OX3 objects kept in a SQLite database
+type Mirror struct {
+	db     *sql.DB
+	tables map[string]table
+	// order is the order types are synced in, parents first
+	order []string
+	now   func() time.Time
+}
+
+// Open creates the mirror's tables in db when they don't exist yet
+func Open(ctx context.Context, db *sql.DB) (*Mirror, error) {
+	m := &Mirror{db: db, tables: map[string]table{}, now: time.Now}
+	stmts := []string{stateDDL}
+	for _, kind := range openx.Kinds() {
+		t := tableFor(kind)
+		m.tables[kind.Type] = t
+		m.order = append(m.order, kind.Type)
+		stmts = append(stmts, t.ddl()...)
+	}
+	for _, stmt := range stmts {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return nil, errors.Wrap(err, "Couldn't create the mirror schema")
+		}
+	}
+	return m, nil
+}
+
+// DB returns the database the mirror writes to, for querying it
+func (m *Mirror) DB() *sql.DB {
+	return m.db
+}
+
+// Options controls a Sync
+type Options struct {
+	// Types limits the sync to some object types, every type is synced when empty
+	Types []string
+	// Full ignores the watermarks and lists every object, rows whose object is gone from OX3 are deleted.
+	// Incremental syncs can't see deletes so run a full one now and again
+	Full bool
+	// Params are extra List parameters sent for every type, an account_uid filter for instance.
+	// Every set of Params gets its own watermarks, and a full sync with Params only deletes the rows
+	// they match. When a parameter isn't one of the table's columns a full sync deletes nothing
+	Params map[string]interface{}
+}
+
+// Result describes the sync of one object type
+type Result struct {
+	Type string
+	// Full is set when every object was listed, either asked for or because the type had no watermark yet
+	Full bool
+	// Fetched is how many objects were written to the mirror
+	Fetched int
+	// Removed is how many rows a full sync deleted
+	Removed int
+	// Watermark is the newest modified_date stored, zero when the type has no objects
+	Watermark time.Time
+}
+
+// Sync brings the mirror up to date with api, one type at a time, parents before children.
+// It stops at the first type that fails and returns the results of the types synced before it
+func (m *Mirror) Sync(ctx context.Context, api openx.ObjectAPI, opts Options) ([]Result, error) {
+	types := opts.Types
+	if len(types) == 0 {
+		types = m.order
+	}
+	for _, objectType := range types {
+		if _, ok := m.tables[objectType]; !ok {
+			return nil, fmt.Errorf("can't mirror unknown object type %q", objectType)
+		}
+	}
+
+	var results []Result
+	for _, objectType := range types {
+		result, err := m.syncType(ctx, api, m.tables[objectType], opts)
+		if err != nil {
+			return results, errors.Wrapf(err, "Couldn't sync %s", objectType)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (m *Mirror) syncType(ctx context.Context, api openx.ObjectAPI, t table, opts Options) (Result, error) {
+	result := Result{Type: t.objectType, Full: opts.Full}
+	scope := scopeOf(opts.Params)
+	watermark, err := m.watermark(ctx, t.objectType, scope)
+	if err != nil {
+		return result, err
+	}
+	if watermark.IsZero() {
+		result.Full = true
+	}
+
+	params := make(map[string]interface{}, len(opts.Params)+1)
+	for k, v := range opts.Params {
+		params[k] = v
+	}
+	if !result.Full {
+		// objects modified in the watermark's second are fetched again, writing them twice is harmless
+		params[openx.ModifiedSinceParam] = openx.FormatDate(watermark)
+	}
+	objects, err := api.List(ctx, t.objectType, params)
+	if err != nil {
+		return result, err
+	}
+	if result.Full {
+		// a full sync starts the watermark over so deleted objects don't hold it up
+		watermark = time.Time{}
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	upsert, err := tx.PrepareContext(ctx, t.upsert())
+	if err != nil {
+		return result, err
+	}
+	defer upsert.Close()
+
+	seen := make(map[string]bool, len(objects))
+	for _, obj := range objects {
+		if obj.UID() == "" {
+			continue
+		}
+		if err := m.write(ctx, tx, upsert, t, obj); err != nil {
+			return result, errors.Wrapf(err, "Couldn't store %s %s", t.objectType, obj.UID())
+		}
+		seen[obj.UID()] = true
+		modified, err := obj.Time("modified_date")
+		if err != nil {
+			return result, errors.Wrapf(err, "%s %s has a bad modified_date", t.objectType, obj.UID())
+		}
+		if modified.After(watermark) {
+			watermark = modified
+		}
+	}
+	result.Fetched = len(seen)
+
+	if result.Full {
+		if result.Removed, err = m.removeUnseen(ctx, tx, t, seen, opts.Params); err != nil {
+			return result, err
+		}
+	}
+
+	var mark interface{}
+	if !watermark.IsZero() {
+		mark = openx.FormatDate(watermark)
+	}
+	var count int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+t.name).Scan(&count); err != nil {
+		return result, err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO sync_state (object_type, scope, watermark, synced_at, objects) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (object_type, scope) DO UPDATE SET watermark = excluded.watermark, synced_at = excluded.synced_at, objects = excluded.objects`,
+		t.objectType, scope, mark, openx.FormatDate(m.now()), count)
+	if err != nil {
+		return result, err
+	}
+
+	result.Watermark = watermark
+	return result, tx.Commit()
+}
+
+// write stores one object and replaces its links
+func (m *Mirror) write(ctx context.Context, tx *sql.Tx, upsert *sql.Stmt, t table, obj openx.Object) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	columns := t.columns()
+	values := make([]interface{}, len(columns))
+	for i, c := range columns {
+		if c == "data" {
+			values[i] = string(data)
+			continue
+		}
+		if v := obj.String(c); v != "" {
+			values[i] = v
+		}
+	}
+	if _, err := upsert.ExecContext(ctx, values...); err != nil {
+		return err
+	}
+
+	for _, l := range t.links {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+l.name+" WHERE uid = ?", obj.UID()); err != nil {
+			return err
+		}
+		refs, _ := obj[l.field].([]interface{})
+		for _, ref := range refs {
+			if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO "+l.name+" (uid, ref_uid) VALUES (?, ?)", obj.UID(), fmt.Sprint(ref)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// removeUnseen deletes the rows of objects a full listing didn't return, only the rows params match
+// as the listing couldn't have returned the others
+func (m *Mirror) removeUnseen(ctx context.Context, tx *sql.Tx, t table, seen map[string]bool, params map[string]interface{}) (int, error) {
+	where, args, ok := t.matching(params)
+	if !ok {
+		return 0, nil
+	}
+	rows, err := tx.QueryContext(ctx, "SELECT uid FROM "+t.name+where, args...)
+	if err != nil {
+		return 0, err
+	}
+	var gone []string
+	for rows.Next() {
+		var uid string
+		if err := rows.Scan(&uid); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if !seen[uid] {
+			gone = append(gone, uid)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, uid := range gone {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+t.name+" WHERE uid = ?", uid); err != nil {
+			return 0, err
+		}
+		for _, l := range t.links {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM "+l.name+" WHERE uid = ?", uid); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return len(gone), nil
+}
+
+// Watermark returns the newest modified_date synced for objectType without Params, zero when it was never synced
+func (m *Mirror) Watermark(ctx context.Context, objectType string) (time.Time, error) {
+	return m.watermark(ctx, objectType, "")
+}
+
+func (m *Mirror) watermark(ctx context.Context, objectType, scope string) (time.Time, error) {
+	var mark sql.NullString
+	err := m.db.QueryRowContext(ctx, "SELECT watermark FROM sync_state WHERE object_type = ? AND scope = ?", objectType, scope).Scan(&mark)
+	if err == sql.ErrNoRows || (err == nil && !mark.Valid) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return openx.ParseDate(mark.String)
+}
+
+// State is the outcome of the last sync of a type
+type State struct {
+	Type string
+	// Scope is the sync's Params encoded as a query string, empty without Params
+	Scope     string
+	Watermark time.Time
+	SyncedAt  time.Time
+	// Objects is how many rows the type's table held after the sync
+	Objects int
+}
+
+// States returns the sync state of every type synced at least once, ordered by type
+func (m *Mirror) States(ctx context.Context) ([]State, error) {
+	rows, err := m.db.QueryContext(ctx, "SELECT object_type, scope, watermark, synced_at, objects FROM sync_state ORDER BY object_type, scope")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var states []State
+	for rows.Next() {
+		var s State
+		var mark sql.NullString
+		var syncedAt string
+		if err := rows.Scan(&s.Type, &s.Scope, &mark, &syncedAt, &s.Objects); err != nil {
+			return nil, err
+		}
+		if mark.Valid {
+			if s.Watermark, err = openx.ParseDate(mark.String); err != nil {
+				return nil, err
+			}
+		}
+		if s.SyncedAt, err = openx.ParseDate(syncedAt); err != nil {
+			return nil, err
+		}
+		states = append(states, s)
+	}
+	return states, rows.Err()
+}
+
+// scopeOf encodes List parameters so the same set always gives the same string
+func scopeOf(params map[string]interface{}) string {
+	vals := url.Values{}
+	for k, v := range params {
+		vals.Set(k, fmt.Sprint(v))
+	}
+	return vals.Encode()
+}
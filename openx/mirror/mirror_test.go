@@ -0,0 +1,222 @@
+package mirror
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/marcsantiago/OX3-Go-API-Client/openx/openxtest"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestMirror(t *testing.T) *Mirror {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	m, err := Open(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// clock hands out times a minute apart
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time {
+	c.t = c.t.Add(time.Minute)
+	return c.t
+}
+
+func count(t *testing.T, m *Mirror, query string, args ...interface{}) int {
+	t.Helper()
+	var n int
+	if err := m.DB().QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	c := &clock{t: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	store := openxtest.NewStore()
+	store.Now = c.now
+
+	site, _ := store.Create(ctx, openx.TypeSite, openx.Object{"name": "example.com", "account_uid": "acct"})
+	unit, _ := store.Create(ctx, openx.TypeAdUnit, openx.Object{"name": "leaderboard", "account_uid": "acct", "site_uid": site.UID()})
+	store.Create(ctx, openx.TypeAdUnitGroup, openx.Object{"name": "leaderboards", "account_uid": "acct", "adunit_uids": []interface{}{unit.UID()}})
+
+	m := newTestMirror(t)
+	results, err := m.Sync(ctx, store, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(openx.Kinds()) {
+		t.Fatalf("got %d results, want one per type", len(results))
+	}
+	for _, r := range results {
+		if !r.Full {
+			t.Errorf("first sync of %s should be full", r.Type)
+		}
+	}
+
+	if n := count(t, m, "SELECT COUNT(*) FROM ad_units WHERE site_uid = ? AND name = 'leaderboard'", site.UID()); n != 1 {
+		t.Errorf("got %d ad units under the site, want 1", n)
+	}
+	if n := count(t, m, "SELECT COUNT(*) FROM ad_unit_group_adunits WHERE ref_uid = ?", unit.UID()); n != 1 {
+		t.Errorf("got %d group memberships, want 1", n)
+	}
+	mark, err := m.Watermark(ctx, openx.TypeAdUnit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := unit.Time("modified_date"); !mark.Equal(want) {
+		t.Errorf("ad unit watermark is %v, want %v", mark, want)
+	}
+
+	// only the object modified since the watermark comes back
+	store.Update(ctx, openx.TypeSite, site.UID(), openx.Object{"name": "example.org"})
+	store.Create(ctx, openx.TypeSite, openx.Object{"name": "example.net", "account_uid": "acct"})
+	results, err = m.Sync(ctx, store, Options{Types: []string{openx.TypeSite}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Full || results[0].Fetched != 2 {
+		t.Errorf("incremental sync got %+v, want 2 objects fetched", results[0])
+	}
+	if n := count(t, m, "SELECT COUNT(*) FROM sites WHERE name = 'example.org'"); n != 1 {
+		t.Error("the renamed site wasn't updated")
+	}
+	if n := count(t, m, "SELECT COUNT(*) FROM sites"); n != 2 {
+		t.Errorf("got %d sites, want 2", n)
+	}
+
+	// deletes only show up in a full sync
+	store.Remove(ctx, openx.TypeAdUnitGroup, store.Objects(openx.TypeAdUnitGroup)[0].UID())
+	if _, err := m.Sync(ctx, store, Options{Types: []string{openx.TypeAdUnitGroup}}); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(t, m, "SELECT COUNT(*) FROM ad_unit_groups"); n != 1 {
+		t.Errorf("an incremental sync removed the group")
+	}
+	results, err = m.Sync(ctx, store, Options{Types: []string{openx.TypeAdUnitGroup}, Full: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Removed != 1 {
+		t.Errorf("full sync removed %d groups, want 1", results[0].Removed)
+	}
+	if n := count(t, m, "SELECT COUNT(*) FROM ad_unit_group_adunits"); n != 0 {
+		t.Errorf("the removed group's members are still there")
+	}
+	if !results[0].Watermark.IsZero() {
+		t.Errorf("watermark of an empty type is %v, want zero", results[0].Watermark)
+	}
+
+	states, err := m.States(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range states {
+		if s.Type == openx.TypeSite && s.Objects != 2 {
+			t.Errorf("site state says %d objects, want 2", s.Objects)
+		}
+	}
+}
+
+func TestSyncIncrementalParams(t *testing.T) {
+	ctx := context.Background()
+	store := openxtest.NewStore()
+	store.Seed(openx.TypeOrder, openx.Object{"name": "spring", "modified_date": "2024-03-01 10:00:00"})
+
+	m := newTestMirror(t)
+	if _, err := m.Sync(ctx, store, Options{Types: []string{openx.TypeOrder}}); err != nil {
+		t.Fatal(err)
+	}
+	store.Seed(openx.TypeOrder, openx.Object{"name": "old", "modified_date": "2024-01-01 10:00:00"})
+	results, err := m.Sync(ctx, store, Options{Types: []string{openx.TypeOrder}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Fetched != 1 {
+		t.Errorf("fetched %d orders, want only the one at the watermark", results[0].Fetched)
+	}
+	if got := results[0].Watermark; !got.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("watermark moved to %v", got)
+	}
+}
+
+func TestSyncParamsScope(t *testing.T) {
+	ctx := context.Background()
+	store := openxtest.NewStore()
+	store.Seed(openx.TypeSite, openx.Object{"name": "a.com", "account_uid": "a", "modified_date": "2024-03-01 10:00:00"})
+	store.Seed(openx.TypeSite, openx.Object{"name": "b.com", "account_uid": "b", "modified_date": "2024-02-01 10:00:00"})
+
+	m := newTestMirror(t)
+	sync := func(account string) Result {
+		t.Helper()
+		results, err := m.Sync(ctx, store, Options{Types: []string{openx.TypeSite}, Full: true, Params: map[string]interface{}{"account_uid": account}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return results[0]
+	}
+	sync("a")
+	if r := sync("b"); r.Removed != 0 || r.Watermark.Month() != time.February {
+		t.Errorf("syncing account b got %+v, want its own watermark and nothing removed", r)
+	}
+	if n := count(t, m, "SELECT COUNT(*) FROM sites"); n != 2 {
+		t.Fatalf("got %d sites, want both accounts' sites", n)
+	}
+
+	store.Remove(ctx, openx.TypeSite, store.Objects(openx.TypeSite)[0].UID())
+	if r := sync("a"); r.Removed != 1 {
+		t.Errorf("full sync of account a removed %d sites, want 1", r.Removed)
+	}
+	if n := count(t, m, "SELECT COUNT(*) FROM sites WHERE account_uid = 'b'"); n != 1 {
+		t.Error("the full sync of account a removed account b's site")
+	}
+
+	// a parameter without a column can't say which rows a full sync should have seen
+	results, err := m.Sync(ctx, store, Options{Types: []string{openx.TypeSite}, Full: true, Params: map[string]interface{}{"name": "nope", "search": "x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Removed != 0 || count(t, m, "SELECT COUNT(*) FROM sites") != 1 {
+		t.Errorf("a full sync with an unknown parameter removed %d sites", results[0].Removed)
+	}
+}
+
+func TestSyncFailure(t *testing.T) {
+	ctx := context.Background()
+	store := openxtest.NewStore()
+	store.Seed(openx.TypeSite, openx.Object{"name": "example.com", "modified_date": "2024-03-01 10:00:00"})
+	boom := errors.New("boom")
+	store.FailOn("list", openx.TypeAdUnit, boom)
+
+	m := newTestMirror(t)
+	results, err := m.Sync(ctx, store, Options{Types: []string{openx.TypeSite, openx.TypeAdUnit}})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(results) != 1 || results[0].Type != openx.TypeSite {
+		t.Errorf("got results %+v, want the site sync only", results)
+	}
+	if mark, _ := m.Watermark(ctx, openx.TypeAdUnit); !mark.IsZero() {
+		t.Errorf("failed type got a watermark %v", mark)
+	}
+
+	if _, err := m.Sync(ctx, store, Options{Types: []string{"nope"}}); err == nil {
+		t.Error("expected an error for an unknown type")
+	}
+}
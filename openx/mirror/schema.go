@@ -0,0 +1,144 @@
+package mirror
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+)
+
+// tableNames maps every mirrored object type to its table
+var tableNames = map[string]string{
+	openx.TypeAccount:       "accounts",
+	openx.TypeSite:          "sites",
+	openx.TypeAdUnit:        "ad_units",
+	openx.TypeAdUnitGroup:   "ad_unit_groups",
+	openx.TypePackage:       "packages",
+	openx.TypeDeal:          "deals",
+	openx.TypeOrder:         "orders",
+	openx.TypeLineItem:      "line_items",
+	openx.TypeCreative:      "creatives",
+	openx.TypeAd:            "ads",
+	openx.TypeConversionTag: "conversion_tags",
+}
+
+// table is where a type's objects are kept. Every table has the columns
+//
+//	uid, id, name, status, <one per single reference>, created_date, modified_date, data
+//
+// data holds the whole object as JSON so fields without a column are never lost.
+// References to many objects get a link table of (uid, ref_uid) pairs
+type table struct {
+	name       string
+	objectType string
+	references []string
+	links      []link
+}
+
+// link is the table behind a reference to many objects, ad unit group members for instance
+type link struct {
+	name  string
+	field string
+}
+
+func tableFor(kind openx.Kind) table {
+	t := table{name: tableNames[kind.Type], objectType: kind.Type}
+	for _, ref := range kind.References {
+		if ref.Many {
+			name := strings.TrimSuffix(t.name, "s") + "_" + strings.TrimSuffix(ref.Field, "_uids") + "s"
+			t.links = append(t.links, link{name: name, field: ref.Field})
+			continue
+		}
+		t.references = append(t.references, ref.Field)
+	}
+	return t
+}
+
+// columns lists the table's columns in insert order
+func (t table) columns() []string {
+	columns := []string{"uid", "id", "name", "status"}
+	columns = append(columns, t.references...)
+	return append(columns, "created_date", "modified_date", "data")
+}
+
+// matching is the WHERE clause selecting the rows List params would return, ok is false when a parameter
+// isn't a column so the rows can't be told apart
+func (t table) matching(params map[string]interface{}) (where string, args []interface{}, ok bool) {
+	if len(params) == 0 {
+		return "", nil, true
+	}
+	columns := map[string]bool{}
+	for _, c := range t.columns() {
+		columns[c] = c != "data"
+	}
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		if !columns[k] {
+			return "", nil, false
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	conds := make([]string, len(keys))
+	for i, k := range keys {
+		conds[i] = k + " = ?"
+		args = append(args, fmt.Sprint(params[k]))
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args, true
+}
+
+func (t table) ddl() []string {
+	var columns []string
+	for _, c := range t.columns() {
+		switch c {
+		case "uid":
+			columns = append(columns, "uid TEXT PRIMARY KEY")
+		case "id":
+			columns = append(columns, "id INTEGER")
+		case "data":
+			columns = append(columns, "data TEXT NOT NULL")
+		default:
+			columns = append(columns, c+" TEXT")
+		}
+	}
+
+	stmts := []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n\t%s\n)", t.name, strings.Join(columns, ",\n\t")),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_modified_date ON %s (modified_date)", t.name, t.name),
+	}
+	for _, ref := range t.references {
+		stmts = append(stmts, fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_%s ON %s (%s)", t.name, ref, t.name, ref))
+	}
+	for _, l := range t.links {
+		stmts = append(stmts,
+			fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n\tuid TEXT NOT NULL,\n\tref_uid TEXT NOT NULL,\n\tPRIMARY KEY (uid, ref_uid)\n)", l.name),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_ref_uid ON %s (ref_uid)", l.name, l.name),
+		)
+	}
+	return stmts
+}
+
+// upsert inserts a row or replaces every column of the existing one
+func (t table) upsert() string {
+	columns := t.columns()
+	placeholders := make([]string, len(columns))
+	var updates []string
+	for i, c := range columns {
+		placeholders[i] = "?"
+		if c != "uid" {
+			updates = append(updates, c+" = excluded."+c)
+		}
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (uid) DO UPDATE SET %s",
+		t.name, strings.Join(columns, ", "), strings.Join(placeholders, ", "), strings.Join(updates, ", "))
+}
+
+const stateDDL = `CREATE TABLE IF NOT EXISTS sync_state (
+	object_type TEXT NOT NULL,
+	scope TEXT NOT NULL DEFAULT '',
+	watermark TEXT,
+	synced_at TEXT NOT NULL,
+	objects INTEGER NOT NULL,
+	PRIMARY KEY (object_type, scope)
+)`
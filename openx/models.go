@@ -0,0 +1,163 @@
+package openx
+
+//go:generate go run ../cmd/ox3gen -in metadata/ox3.json -out models_gen.go
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The typed models in models_gen.go are generated from the object metadata in metadata/ox3.json,
+// run go generate after changing it. The types below are what they're built from
+
+// GeneratedFields are the fields OX3 sets itself on objects of every type, they're never sent
+// when an object is recreated. A model's read only fields start with them and can add more
+var GeneratedFields = []string{"uid", "id", "created_date", "modified_date"}
+
+// Int is an integer OX3 sends either as a number or as a string
+type Int int64
+
+// UnmarshalJSON accepts 12, "12", "" and null
+func (i *Int) UnmarshalJSON(raw []byte) error {
+	s, err := unquoteNumber(raw)
+	if err != nil || s == "" {
+		*i = 0
+		return err
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "%s isn't an integer", raw)
+	}
+	*i = Int(n)
+	return nil
+}
+
+// Decimal is an amount of money or other decimal kept as text so no precision is lost,
+// OX3 sends them either as numbers or as strings
+type Decimal string
+
+// UnmarshalJSON accepts 1.5, "1.5", "" and null
+func (d *Decimal) UnmarshalJSON(raw []byte) error {
+	s, err := unquoteNumber(raw)
+	if err != nil {
+		return err
+	}
+	if s != "" {
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return errors.Wrapf(err, "%s isn't a decimal", raw)
+		}
+	}
+	*d = Decimal(s)
+	return nil
+}
+
+// Float returns the decimal as a float64, 0 when empty
+func (d Decimal) Float() (float64, error) {
+	if d == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(string(d), 64)
+}
+
+func unquoteNumber(raw []byte) (string, error) {
+	raw = bytes.TrimSpace(raw)
+	if bytes.Equal(raw, []byte("null")) {
+		return "", nil
+	}
+	if len(raw) > 0 && raw[0] == '"' {
+		var s string
+		err := json.Unmarshal(raw, &s)
+		return s, err
+	}
+	return string(raw), nil
+}
+
+// Bool returns v as a *bool for a model field, generated models hold booleans as *bool so false is sent
+func Bool(v bool) *bool {
+	return &v
+}
+
+// Date is a date in OX3's format, generated models hold them as *Date so unset dates are left out
+type Date struct {
+	time.Time
+}
+
+// NewDate returns t as a *Date for a model field
+func NewDate(t time.Time) *Date {
+	return &Date{Time: t.UTC()}
+}
+
+// MarshalJSON writes the date the way OX3 expects it
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(FormatDate(d.Time))
+}
+
+// UnmarshalJSON reads a date in OX3's format, "" and null are the zero date
+func (d *Date) UnmarshalJSON(raw []byte) error {
+	var s *string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return err
+	}
+	if s == nil || *s == "" {
+		d.Time = time.Time{}
+		return nil
+	}
+	t, err := ParseDate(*s)
+	if err != nil {
+		return err
+	}
+	d.Time = t
+	return nil
+}
+
+func errMissingUID(objectType string) error {
+	return errors.Errorf("can't update a %s without its uid", objectType)
+}
+
+// tracked is a model that remembers what it was decoded from, every generated model is one
+type tracked interface {
+	track(original Object)
+}
+
+// decodeModel fills a typed model from a generic object. Tracked models remember the object
+// the way they encode it so Changes isn't fooled by OX3 sending numbers as strings
+func decodeModel(obj Object, model interface{}) error {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, model); err != nil {
+		return err
+	}
+	if t, ok := model.(tracked); ok {
+		original, err := encodeModel(model, nil)
+		if err != nil {
+			return err
+		}
+		t.track(original)
+	}
+	return nil
+}
+
+// encodeModel turns a typed model into the fields sent to OX3, leaving out the ones OX3 sets itself
+func encodeModel(model interface{}, readOnly []string) (Object, error) {
+	raw, err := json.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+	var obj Object
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, err
+	}
+	for _, f := range readOnly {
+		delete(obj, f)
+	}
+	return obj, nil
+}
@@ -0,0 +1,2247 @@
+// Code generated by ox3gen from metadata/ox3.json. DO NOT EDIT.
+
+package openx
+
+import "context"
+
+// Status is a value OX3 accepts for Status fields
+type Status string
+
+// Status values
+const (
+	StatusActive   Status = "Active"
+	StatusInactive Status = "Inactive"
+	StatusPending  Status = "Pending"
+)
+
+var statusValues = []string{"Active", "Inactive", "Pending"}
+
+// Valid reports whether v is one of the Status values
+func (v Status) Valid() bool {
+	switch v {
+	case StatusActive, StatusInactive, StatusPending:
+		return true
+	}
+	return false
+}
+
+// AccountType is a value OX3 accepts for AccountType fields
+type AccountType string
+
+// AccountType values
+const (
+	AccountTypeNetwork    AccountType = "network"
+	AccountTypePublisher  AccountType = "publisher"
+	AccountTypeAdvertiser AccountType = "advertiser"
+)
+
+var accountTypeValues = []string{"network", "publisher", "advertiser"}
+
+// Valid reports whether v is one of the AccountType values
+func (v AccountType) Valid() bool {
+	switch v {
+	case AccountTypeNetwork, AccountTypePublisher, AccountTypeAdvertiser:
+		return true
+	}
+	return false
+}
+
+// AdUnitType is a value OX3 accepts for AdUnitType fields
+type AdUnitType string
+
+// AdUnitType values
+const (
+	AdUnitTypeWeb    AdUnitType = "web"
+	AdUnitTypeMobile AdUnitType = "mobile"
+	AdUnitTypeVideo  AdUnitType = "video"
+)
+
+var adUnitTypeValues = []string{"web", "mobile", "video"}
+
+// Valid reports whether v is one of the AdUnitType values
+func (v AdUnitType) Valid() bool {
+	switch v {
+	case AdUnitTypeWeb, AdUnitTypeMobile, AdUnitTypeVideo:
+		return true
+	}
+	return false
+}
+
+// LineItemType is a value OX3 accepts for LineItemType fields
+type LineItemType string
+
+// LineItemType values
+const (
+	LineItemTypeExclusive     LineItemType = "exclusive"
+	LineItemTypeNonGuaranteed LineItemType = "non_guaranteed"
+	LineItemTypeHouse         LineItemType = "house"
+)
+
+var lineItemTypeValues = []string{"exclusive", "non_guaranteed", "house"}
+
+// Valid reports whether v is one of the LineItemType values
+func (v LineItemType) Valid() bool {
+	switch v {
+	case LineItemTypeExclusive, LineItemTypeNonGuaranteed, LineItemTypeHouse:
+		return true
+	}
+	return false
+}
+
+// PricingModel is a value OX3 accepts for PricingModel fields
+type PricingModel string
+
+// PricingModel values
+const (
+	PricingModelCPM     PricingModel = "cpm"
+	PricingModelCPC     PricingModel = "cpc"
+	PricingModelCPA     PricingModel = "cpa"
+	PricingModelFlatFee PricingModel = "flat_fee"
+)
+
+var pricingModelValues = []string{"cpm", "cpc", "cpa", "flat_fee"}
+
+// Valid reports whether v is one of the PricingModel values
+func (v PricingModel) Valid() bool {
+	switch v {
+	case PricingModelCPM, PricingModelCPC, PricingModelCPA, PricingModelFlatFee:
+		return true
+	}
+	return false
+}
+
+// CreativeType is a value OX3 accepts for CreativeType fields
+type CreativeType string
+
+// CreativeType values
+const (
+	CreativeTypeImage CreativeType = "image"
+	CreativeTypeHTML  CreativeType = "html"
+	CreativeTypeVideo CreativeType = "video"
+)
+
+var creativeTypeValues = []string{"image", "html", "video"}
+
+// Valid reports whether v is one of the CreativeType values
+func (v CreativeType) Valid() bool {
+	switch v {
+	case CreativeTypeImage, CreativeTypeHTML, CreativeTypeVideo:
+		return true
+	}
+	return false
+}
+
+// DealType is a value OX3 accepts for DealType fields
+type DealType string
+
+// DealType values
+const (
+	DealTypePrivateAuction         DealType = "private_auction"
+	DealTypePreferredDeal          DealType = "preferred_deal"
+	DealTypeProgrammaticGuaranteed DealType = "programmatic_guaranteed"
+)
+
+var dealTypeValues = []string{"private_auction", "preferred_deal", "programmatic_guaranteed"}
+
+// Valid reports whether v is one of the DealType values
+func (v DealType) Valid() bool {
+	switch v {
+	case DealTypePrivateAuction, DealTypePreferredDeal, DealTypeProgrammaticGuaranteed:
+		return true
+	}
+	return false
+}
+
+// ConversionType is a value OX3 accepts for ConversionType fields
+type ConversionType string
+
+// ConversionType values
+const (
+	ConversionTypePageView ConversionType = "page_view"
+	ConversionTypeSignup   ConversionType = "signup"
+	ConversionTypeLead     ConversionType = "lead"
+	ConversionTypePurchase ConversionType = "purchase"
+)
+
+var conversionTypeValues = []string{"page_view", "signup", "lead", "purchase"}
+
+// Valid reports whether v is one of the ConversionType values
+func (v ConversionType) Valid() bool {
+	switch v {
+	case ConversionTypePageView, ConversionTypeSignup, ConversionTypeLead, ConversionTypePurchase:
+		return true
+	}
+	return false
+}
+
+// newModel returns an empty model of objectType, nil for types without one
+func newModel(objectType string) validator {
+	switch objectType {
+	case "account":
+		return &Account{}
+	case "site":
+		return &Site{}
+	case "adunit":
+		return &AdUnit{}
+	case "adunitgroup":
+		return &AdUnitGroup{}
+	case "order":
+		return &Order{}
+	case "lineitem":
+		return &LineItem{}
+	case "creative":
+		return &Creative{}
+	case "ad":
+		return &Ad{}
+	case "user":
+		return &User{}
+	case "role":
+		return &Role{}
+	case "audiencesegment":
+		return &AudienceSegment{}
+	case "package":
+		return &Package{}
+	case "deal":
+		return &Deal{}
+	case "conversiontag":
+		return &ConversionTag{}
+	}
+	return nil
+}
+
+// Account is an OX3 account
+type Account struct {
+	UID              string      `json:"uid,omitempty"`
+	ID               Int         `json:"id,omitempty"`
+	Name             string      `json:"name,omitempty"`
+	Status           Status      `json:"status,omitempty"`
+	Type             AccountType `json:"type,omitempty"`
+	ParentAccountUID string      `json:"parent_account_uid,omitempty"`
+	Currency         string      `json:"currency,omitempty"`
+	Timezone         string      `json:"timezone,omitempty"`
+	CreatedDate      *Date       `json:"created_date,omitempty"`
+	ModifiedDate     *Date       `json:"modified_date,omitempty"`
+
+	// original is the account as OX3 last returned it, Changes compares against it
+	original Object
+}
+
+func (m *Account) track(obj Object) {
+	m.original = obj
+}
+
+// Changes returns the fields set differently from when the account was read from OX3, cleared fields are null.
+// Every set field is a change for an account that wasn't read from OX3
+func (m *Account) Changes() (Object, error) {
+	return changes(m, m.original, accountReadOnly)
+}
+
+// accountReadOnly are the account fields OX3 sets itself
+var accountReadOnly = []string{"uid", "id", "created_date", "modified_date"}
+
+// Validate checks the account's fields, every problem found is listed in the returned ValidationErrors
+func (m *Account) Validate() error {
+	var errs ValidationErrors
+	errs.required("name", m.Name == "")
+	errs.oneOf("status", string(m.Status), m.Status.Valid(), statusValues)
+	errs.required("type", m.Type == "")
+	errs.oneOf("type", string(m.Type), m.Type.Valid(), accountTypeValues)
+	if r, ok := interface{}(m).(ruleValidator); ok {
+		r.validateRules(&errs)
+	}
+	return errs.err()
+}
+
+// AccountService reads and writes account objects
+type AccountService struct {
+	api ObjectAPI
+}
+
+// NewAccountService returns the account service working through api, such as an openxtest.Store
+func NewAccountService(api ObjectAPI) *AccountService {
+	return &AccountService{api: api}
+}
+
+// Accounts returns the account service
+func (c *Client) Accounts() *AccountService {
+	return NewAccountService(c)
+}
+
+// List returns every account matching params
+func (s *AccountService) List(ctx context.Context, params map[string]interface{}) ([]*Account, error) {
+	objects, err := s.api.List(ctx, "account", params)
+	if err != nil {
+		return nil, err
+	}
+	models := make([]*Account, len(objects))
+	for i, obj := range objects {
+		models[i] = &Account{}
+		if err := decodeModel(obj, models[i]); err != nil {
+			return nil, err
+		}
+	}
+	return models, nil
+}
+
+// Get returns the account with uid
+func (s *AccountService) Get(ctx context.Context, uid string) (*Account, error) {
+	obj, err := s.api.Fetch(ctx, "account", uid)
+	if err != nil {
+		return nil, err
+	}
+	m := &Account{}
+	return m, decodeModel(obj, m)
+}
+
+// Create creates m and returns the account OX3 made of it
+func (s *AccountService) Create(ctx context.Context, m *Account) (*Account, error) {
+	fields, err := encodeModel(m, accountReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Create(ctx, "account", fields)
+	if err != nil {
+		return nil, err
+	}
+	created := &Account{}
+	return created, decodeModel(obj, created)
+}
+
+// Update sends every set field of m to the account with m's uid
+func (s *AccountService) Update(ctx context.Context, m *Account) (*Account, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("account")
+	}
+	fields, err := encodeModel(m, accountReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "account", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &Account{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Patch sends only m's Changes, after checking the account wasn't modified since m was read.
+// An account modified in between comes back as a *ConflictError and nothing is sent
+func (s *AccountService) Patch(ctx context.Context, m *Account) (*Account, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("account")
+	}
+	fields, err := m.Changes()
+	if err != nil || len(fields) == 0 {
+		return m, err
+	}
+	current, err := s.Get(ctx, m.UID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkConflict("account", m.UID, m.original, current.original, fields); err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "account", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &Account{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Delete removes the account with uid
+func (s *AccountService) Delete(ctx context.Context, uid string) error {
+	return s.api.Remove(ctx, "account", uid)
+}
+
+// Site is an OX3 site
+type Site struct {
+	UID          string `json:"uid,omitempty"`
+	ID           Int    `json:"id,omitempty"`
+	Name         string `json:"name,omitempty"`
+	AccountUID   string `json:"account_uid,omitempty"`
+	Status       Status `json:"status,omitempty"`
+	URL          string `json:"url,omitempty"`
+	CreatedDate  *Date  `json:"created_date,omitempty"`
+	ModifiedDate *Date  `json:"modified_date,omitempty"`
+
+	// original is the site as OX3 last returned it, Changes compares against it
+	original Object
+}
+
+func (m *Site) track(obj Object) {
+	m.original = obj
+}
+
+// Changes returns the fields set differently from when the site was read from OX3, cleared fields are null.
+// Every set field is a change for a site that wasn't read from OX3
+func (m *Site) Changes() (Object, error) {
+	return changes(m, m.original, siteReadOnly)
+}
+
+// siteReadOnly are the site fields OX3 sets itself
+var siteReadOnly = []string{"uid", "id", "created_date", "modified_date"}
+
+// Validate checks the site's fields, every problem found is listed in the returned ValidationErrors
+func (m *Site) Validate() error {
+	var errs ValidationErrors
+	errs.required("name", m.Name == "")
+	errs.required("account_uid", m.AccountUID == "")
+	errs.oneOf("status", string(m.Status), m.Status.Valid(), statusValues)
+	if r, ok := interface{}(m).(ruleValidator); ok {
+		r.validateRules(&errs)
+	}
+	return errs.err()
+}
+
+// SiteService reads and writes site objects
+type SiteService struct {
+	api ObjectAPI
+}
+
+// NewSiteService returns the site service working through api, such as an openxtest.Store
+func NewSiteService(api ObjectAPI) *SiteService {
+	return &SiteService{api: api}
+}
+
+// Sites returns the site service
+func (c *Client) Sites() *SiteService {
+	return NewSiteService(c)
+}
+
+// List returns every site matching params
+func (s *SiteService) List(ctx context.Context, params map[string]interface{}) ([]*Site, error) {
+	objects, err := s.api.List(ctx, "site", params)
+	if err != nil {
+		return nil, err
+	}
+	models := make([]*Site, len(objects))
+	for i, obj := range objects {
+		models[i] = &Site{}
+		if err := decodeModel(obj, models[i]); err != nil {
+			return nil, err
+		}
+	}
+	return models, nil
+}
+
+// Get returns the site with uid
+func (s *SiteService) Get(ctx context.Context, uid string) (*Site, error) {
+	obj, err := s.api.Fetch(ctx, "site", uid)
+	if err != nil {
+		return nil, err
+	}
+	m := &Site{}
+	return m, decodeModel(obj, m)
+}
+
+// Create creates m and returns the site OX3 made of it
+func (s *SiteService) Create(ctx context.Context, m *Site) (*Site, error) {
+	fields, err := encodeModel(m, siteReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Create(ctx, "site", fields)
+	if err != nil {
+		return nil, err
+	}
+	created := &Site{}
+	return created, decodeModel(obj, created)
+}
+
+// Update sends every set field of m to the site with m's uid
+func (s *SiteService) Update(ctx context.Context, m *Site) (*Site, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("site")
+	}
+	fields, err := encodeModel(m, siteReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "site", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &Site{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Patch sends only m's Changes, after checking the site wasn't modified since m was read.
+// A site modified in between comes back as a *ConflictError and nothing is sent
+func (s *SiteService) Patch(ctx context.Context, m *Site) (*Site, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("site")
+	}
+	fields, err := m.Changes()
+	if err != nil || len(fields) == 0 {
+		return m, err
+	}
+	current, err := s.Get(ctx, m.UID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkConflict("site", m.UID, m.original, current.original, fields); err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "site", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &Site{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Delete removes the site with uid
+func (s *SiteService) Delete(ctx context.Context, uid string) error {
+	return s.api.Remove(ctx, "site", uid)
+}
+
+// AdUnit is an OX3 adunit
+type AdUnit struct {
+	UID          string     `json:"uid,omitempty"`
+	ID           Int        `json:"id,omitempty"`
+	Name         string     `json:"name,omitempty"`
+	AccountUID   string     `json:"account_uid,omitempty"`
+	SiteUID      string     `json:"site_uid,omitempty"`
+	Status       Status     `json:"status,omitempty"`
+	Type         AdUnitType `json:"type,omitempty"`
+	PrimarySize  string     `json:"primary_size,omitempty"`
+	CreatedDate  *Date      `json:"created_date,omitempty"`
+	ModifiedDate *Date      `json:"modified_date,omitempty"`
+
+	// original is the adunit as OX3 last returned it, Changes compares against it
+	original Object
+}
+
+func (m *AdUnit) track(obj Object) {
+	m.original = obj
+}
+
+// Changes returns the fields set differently from when the adunit was read from OX3, cleared fields are null.
+// Every set field is a change for an adunit that wasn't read from OX3
+func (m *AdUnit) Changes() (Object, error) {
+	return changes(m, m.original, adUnitReadOnly)
+}
+
+// adUnitReadOnly are the adunit fields OX3 sets itself
+var adUnitReadOnly = []string{"uid", "id", "created_date", "modified_date"}
+
+// Validate checks the adunit's fields, every problem found is listed in the returned ValidationErrors
+func (m *AdUnit) Validate() error {
+	var errs ValidationErrors
+	errs.required("name", m.Name == "")
+	errs.required("account_uid", m.AccountUID == "")
+	errs.required("site_uid", m.SiteUID == "")
+	errs.oneOf("status", string(m.Status), m.Status.Valid(), statusValues)
+	errs.oneOf("type", string(m.Type), m.Type.Valid(), adUnitTypeValues)
+	if r, ok := interface{}(m).(ruleValidator); ok {
+		r.validateRules(&errs)
+	}
+	return errs.err()
+}
+
+// AdUnitService reads and writes adunit objects
+type AdUnitService struct {
+	api ObjectAPI
+}
+
+// NewAdUnitService returns the adunit service working through api, such as an openxtest.Store
+func NewAdUnitService(api ObjectAPI) *AdUnitService {
+	return &AdUnitService{api: api}
+}
+
+// AdUnits returns the adunit service
+func (c *Client) AdUnits() *AdUnitService {
+	return NewAdUnitService(c)
+}
+
+// List returns every adunit matching params
+func (s *AdUnitService) List(ctx context.Context, params map[string]interface{}) ([]*AdUnit, error) {
+	objects, err := s.api.List(ctx, "adunit", params)
+	if err != nil {
+		return nil, err
+	}
+	models := make([]*AdUnit, len(objects))
+	for i, obj := range objects {
+		models[i] = &AdUnit{}
+		if err := decodeModel(obj, models[i]); err != nil {
+			return nil, err
+		}
+	}
+	return models, nil
+}
+
+// Get returns the adunit with uid
+func (s *AdUnitService) Get(ctx context.Context, uid string) (*AdUnit, error) {
+	obj, err := s.api.Fetch(ctx, "adunit", uid)
+	if err != nil {
+		return nil, err
+	}
+	m := &AdUnit{}
+	return m, decodeModel(obj, m)
+}
+
+// Create creates m and returns the adunit OX3 made of it
+func (s *AdUnitService) Create(ctx context.Context, m *AdUnit) (*AdUnit, error) {
+	fields, err := encodeModel(m, adUnitReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Create(ctx, "adunit", fields)
+	if err != nil {
+		return nil, err
+	}
+	created := &AdUnit{}
+	return created, decodeModel(obj, created)
+}
+
+// Update sends every set field of m to the adunit with m's uid
+func (s *AdUnitService) Update(ctx context.Context, m *AdUnit) (*AdUnit, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("adunit")
+	}
+	fields, err := encodeModel(m, adUnitReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "adunit", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &AdUnit{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Patch sends only m's Changes, after checking the adunit wasn't modified since m was read.
+// An adunit modified in between comes back as a *ConflictError and nothing is sent
+func (s *AdUnitService) Patch(ctx context.Context, m *AdUnit) (*AdUnit, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("adunit")
+	}
+	fields, err := m.Changes()
+	if err != nil || len(fields) == 0 {
+		return m, err
+	}
+	current, err := s.Get(ctx, m.UID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkConflict("adunit", m.UID, m.original, current.original, fields); err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "adunit", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &AdUnit{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Delete removes the adunit with uid
+func (s *AdUnitService) Delete(ctx context.Context, uid string) error {
+	return s.api.Remove(ctx, "adunit", uid)
+}
+
+// AdUnitGroup is an OX3 adunitgroup
+type AdUnitGroup struct {
+	UID          string   `json:"uid,omitempty"`
+	ID           Int      `json:"id,omitempty"`
+	Name         string   `json:"name,omitempty"`
+	AccountUID   string   `json:"account_uid,omitempty"`
+	Status       Status   `json:"status,omitempty"`
+	AdUnitUIDs   []string `json:"adunit_uids,omitempty"`
+	CreatedDate  *Date    `json:"created_date,omitempty"`
+	ModifiedDate *Date    `json:"modified_date,omitempty"`
+
+	// original is the adunitgroup as OX3 last returned it, Changes compares against it
+	original Object
+}
+
+func (m *AdUnitGroup) track(obj Object) {
+	m.original = obj
+}
+
+// Changes returns the fields set differently from when the adunitgroup was read from OX3, cleared fields are null.
+// Every set field is a change for an adunitgroup that wasn't read from OX3
+func (m *AdUnitGroup) Changes() (Object, error) {
+	return changes(m, m.original, adUnitGroupReadOnly)
+}
+
+// adUnitGroupReadOnly are the adunitgroup fields OX3 sets itself
+var adUnitGroupReadOnly = []string{"uid", "id", "created_date", "modified_date"}
+
+// Validate checks the adunitgroup's fields, every problem found is listed in the returned ValidationErrors
+func (m *AdUnitGroup) Validate() error {
+	var errs ValidationErrors
+	errs.required("name", m.Name == "")
+	errs.required("account_uid", m.AccountUID == "")
+	errs.oneOf("status", string(m.Status), m.Status.Valid(), statusValues)
+	if r, ok := interface{}(m).(ruleValidator); ok {
+		r.validateRules(&errs)
+	}
+	return errs.err()
+}
+
+// AdUnitGroupService reads and writes adunitgroup objects
+type AdUnitGroupService struct {
+	api ObjectAPI
+}
+
+// NewAdUnitGroupService returns the adunitgroup service working through api, such as an openxtest.Store
+func NewAdUnitGroupService(api ObjectAPI) *AdUnitGroupService {
+	return &AdUnitGroupService{api: api}
+}
+
+// AdUnitGroups returns the adunitgroup service
+func (c *Client) AdUnitGroups() *AdUnitGroupService {
+	return NewAdUnitGroupService(c)
+}
+
+// List returns every adunitgroup matching params
+func (s *AdUnitGroupService) List(ctx context.Context, params map[string]interface{}) ([]*AdUnitGroup, error) {
+	objects, err := s.api.List(ctx, "adunitgroup", params)
+	if err != nil {
+		return nil, err
+	}
+	models := make([]*AdUnitGroup, len(objects))
+	for i, obj := range objects {
+		models[i] = &AdUnitGroup{}
+		if err := decodeModel(obj, models[i]); err != nil {
+			return nil, err
+		}
+	}
+	return models, nil
+}
+
+// Get returns the adunitgroup with uid
+func (s *AdUnitGroupService) Get(ctx context.Context, uid string) (*AdUnitGroup, error) {
+	obj, err := s.api.Fetch(ctx, "adunitgroup", uid)
+	if err != nil {
+		return nil, err
+	}
+	m := &AdUnitGroup{}
+	return m, decodeModel(obj, m)
+}
+
+// Create creates m and returns the adunitgroup OX3 made of it
+func (s *AdUnitGroupService) Create(ctx context.Context, m *AdUnitGroup) (*AdUnitGroup, error) {
+	fields, err := encodeModel(m, adUnitGroupReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Create(ctx, "adunitgroup", fields)
+	if err != nil {
+		return nil, err
+	}
+	created := &AdUnitGroup{}
+	return created, decodeModel(obj, created)
+}
+
+// Update sends every set field of m to the adunitgroup with m's uid
+func (s *AdUnitGroupService) Update(ctx context.Context, m *AdUnitGroup) (*AdUnitGroup, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("adunitgroup")
+	}
+	fields, err := encodeModel(m, adUnitGroupReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "adunitgroup", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &AdUnitGroup{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Patch sends only m's Changes, after checking the adunitgroup wasn't modified since m was read.
+// An adunitgroup modified in between comes back as a *ConflictError and nothing is sent
+func (s *AdUnitGroupService) Patch(ctx context.Context, m *AdUnitGroup) (*AdUnitGroup, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("adunitgroup")
+	}
+	fields, err := m.Changes()
+	if err != nil || len(fields) == 0 {
+		return m, err
+	}
+	current, err := s.Get(ctx, m.UID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkConflict("adunitgroup", m.UID, m.original, current.original, fields); err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "adunitgroup", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &AdUnitGroup{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Delete removes the adunitgroup with uid
+func (s *AdUnitGroupService) Delete(ctx context.Context, uid string) error {
+	return s.api.Remove(ctx, "adunitgroup", uid)
+}
+
+// Order is an OX3 order
+type Order struct {
+	UID          string  `json:"uid,omitempty"`
+	ID           Int     `json:"id,omitempty"`
+	Name         string  `json:"name,omitempty"`
+	AccountUID   string  `json:"account_uid,omitempty"`
+	Status       Status  `json:"status,omitempty"`
+	StartDate    *Date   `json:"start_date,omitempty"`
+	EndDate      *Date   `json:"end_date,omitempty"`
+	Budget       Decimal `json:"budget,omitempty"`
+	Currency     string  `json:"currency,omitempty"`
+	CreatedDate  *Date   `json:"created_date,omitempty"`
+	ModifiedDate *Date   `json:"modified_date,omitempty"`
+
+	// original is the order as OX3 last returned it, Changes compares against it
+	original Object
+}
+
+func (m *Order) track(obj Object) {
+	m.original = obj
+}
+
+// Changes returns the fields set differently from when the order was read from OX3, cleared fields are null.
+// Every set field is a change for an order that wasn't read from OX3
+func (m *Order) Changes() (Object, error) {
+	return changes(m, m.original, orderReadOnly)
+}
+
+// orderReadOnly are the order fields OX3 sets itself
+var orderReadOnly = []string{"uid", "id", "created_date", "modified_date"}
+
+// Validate checks the order's fields, every problem found is listed in the returned ValidationErrors
+func (m *Order) Validate() error {
+	var errs ValidationErrors
+	errs.required("name", m.Name == "")
+	errs.required("account_uid", m.AccountUID == "")
+	errs.oneOf("status", string(m.Status), m.Status.Valid(), statusValues)
+	errs.required("start_date", m.StartDate == nil)
+	if r, ok := interface{}(m).(ruleValidator); ok {
+		r.validateRules(&errs)
+	}
+	return errs.err()
+}
+
+// OrderService reads and writes order objects
+type OrderService struct {
+	api ObjectAPI
+}
+
+// NewOrderService returns the order service working through api, such as an openxtest.Store
+func NewOrderService(api ObjectAPI) *OrderService {
+	return &OrderService{api: api}
+}
+
+// Orders returns the order service
+func (c *Client) Orders() *OrderService {
+	return NewOrderService(c)
+}
+
+// List returns every order matching params
+func (s *OrderService) List(ctx context.Context, params map[string]interface{}) ([]*Order, error) {
+	objects, err := s.api.List(ctx, "order", params)
+	if err != nil {
+		return nil, err
+	}
+	models := make([]*Order, len(objects))
+	for i, obj := range objects {
+		models[i] = &Order{}
+		if err := decodeModel(obj, models[i]); err != nil {
+			return nil, err
+		}
+	}
+	return models, nil
+}
+
+// Get returns the order with uid
+func (s *OrderService) Get(ctx context.Context, uid string) (*Order, error) {
+	obj, err := s.api.Fetch(ctx, "order", uid)
+	if err != nil {
+		return nil, err
+	}
+	m := &Order{}
+	return m, decodeModel(obj, m)
+}
+
+// Create creates m and returns the order OX3 made of it
+func (s *OrderService) Create(ctx context.Context, m *Order) (*Order, error) {
+	fields, err := encodeModel(m, orderReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Create(ctx, "order", fields)
+	if err != nil {
+		return nil, err
+	}
+	created := &Order{}
+	return created, decodeModel(obj, created)
+}
+
+// Update sends every set field of m to the order with m's uid
+func (s *OrderService) Update(ctx context.Context, m *Order) (*Order, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("order")
+	}
+	fields, err := encodeModel(m, orderReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "order", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &Order{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Patch sends only m's Changes, after checking the order wasn't modified since m was read.
+// An order modified in between comes back as a *ConflictError and nothing is sent
+func (s *OrderService) Patch(ctx context.Context, m *Order) (*Order, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("order")
+	}
+	fields, err := m.Changes()
+	if err != nil || len(fields) == 0 {
+		return m, err
+	}
+	current, err := s.Get(ctx, m.UID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkConflict("order", m.UID, m.original, current.original, fields); err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "order", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &Order{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Delete removes the order with uid
+func (s *OrderService) Delete(ctx context.Context, uid string) error {
+	return s.api.Remove(ctx, "order", uid)
+}
+
+// LineItem is an OX3 lineitem
+type LineItem struct {
+	UID          string                 `json:"uid,omitempty"`
+	ID           Int                    `json:"id,omitempty"`
+	Name         string                 `json:"name,omitempty"`
+	AccountUID   string                 `json:"account_uid,omitempty"`
+	OrderUID     string                 `json:"order_uid,omitempty"`
+	Status       Status                 `json:"status,omitempty"`
+	Type         LineItemType           `json:"type,omitempty"`
+	StartDate    *Date                  `json:"start_date,omitempty"`
+	EndDate      *Date                  `json:"end_date,omitempty"`
+	PricingModel PricingModel           `json:"pricing_model,omitempty"`
+	Price        Decimal                `json:"price,omitempty"`
+	Budget       Decimal                `json:"budget,omitempty"`
+	Currency     string                 `json:"currency,omitempty"`
+	Targeting    map[string]interface{} `json:"targeting,omitempty"`
+	CreatedDate  *Date                  `json:"created_date,omitempty"`
+	ModifiedDate *Date                  `json:"modified_date,omitempty"`
+
+	// original is the lineitem as OX3 last returned it, Changes compares against it
+	original Object
+}
+
+func (m *LineItem) track(obj Object) {
+	m.original = obj
+}
+
+// Changes returns the fields set differently from when the lineitem was read from OX3, cleared fields are null.
+// Every set field is a change for a lineitem that wasn't read from OX3
+func (m *LineItem) Changes() (Object, error) {
+	return changes(m, m.original, lineItemReadOnly)
+}
+
+// lineItemReadOnly are the lineitem fields OX3 sets itself
+var lineItemReadOnly = []string{"uid", "id", "created_date", "modified_date"}
+
+// Validate checks the lineitem's fields, every problem found is listed in the returned ValidationErrors
+func (m *LineItem) Validate() error {
+	var errs ValidationErrors
+	errs.required("name", m.Name == "")
+	errs.required("account_uid", m.AccountUID == "")
+	errs.required("order_uid", m.OrderUID == "")
+	errs.oneOf("status", string(m.Status), m.Status.Valid(), statusValues)
+	errs.required("type", m.Type == "")
+	errs.oneOf("type", string(m.Type), m.Type.Valid(), lineItemTypeValues)
+	errs.required("start_date", m.StartDate == nil)
+	errs.oneOf("pricing_model", string(m.PricingModel), m.PricingModel.Valid(), pricingModelValues)
+	if r, ok := interface{}(m).(ruleValidator); ok {
+		r.validateRules(&errs)
+	}
+	return errs.err()
+}
+
+// LineItemService reads and writes lineitem objects
+type LineItemService struct {
+	api ObjectAPI
+}
+
+// NewLineItemService returns the lineitem service working through api, such as an openxtest.Store
+func NewLineItemService(api ObjectAPI) *LineItemService {
+	return &LineItemService{api: api}
+}
+
+// LineItems returns the lineitem service
+func (c *Client) LineItems() *LineItemService {
+	return NewLineItemService(c)
+}
+
+// List returns every lineitem matching params
+func (s *LineItemService) List(ctx context.Context, params map[string]interface{}) ([]*LineItem, error) {
+	objects, err := s.api.List(ctx, "lineitem", params)
+	if err != nil {
+		return nil, err
+	}
+	models := make([]*LineItem, len(objects))
+	for i, obj := range objects {
+		models[i] = &LineItem{}
+		if err := decodeModel(obj, models[i]); err != nil {
+			return nil, err
+		}
+	}
+	return models, nil
+}
+
+// Get returns the lineitem with uid
+func (s *LineItemService) Get(ctx context.Context, uid string) (*LineItem, error) {
+	obj, err := s.api.Fetch(ctx, "lineitem", uid)
+	if err != nil {
+		return nil, err
+	}
+	m := &LineItem{}
+	return m, decodeModel(obj, m)
+}
+
+// Create creates m and returns the lineitem OX3 made of it
+func (s *LineItemService) Create(ctx context.Context, m *LineItem) (*LineItem, error) {
+	fields, err := encodeModel(m, lineItemReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Create(ctx, "lineitem", fields)
+	if err != nil {
+		return nil, err
+	}
+	created := &LineItem{}
+	return created, decodeModel(obj, created)
+}
+
+// Update sends every set field of m to the lineitem with m's uid
+func (s *LineItemService) Update(ctx context.Context, m *LineItem) (*LineItem, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("lineitem")
+	}
+	fields, err := encodeModel(m, lineItemReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "lineitem", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &LineItem{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Patch sends only m's Changes, after checking the lineitem wasn't modified since m was read.
+// A lineitem modified in between comes back as a *ConflictError and nothing is sent
+func (s *LineItemService) Patch(ctx context.Context, m *LineItem) (*LineItem, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("lineitem")
+	}
+	fields, err := m.Changes()
+	if err != nil || len(fields) == 0 {
+		return m, err
+	}
+	current, err := s.Get(ctx, m.UID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkConflict("lineitem", m.UID, m.original, current.original, fields); err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "lineitem", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &LineItem{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Delete removes the lineitem with uid
+func (s *LineItemService) Delete(ctx context.Context, uid string) error {
+	return s.api.Remove(ctx, "lineitem", uid)
+}
+
+// Creative is an OX3 creative
+type Creative struct {
+	UID          string       `json:"uid,omitempty"`
+	ID           Int          `json:"id,omitempty"`
+	Name         string       `json:"name,omitempty"`
+	AccountUID   string       `json:"account_uid,omitempty"`
+	Type         CreativeType `json:"type,omitempty"`
+	URI          string       `json:"uri,omitempty"`
+	Width        Int          `json:"width,omitempty"`
+	Height       Int          `json:"height,omitempty"`
+	CreatedDate  *Date        `json:"created_date,omitempty"`
+	ModifiedDate *Date        `json:"modified_date,omitempty"`
+
+	// original is the creative as OX3 last returned it, Changes compares against it
+	original Object
+}
+
+func (m *Creative) track(obj Object) {
+	m.original = obj
+}
+
+// Changes returns the fields set differently from when the creative was read from OX3, cleared fields are null.
+// Every set field is a change for a creative that wasn't read from OX3
+func (m *Creative) Changes() (Object, error) {
+	return changes(m, m.original, creativeReadOnly)
+}
+
+// creativeReadOnly are the creative fields OX3 sets itself
+var creativeReadOnly = []string{"uid", "id", "created_date", "modified_date"}
+
+// Validate checks the creative's fields, every problem found is listed in the returned ValidationErrors
+func (m *Creative) Validate() error {
+	var errs ValidationErrors
+	errs.required("name", m.Name == "")
+	errs.required("account_uid", m.AccountUID == "")
+	errs.required("type", m.Type == "")
+	errs.oneOf("type", string(m.Type), m.Type.Valid(), creativeTypeValues)
+	if r, ok := interface{}(m).(ruleValidator); ok {
+		r.validateRules(&errs)
+	}
+	return errs.err()
+}
+
+// CreativeService reads and writes creative objects
+type CreativeService struct {
+	api ObjectAPI
+}
+
+// NewCreativeService returns the creative service working through api, such as an openxtest.Store
+func NewCreativeService(api ObjectAPI) *CreativeService {
+	return &CreativeService{api: api}
+}
+
+// Creatives returns the creative service
+func (c *Client) Creatives() *CreativeService {
+	return NewCreativeService(c)
+}
+
+// List returns every creative matching params
+func (s *CreativeService) List(ctx context.Context, params map[string]interface{}) ([]*Creative, error) {
+	objects, err := s.api.List(ctx, "creative", params)
+	if err != nil {
+		return nil, err
+	}
+	models := make([]*Creative, len(objects))
+	for i, obj := range objects {
+		models[i] = &Creative{}
+		if err := decodeModel(obj, models[i]); err != nil {
+			return nil, err
+		}
+	}
+	return models, nil
+}
+
+// Get returns the creative with uid
+func (s *CreativeService) Get(ctx context.Context, uid string) (*Creative, error) {
+	obj, err := s.api.Fetch(ctx, "creative", uid)
+	if err != nil {
+		return nil, err
+	}
+	m := &Creative{}
+	return m, decodeModel(obj, m)
+}
+
+// Create creates m and returns the creative OX3 made of it
+func (s *CreativeService) Create(ctx context.Context, m *Creative) (*Creative, error) {
+	fields, err := encodeModel(m, creativeReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Create(ctx, "creative", fields)
+	if err != nil {
+		return nil, err
+	}
+	created := &Creative{}
+	return created, decodeModel(obj, created)
+}
+
+// Update sends every set field of m to the creative with m's uid
+func (s *CreativeService) Update(ctx context.Context, m *Creative) (*Creative, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("creative")
+	}
+	fields, err := encodeModel(m, creativeReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "creative", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &Creative{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Patch sends only m's Changes, after checking the creative wasn't modified since m was read.
+// A creative modified in between comes back as a *ConflictError and nothing is sent
+func (s *CreativeService) Patch(ctx context.Context, m *Creative) (*Creative, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("creative")
+	}
+	fields, err := m.Changes()
+	if err != nil || len(fields) == 0 {
+		return m, err
+	}
+	current, err := s.Get(ctx, m.UID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkConflict("creative", m.UID, m.original, current.original, fields); err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "creative", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &Creative{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Delete removes the creative with uid
+func (s *CreativeService) Delete(ctx context.Context, uid string) error {
+	return s.api.Remove(ctx, "creative", uid)
+}
+
+// Ad is an OX3 ad
+type Ad struct {
+	UID          string `json:"uid,omitempty"`
+	ID           Int    `json:"id,omitempty"`
+	Name         string `json:"name,omitempty"`
+	AccountUID   string `json:"account_uid,omitempty"`
+	LineItemUID  string `json:"lineitem_uid,omitempty"`
+	CreativeUID  string `json:"creative_uid,omitempty"`
+	Status       Status `json:"status,omitempty"`
+	ClickURL     string `json:"click_url,omitempty"`
+	CreatedDate  *Date  `json:"created_date,omitempty"`
+	ModifiedDate *Date  `json:"modified_date,omitempty"`
+
+	// original is the ad as OX3 last returned it, Changes compares against it
+	original Object
+}
+
+func (m *Ad) track(obj Object) {
+	m.original = obj
+}
+
+// Changes returns the fields set differently from when the ad was read from OX3, cleared fields are null.
+// Every set field is a change for an ad that wasn't read from OX3
+func (m *Ad) Changes() (Object, error) {
+	return changes(m, m.original, adReadOnly)
+}
+
+// adReadOnly are the ad fields OX3 sets itself
+var adReadOnly = []string{"uid", "id", "created_date", "modified_date"}
+
+// Validate checks the ad's fields, every problem found is listed in the returned ValidationErrors
+func (m *Ad) Validate() error {
+	var errs ValidationErrors
+	errs.required("name", m.Name == "")
+	errs.required("account_uid", m.AccountUID == "")
+	errs.required("lineitem_uid", m.LineItemUID == "")
+	errs.required("creative_uid", m.CreativeUID == "")
+	errs.oneOf("status", string(m.Status), m.Status.Valid(), statusValues)
+	if r, ok := interface{}(m).(ruleValidator); ok {
+		r.validateRules(&errs)
+	}
+	return errs.err()
+}
+
+// AdService reads and writes ad objects
+type AdService struct {
+	api ObjectAPI
+}
+
+// NewAdService returns the ad service working through api, such as an openxtest.Store
+func NewAdService(api ObjectAPI) *AdService {
+	return &AdService{api: api}
+}
+
+// Ads returns the ad service
+func (c *Client) Ads() *AdService {
+	return NewAdService(c)
+}
+
+// List returns every ad matching params
+func (s *AdService) List(ctx context.Context, params map[string]interface{}) ([]*Ad, error) {
+	objects, err := s.api.List(ctx, "ad", params)
+	if err != nil {
+		return nil, err
+	}
+	models := make([]*Ad, len(objects))
+	for i, obj := range objects {
+		models[i] = &Ad{}
+		if err := decodeModel(obj, models[i]); err != nil {
+			return nil, err
+		}
+	}
+	return models, nil
+}
+
+// Get returns the ad with uid
+func (s *AdService) Get(ctx context.Context, uid string) (*Ad, error) {
+	obj, err := s.api.Fetch(ctx, "ad", uid)
+	if err != nil {
+		return nil, err
+	}
+	m := &Ad{}
+	return m, decodeModel(obj, m)
+}
+
+// Create creates m and returns the ad OX3 made of it
+func (s *AdService) Create(ctx context.Context, m *Ad) (*Ad, error) {
+	fields, err := encodeModel(m, adReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Create(ctx, "ad", fields)
+	if err != nil {
+		return nil, err
+	}
+	created := &Ad{}
+	return created, decodeModel(obj, created)
+}
+
+// Update sends every set field of m to the ad with m's uid
+func (s *AdService) Update(ctx context.Context, m *Ad) (*Ad, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("ad")
+	}
+	fields, err := encodeModel(m, adReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "ad", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &Ad{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Patch sends only m's Changes, after checking the ad wasn't modified since m was read.
+// An ad modified in between comes back as a *ConflictError and nothing is sent
+func (s *AdService) Patch(ctx context.Context, m *Ad) (*Ad, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("ad")
+	}
+	fields, err := m.Changes()
+	if err != nil || len(fields) == 0 {
+		return m, err
+	}
+	current, err := s.Get(ctx, m.UID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkConflict("ad", m.UID, m.original, current.original, fields); err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "ad", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &Ad{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Delete removes the ad with uid
+func (s *AdService) Delete(ctx context.Context, uid string) error {
+	return s.api.Remove(ctx, "ad", uid)
+}
+
+// User is an OX3 user
+type User struct {
+	UID          string `json:"uid,omitempty"`
+	ID           Int    `json:"id,omitempty"`
+	Email        string `json:"email,omitempty"`
+	FirstName    string `json:"first_name,omitempty"`
+	LastName     string `json:"last_name,omitempty"`
+	AccountUID   string `json:"account_uid,omitempty"`
+	Status       Status `json:"status,omitempty"`
+	CreatedDate  *Date  `json:"created_date,omitempty"`
+	ModifiedDate *Date  `json:"modified_date,omitempty"`
+
+	// original is the user as OX3 last returned it, Changes compares against it
+	original Object
+}
+
+func (m *User) track(obj Object) {
+	m.original = obj
+}
+
+// Changes returns the fields set differently from when the user was read from OX3, cleared fields are null.
+// Every set field is a change for a user that wasn't read from OX3
+func (m *User) Changes() (Object, error) {
+	return changes(m, m.original, userReadOnly)
+}
+
+// userReadOnly are the user fields OX3 sets itself
+var userReadOnly = []string{"uid", "id", "created_date", "modified_date"}
+
+// Validate checks the user's fields, every problem found is listed in the returned ValidationErrors
+func (m *User) Validate() error {
+	var errs ValidationErrors
+	errs.required("email", m.Email == "")
+	errs.required("account_uid", m.AccountUID == "")
+	errs.oneOf("status", string(m.Status), m.Status.Valid(), statusValues)
+	if r, ok := interface{}(m).(ruleValidator); ok {
+		r.validateRules(&errs)
+	}
+	return errs.err()
+}
+
+// UserService reads and writes user objects
+type UserService struct {
+	api ObjectAPI
+}
+
+// NewUserService returns the user service working through api, such as an openxtest.Store
+func NewUserService(api ObjectAPI) *UserService {
+	return &UserService{api: api}
+}
+
+// Users returns the user service
+func (c *Client) Users() *UserService {
+	return NewUserService(c)
+}
+
+// List returns every user matching params
+func (s *UserService) List(ctx context.Context, params map[string]interface{}) ([]*User, error) {
+	objects, err := s.api.List(ctx, "user", params)
+	if err != nil {
+		return nil, err
+	}
+	models := make([]*User, len(objects))
+	for i, obj := range objects {
+		models[i] = &User{}
+		if err := decodeModel(obj, models[i]); err != nil {
+			return nil, err
+		}
+	}
+	return models, nil
+}
+
+// Get returns the user with uid
+func (s *UserService) Get(ctx context.Context, uid string) (*User, error) {
+	obj, err := s.api.Fetch(ctx, "user", uid)
+	if err != nil {
+		return nil, err
+	}
+	m := &User{}
+	return m, decodeModel(obj, m)
+}
+
+// Create creates m and returns the user OX3 made of it
+func (s *UserService) Create(ctx context.Context, m *User) (*User, error) {
+	fields, err := encodeModel(m, userReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Create(ctx, "user", fields)
+	if err != nil {
+		return nil, err
+	}
+	created := &User{}
+	return created, decodeModel(obj, created)
+}
+
+// Update sends every set field of m to the user with m's uid
+func (s *UserService) Update(ctx context.Context, m *User) (*User, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("user")
+	}
+	fields, err := encodeModel(m, userReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "user", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &User{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Patch sends only m's Changes, after checking the user wasn't modified since m was read.
+// A user modified in between comes back as a *ConflictError and nothing is sent
+func (s *UserService) Patch(ctx context.Context, m *User) (*User, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("user")
+	}
+	fields, err := m.Changes()
+	if err != nil || len(fields) == 0 {
+		return m, err
+	}
+	current, err := s.Get(ctx, m.UID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkConflict("user", m.UID, m.original, current.original, fields); err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "user", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &User{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Delete removes the user with uid
+func (s *UserService) Delete(ctx context.Context, uid string) error {
+	return s.api.Remove(ctx, "user", uid)
+}
+
+// Role is an OX3 role
+type Role struct {
+	UID          string   `json:"uid,omitempty"`
+	ID           Int      `json:"id,omitempty"`
+	Name         string   `json:"name,omitempty"`
+	AccountUID   string   `json:"account_uid,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	Permissions  []string `json:"permissions,omitempty"`
+	CreatedDate  *Date    `json:"created_date,omitempty"`
+	ModifiedDate *Date    `json:"modified_date,omitempty"`
+
+	// original is the role as OX3 last returned it, Changes compares against it
+	original Object
+}
+
+func (m *Role) track(obj Object) {
+	m.original = obj
+}
+
+// Changes returns the fields set differently from when the role was read from OX3, cleared fields are null.
+// Every set field is a change for a role that wasn't read from OX3
+func (m *Role) Changes() (Object, error) {
+	return changes(m, m.original, roleReadOnly)
+}
+
+// roleReadOnly are the role fields OX3 sets itself
+var roleReadOnly = []string{"uid", "id", "created_date", "modified_date"}
+
+// Validate checks the role's fields, every problem found is listed in the returned ValidationErrors
+func (m *Role) Validate() error {
+	var errs ValidationErrors
+	errs.required("name", m.Name == "")
+	if r, ok := interface{}(m).(ruleValidator); ok {
+		r.validateRules(&errs)
+	}
+	return errs.err()
+}
+
+// RoleService reads and writes role objects
+type RoleService struct {
+	api ObjectAPI
+}
+
+// NewRoleService returns the role service working through api, such as an openxtest.Store
+func NewRoleService(api ObjectAPI) *RoleService {
+	return &RoleService{api: api}
+}
+
+// Roles returns the role service
+func (c *Client) Roles() *RoleService {
+	return NewRoleService(c)
+}
+
+// List returns every role matching params
+func (s *RoleService) List(ctx context.Context, params map[string]interface{}) ([]*Role, error) {
+	objects, err := s.api.List(ctx, "role", params)
+	if err != nil {
+		return nil, err
+	}
+	models := make([]*Role, len(objects))
+	for i, obj := range objects {
+		models[i] = &Role{}
+		if err := decodeModel(obj, models[i]); err != nil {
+			return nil, err
+		}
+	}
+	return models, nil
+}
+
+// Get returns the role with uid
+func (s *RoleService) Get(ctx context.Context, uid string) (*Role, error) {
+	obj, err := s.api.Fetch(ctx, "role", uid)
+	if err != nil {
+		return nil, err
+	}
+	m := &Role{}
+	return m, decodeModel(obj, m)
+}
+
+// Create creates m and returns the role OX3 made of it
+func (s *RoleService) Create(ctx context.Context, m *Role) (*Role, error) {
+	fields, err := encodeModel(m, roleReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Create(ctx, "role", fields)
+	if err != nil {
+		return nil, err
+	}
+	created := &Role{}
+	return created, decodeModel(obj, created)
+}
+
+// Update sends every set field of m to the role with m's uid
+func (s *RoleService) Update(ctx context.Context, m *Role) (*Role, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("role")
+	}
+	fields, err := encodeModel(m, roleReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "role", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &Role{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Patch sends only m's Changes, after checking the role wasn't modified since m was read.
+// A role modified in between comes back as a *ConflictError and nothing is sent
+func (s *RoleService) Patch(ctx context.Context, m *Role) (*Role, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("role")
+	}
+	fields, err := m.Changes()
+	if err != nil || len(fields) == 0 {
+		return m, err
+	}
+	current, err := s.Get(ctx, m.UID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkConflict("role", m.UID, m.original, current.original, fields); err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "role", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &Role{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Delete removes the role with uid
+func (s *RoleService) Delete(ctx context.Context, uid string) error {
+	return s.api.Remove(ctx, "role", uid)
+}
+
+// AudienceSegment is an OX3 audiencesegment
+type AudienceSegment struct {
+	UID          string `json:"uid,omitempty"`
+	ID           Int    `json:"id,omitempty"`
+	Name         string `json:"name,omitempty"`
+	AccountUID   string `json:"account_uid,omitempty"`
+	Status       Status `json:"status,omitempty"`
+	Description  string `json:"description,omitempty"`
+	TtlDays      Int    `json:"ttl_days,omitempty"`
+	UserCount    Int    `json:"user_count,omitempty"`
+	CreatedDate  *Date  `json:"created_date,omitempty"`
+	ModifiedDate *Date  `json:"modified_date,omitempty"`
+
+	// original is the audiencesegment as OX3 last returned it, Changes compares against it
+	original Object
+}
+
+func (m *AudienceSegment) track(obj Object) {
+	m.original = obj
+}
+
+// Changes returns the fields set differently from when the audiencesegment was read from OX3, cleared fields are null.
+// Every set field is a change for an audiencesegment that wasn't read from OX3
+func (m *AudienceSegment) Changes() (Object, error) {
+	return changes(m, m.original, audienceSegmentReadOnly)
+}
+
+// audienceSegmentReadOnly are the audiencesegment fields OX3 sets itself
+var audienceSegmentReadOnly = []string{"uid", "id", "user_count", "created_date", "modified_date"}
+
+// Validate checks the audiencesegment's fields, every problem found is listed in the returned ValidationErrors
+func (m *AudienceSegment) Validate() error {
+	var errs ValidationErrors
+	errs.required("name", m.Name == "")
+	errs.required("account_uid", m.AccountUID == "")
+	errs.oneOf("status", string(m.Status), m.Status.Valid(), statusValues)
+	if r, ok := interface{}(m).(ruleValidator); ok {
+		r.validateRules(&errs)
+	}
+	return errs.err()
+}
+
+// AudienceSegmentService reads and writes audiencesegment objects
+type AudienceSegmentService struct {
+	api ObjectAPI
+}
+
+// NewAudienceSegmentService returns the audiencesegment service working through api, such as an openxtest.Store
+func NewAudienceSegmentService(api ObjectAPI) *AudienceSegmentService {
+	return &AudienceSegmentService{api: api}
+}
+
+// AudienceSegments returns the audiencesegment service
+func (c *Client) AudienceSegments() *AudienceSegmentService {
+	return NewAudienceSegmentService(c)
+}
+
+// List returns every audiencesegment matching params
+func (s *AudienceSegmentService) List(ctx context.Context, params map[string]interface{}) ([]*AudienceSegment, error) {
+	objects, err := s.api.List(ctx, "audiencesegment", params)
+	if err != nil {
+		return nil, err
+	}
+	models := make([]*AudienceSegment, len(objects))
+	for i, obj := range objects {
+		models[i] = &AudienceSegment{}
+		if err := decodeModel(obj, models[i]); err != nil {
+			return nil, err
+		}
+	}
+	return models, nil
+}
+
+// Get returns the audiencesegment with uid
+func (s *AudienceSegmentService) Get(ctx context.Context, uid string) (*AudienceSegment, error) {
+	obj, err := s.api.Fetch(ctx, "audiencesegment", uid)
+	if err != nil {
+		return nil, err
+	}
+	m := &AudienceSegment{}
+	return m, decodeModel(obj, m)
+}
+
+// Create creates m and returns the audiencesegment OX3 made of it
+func (s *AudienceSegmentService) Create(ctx context.Context, m *AudienceSegment) (*AudienceSegment, error) {
+	fields, err := encodeModel(m, audienceSegmentReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Create(ctx, "audiencesegment", fields)
+	if err != nil {
+		return nil, err
+	}
+	created := &AudienceSegment{}
+	return created, decodeModel(obj, created)
+}
+
+// Update sends every set field of m to the audiencesegment with m's uid
+func (s *AudienceSegmentService) Update(ctx context.Context, m *AudienceSegment) (*AudienceSegment, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("audiencesegment")
+	}
+	fields, err := encodeModel(m, audienceSegmentReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "audiencesegment", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &AudienceSegment{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Patch sends only m's Changes, after checking the audiencesegment wasn't modified since m was read.
+// An audiencesegment modified in between comes back as a *ConflictError and nothing is sent
+func (s *AudienceSegmentService) Patch(ctx context.Context, m *AudienceSegment) (*AudienceSegment, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("audiencesegment")
+	}
+	fields, err := m.Changes()
+	if err != nil || len(fields) == 0 {
+		return m, err
+	}
+	current, err := s.Get(ctx, m.UID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkConflict("audiencesegment", m.UID, m.original, current.original, fields); err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "audiencesegment", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &AudienceSegment{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Delete removes the audiencesegment with uid
+func (s *AudienceSegmentService) Delete(ctx context.Context, uid string) error {
+	return s.api.Remove(ctx, "audiencesegment", uid)
+}
+
+// Package is an OX3 package
+type Package struct {
+	UID          string   `json:"uid,omitempty"`
+	ID           Int      `json:"id,omitempty"`
+	Name         string   `json:"name,omitempty"`
+	AccountUID   string   `json:"account_uid,omitempty"`
+	Status       Status   `json:"status,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	AdUnitUIDs   []string `json:"adunit_uids,omitempty"`
+	SiteUIDs     []string `json:"site_uids,omitempty"`
+	CreatedDate  *Date    `json:"created_date,omitempty"`
+	ModifiedDate *Date    `json:"modified_date,omitempty"`
+
+	// original is the package as OX3 last returned it, Changes compares against it
+	original Object
+}
+
+func (m *Package) track(obj Object) {
+	m.original = obj
+}
+
+// Changes returns the fields set differently from when the package was read from OX3, cleared fields are null.
+// Every set field is a change for a package that wasn't read from OX3
+func (m *Package) Changes() (Object, error) {
+	return changes(m, m.original, packageReadOnly)
+}
+
+// packageReadOnly are the package fields OX3 sets itself
+var packageReadOnly = []string{"uid", "id", "created_date", "modified_date"}
+
+// Validate checks the package's fields, every problem found is listed in the returned ValidationErrors
+func (m *Package) Validate() error {
+	var errs ValidationErrors
+	errs.required("name", m.Name == "")
+	errs.required("account_uid", m.AccountUID == "")
+	errs.oneOf("status", string(m.Status), m.Status.Valid(), statusValues)
+	if r, ok := interface{}(m).(ruleValidator); ok {
+		r.validateRules(&errs)
+	}
+	return errs.err()
+}
+
+// PackageService reads and writes package objects
+type PackageService struct {
+	api ObjectAPI
+}
+
+// NewPackageService returns the package service working through api, such as an openxtest.Store
+func NewPackageService(api ObjectAPI) *PackageService {
+	return &PackageService{api: api}
+}
+
+// Packages returns the package service
+func (c *Client) Packages() *PackageService {
+	return NewPackageService(c)
+}
+
+// List returns every package matching params
+func (s *PackageService) List(ctx context.Context, params map[string]interface{}) ([]*Package, error) {
+	objects, err := s.api.List(ctx, "package", params)
+	if err != nil {
+		return nil, err
+	}
+	models := make([]*Package, len(objects))
+	for i, obj := range objects {
+		models[i] = &Package{}
+		if err := decodeModel(obj, models[i]); err != nil {
+			return nil, err
+		}
+	}
+	return models, nil
+}
+
+// Get returns the package with uid
+func (s *PackageService) Get(ctx context.Context, uid string) (*Package, error) {
+	obj, err := s.api.Fetch(ctx, "package", uid)
+	if err != nil {
+		return nil, err
+	}
+	m := &Package{}
+	return m, decodeModel(obj, m)
+}
+
+// Create creates m and returns the package OX3 made of it
+func (s *PackageService) Create(ctx context.Context, m *Package) (*Package, error) {
+	fields, err := encodeModel(m, packageReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Create(ctx, "package", fields)
+	if err != nil {
+		return nil, err
+	}
+	created := &Package{}
+	return created, decodeModel(obj, created)
+}
+
+// Update sends every set field of m to the package with m's uid
+func (s *PackageService) Update(ctx context.Context, m *Package) (*Package, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("package")
+	}
+	fields, err := encodeModel(m, packageReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "package", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &Package{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Patch sends only m's Changes, after checking the package wasn't modified since m was read.
+// A package modified in between comes back as a *ConflictError and nothing is sent
+func (s *PackageService) Patch(ctx context.Context, m *Package) (*Package, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("package")
+	}
+	fields, err := m.Changes()
+	if err != nil || len(fields) == 0 {
+		return m, err
+	}
+	current, err := s.Get(ctx, m.UID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkConflict("package", m.UID, m.original, current.original, fields); err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "package", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &Package{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Delete removes the package with uid
+func (s *PackageService) Delete(ctx context.Context, uid string) error {
+	return s.api.Remove(ctx, "package", uid)
+}
+
+// Deal is an OX3 deal
+type Deal struct {
+	UID          string   `json:"uid,omitempty"`
+	ID           Int      `json:"id,omitempty"`
+	Name         string   `json:"name,omitempty"`
+	AccountUID   string   `json:"account_uid,omitempty"`
+	DealID       string   `json:"deal_id,omitempty"`
+	Type         DealType `json:"type,omitempty"`
+	Status       Status   `json:"status,omitempty"`
+	PackageUID   string   `json:"package_uid,omitempty"`
+	FloorPrice   Decimal  `json:"floor_price,omitempty"`
+	Currency     string   `json:"currency,omitempty"`
+	BuyerSeatIDs []string `json:"buyer_seat_ids,omitempty"`
+	StartDate    *Date    `json:"start_date,omitempty"`
+	EndDate      *Date    `json:"end_date,omitempty"`
+	CreatedDate  *Date    `json:"created_date,omitempty"`
+	ModifiedDate *Date    `json:"modified_date,omitempty"`
+
+	// original is the deal as OX3 last returned it, Changes compares against it
+	original Object
+}
+
+func (m *Deal) track(obj Object) {
+	m.original = obj
+}
+
+// Changes returns the fields set differently from when the deal was read from OX3, cleared fields are null.
+// Every set field is a change for a deal that wasn't read from OX3
+func (m *Deal) Changes() (Object, error) {
+	return changes(m, m.original, dealReadOnly)
+}
+
+// dealReadOnly are the deal fields OX3 sets itself
+var dealReadOnly = []string{"uid", "id", "created_date", "modified_date"}
+
+// Validate checks the deal's fields, every problem found is listed in the returned ValidationErrors
+func (m *Deal) Validate() error {
+	var errs ValidationErrors
+	errs.required("name", m.Name == "")
+	errs.required("account_uid", m.AccountUID == "")
+	errs.required("type", m.Type == "")
+	errs.oneOf("type", string(m.Type), m.Type.Valid(), dealTypeValues)
+	errs.oneOf("status", string(m.Status), m.Status.Valid(), statusValues)
+	if r, ok := interface{}(m).(ruleValidator); ok {
+		r.validateRules(&errs)
+	}
+	return errs.err()
+}
+
+// DealService reads and writes deal objects
+type DealService struct {
+	api ObjectAPI
+}
+
+// NewDealService returns the deal service working through api, such as an openxtest.Store
+func NewDealService(api ObjectAPI) *DealService {
+	return &DealService{api: api}
+}
+
+// Deals returns the deal service
+func (c *Client) Deals() *DealService {
+	return NewDealService(c)
+}
+
+// List returns every deal matching params
+func (s *DealService) List(ctx context.Context, params map[string]interface{}) ([]*Deal, error) {
+	objects, err := s.api.List(ctx, "deal", params)
+	if err != nil {
+		return nil, err
+	}
+	models := make([]*Deal, len(objects))
+	for i, obj := range objects {
+		models[i] = &Deal{}
+		if err := decodeModel(obj, models[i]); err != nil {
+			return nil, err
+		}
+	}
+	return models, nil
+}
+
+// Get returns the deal with uid
+func (s *DealService) Get(ctx context.Context, uid string) (*Deal, error) {
+	obj, err := s.api.Fetch(ctx, "deal", uid)
+	if err != nil {
+		return nil, err
+	}
+	m := &Deal{}
+	return m, decodeModel(obj, m)
+}
+
+// Create creates m and returns the deal OX3 made of it
+func (s *DealService) Create(ctx context.Context, m *Deal) (*Deal, error) {
+	fields, err := encodeModel(m, dealReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Create(ctx, "deal", fields)
+	if err != nil {
+		return nil, err
+	}
+	created := &Deal{}
+	return created, decodeModel(obj, created)
+}
+
+// Update sends every set field of m to the deal with m's uid
+func (s *DealService) Update(ctx context.Context, m *Deal) (*Deal, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("deal")
+	}
+	fields, err := encodeModel(m, dealReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "deal", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &Deal{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Patch sends only m's Changes, after checking the deal wasn't modified since m was read.
+// A deal modified in between comes back as a *ConflictError and nothing is sent
+func (s *DealService) Patch(ctx context.Context, m *Deal) (*Deal, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("deal")
+	}
+	fields, err := m.Changes()
+	if err != nil || len(fields) == 0 {
+		return m, err
+	}
+	current, err := s.Get(ctx, m.UID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkConflict("deal", m.UID, m.original, current.original, fields); err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "deal", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &Deal{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Delete removes the deal with uid
+func (s *DealService) Delete(ctx context.Context, uid string) error {
+	return s.api.Remove(ctx, "deal", uid)
+}
+
+// ConversionTag is an OX3 conversiontag
+type ConversionTag struct {
+	UID             string         `json:"uid,omitempty"`
+	ID              Int            `json:"id,omitempty"`
+	Name            string         `json:"name,omitempty"`
+	AccountUID      string         `json:"account_uid,omitempty"`
+	Status          Status         `json:"status,omitempty"`
+	ConversionType  ConversionType `json:"conversion_type,omitempty"`
+	ClickWindowDays Int            `json:"click_window_days,omitempty"`
+	ViewWindowDays  Int            `json:"view_window_days,omitempty"`
+	Value           Decimal        `json:"value,omitempty"`
+	Currency        string         `json:"currency,omitempty"`
+	CreatedDate     *Date          `json:"created_date,omitempty"`
+	ModifiedDate    *Date          `json:"modified_date,omitempty"`
+
+	// original is the conversiontag as OX3 last returned it, Changes compares against it
+	original Object
+}
+
+func (m *ConversionTag) track(obj Object) {
+	m.original = obj
+}
+
+// Changes returns the fields set differently from when the conversiontag was read from OX3, cleared fields are null.
+// Every set field is a change for a conversiontag that wasn't read from OX3
+func (m *ConversionTag) Changes() (Object, error) {
+	return changes(m, m.original, conversionTagReadOnly)
+}
+
+// conversionTagReadOnly are the conversiontag fields OX3 sets itself
+var conversionTagReadOnly = []string{"uid", "id", "created_date", "modified_date"}
+
+// Validate checks the conversiontag's fields, every problem found is listed in the returned ValidationErrors
+func (m *ConversionTag) Validate() error {
+	var errs ValidationErrors
+	errs.required("name", m.Name == "")
+	errs.required("account_uid", m.AccountUID == "")
+	errs.oneOf("status", string(m.Status), m.Status.Valid(), statusValues)
+	errs.required("conversion_type", m.ConversionType == "")
+	errs.oneOf("conversion_type", string(m.ConversionType), m.ConversionType.Valid(), conversionTypeValues)
+	if r, ok := interface{}(m).(ruleValidator); ok {
+		r.validateRules(&errs)
+	}
+	return errs.err()
+}
+
+// ConversionTagService reads and writes conversiontag objects
+type ConversionTagService struct {
+	api ObjectAPI
+}
+
+// NewConversionTagService returns the conversiontag service working through api, such as an openxtest.Store
+func NewConversionTagService(api ObjectAPI) *ConversionTagService {
+	return &ConversionTagService{api: api}
+}
+
+// ConversionTags returns the conversiontag service
+func (c *Client) ConversionTags() *ConversionTagService {
+	return NewConversionTagService(c)
+}
+
+// List returns every conversiontag matching params
+func (s *ConversionTagService) List(ctx context.Context, params map[string]interface{}) ([]*ConversionTag, error) {
+	objects, err := s.api.List(ctx, "conversiontag", params)
+	if err != nil {
+		return nil, err
+	}
+	models := make([]*ConversionTag, len(objects))
+	for i, obj := range objects {
+		models[i] = &ConversionTag{}
+		if err := decodeModel(obj, models[i]); err != nil {
+			return nil, err
+		}
+	}
+	return models, nil
+}
+
+// Get returns the conversiontag with uid
+func (s *ConversionTagService) Get(ctx context.Context, uid string) (*ConversionTag, error) {
+	obj, err := s.api.Fetch(ctx, "conversiontag", uid)
+	if err != nil {
+		return nil, err
+	}
+	m := &ConversionTag{}
+	return m, decodeModel(obj, m)
+}
+
+// Create creates m and returns the conversiontag OX3 made of it
+func (s *ConversionTagService) Create(ctx context.Context, m *ConversionTag) (*ConversionTag, error) {
+	fields, err := encodeModel(m, conversionTagReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Create(ctx, "conversiontag", fields)
+	if err != nil {
+		return nil, err
+	}
+	created := &ConversionTag{}
+	return created, decodeModel(obj, created)
+}
+
+// Update sends every set field of m to the conversiontag with m's uid
+func (s *ConversionTagService) Update(ctx context.Context, m *ConversionTag) (*ConversionTag, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("conversiontag")
+	}
+	fields, err := encodeModel(m, conversionTagReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "conversiontag", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &ConversionTag{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Patch sends only m's Changes, after checking the conversiontag wasn't modified since m was read.
+// A conversiontag modified in between comes back as a *ConflictError and nothing is sent
+func (s *ConversionTagService) Patch(ctx context.Context, m *ConversionTag) (*ConversionTag, error) {
+	if m.UID == "" {
+		return nil, errMissingUID("conversiontag")
+	}
+	fields, err := m.Changes()
+	if err != nil || len(fields) == 0 {
+		return m, err
+	}
+	current, err := s.Get(ctx, m.UID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkConflict("conversiontag", m.UID, m.original, current.original, fields); err != nil {
+		return nil, err
+	}
+	obj, err := s.api.Update(ctx, "conversiontag", m.UID, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated := &ConversionTag{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Delete removes the conversiontag with uid
+func (s *ConversionTagService) Delete(ctx context.Context, uid string) error {
+	return s.api.Remove(ctx, "conversiontag", uid)
+}
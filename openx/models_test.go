@@ -0,0 +1,125 @@
+package openx
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestGeneratedFields every type in the metadata should mark the fields OX3 always sets read only
+func TestGeneratedFields(t *testing.T) {
+	raw, err := ioutil.ReadFile("metadata/ox3.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var meta struct {
+		Types []struct {
+			Type   string
+			Fields []struct {
+				Name     string
+				ReadOnly bool `json:"read_only"`
+			}
+		}
+	}
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		t.Fatal(err)
+	}
+	for _, typ := range meta.Types {
+		readOnly := map[string]bool{}
+		for _, f := range typ.Fields {
+			readOnly[f.Name] = f.ReadOnly
+		}
+		for _, f := range GeneratedFields {
+			if !readOnly[f] {
+				t.Errorf("%s: %s isn't a read only field", typ.Type, f)
+			}
+		}
+	}
+}
+
+// TestModelDecoding numbers should decode whether OX3 sends them as numbers or strings
+func TestModelDecoding(t *testing.T) {
+	raw := `{"uid": "li-1", "id": "42", "name": "spring", "price": 1.25, "budget": "1000.10",
+		"start_date": "2024-03-01 00:00:00", "end_date": null, "type": "house", "targeting": {"geo": "US"}}`
+	var li LineItem
+	if err := json.Unmarshal([]byte(raw), &li); err != nil {
+		t.Fatal(err)
+	}
+	if li.ID != 42 || li.Price != "1.25" || li.Budget != "1000.10" || li.Type != LineItemTypeHouse {
+		t.Errorf("decoded %+v", li)
+	}
+	if !li.StartDate.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) || li.EndDate != nil {
+		t.Errorf("dates decoded to %v and %v", li.StartDate, li.EndDate)
+	}
+	if f, _ := li.Budget.Float(); f != 1000.10 {
+		t.Errorf("budget as float is %v", f)
+	}
+
+	out, err := json.Marshal(li)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back map[string]interface{}
+	json.Unmarshal(out, &back)
+	if back["start_date"] != "2024-03-01 00:00:00" || back["budget"] != "1000.10" {
+		t.Errorf("encoded to %s", out)
+	}
+	if _, ok := back["end_date"]; ok {
+		t.Errorf("unset end_date was sent: %s", out)
+	}
+
+	if err := json.Unmarshal([]byte(`{"id": "x"}`), &li); err == nil {
+		t.Error("expected an error for a non numeric id")
+	}
+}
+
+// TestModelValidate required fields and enums should all be reported
+func TestModelValidate(t *testing.T) {
+	site := &Site{Status: "Archived"}
+	err := site.Validate()
+	verrs, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+	want := []string{"name", "account_uid", "status"}
+	if got := verrs.Fields(); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("invalid fields %v, want %v", got, want)
+	}
+
+	site = &Site{Name: "example.com", AccountUID: "acct", Status: StatusActive}
+	if err := site.Validate(); err != nil {
+		t.Errorf("valid site failed validation: %v", err)
+	}
+}
+
+// TestModelService the typed service should send only writable fields and decode the answer
+func TestModelService(t *testing.T) {
+	var sent map[string]interface{}
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/data/1.0/site" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		raw, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(raw, &sent)
+		w.Write([]byte(`{"uid": "site-1", "id": "7", "name": "example.com", "account_uid": "acct",
+			"created_date": "2024-03-01 10:00:00", "modified_date": "2024-03-01 10:00:00"}`))
+	}))
+
+	created, err := c.Sites().Create(context.Background(), &Site{UID: "ignored", Name: "example.com", AccountUID: "acct"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sent["uid"]; ok || sent["name"] != "example.com" {
+		t.Errorf("sent %v", sent)
+	}
+	if created.UID != "site-1" || created.ID != 7 || created.CreatedDate == nil {
+		t.Errorf("created %+v", created)
+	}
+
+	if _, err := c.Sites().Update(context.Background(), &Site{Name: "x"}); err == nil {
+		t.Error("expected an update without a uid to fail")
+	}
+}
@@ -0,0 +1,269 @@
+package openx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// listPageSize is the limit sent when List walks through a paginated endpoint
+	listPageSize = 500
+
+	// DateFormat is the layout of created_date, modified_date and the other dates OX3 returns, always UTC
+	DateFormat = "2006-01-02 15:04:05"
+
+	// ModifiedSinceParam is the List parameter that limits results to objects modified at or after a date,
+	// use FormatDate for its value
+	ModifiedSinceParam = "modified_since"
+)
+
+// OX3 object types, each one is also the endpoint its objects live under
+const (
+	TypeAccount         = "account"
+	TypeSite            = "site"
+	TypeAdUnit          = "adunit"
+	TypeAdUnitGroup     = "adunitgroup"
+	TypeOrder           = "order"
+	TypeLineItem        = "lineitem"
+	TypeAd              = "ad"
+	TypeCreative        = "creative"
+	TypeUser            = "user"
+	TypeRole            = "role"
+	TypeAudienceSegment = "audiencesegment"
+	TypePackage         = "package"
+	TypeDeal            = "deal"
+	TypeConversionTag   = "conversiontag"
+)
+
+// Object is an OX3 object decoded from JSON, fields keep the names the API uses
+type Object map[string]interface{}
+
+// String returns field formatted as a string, missing and null fields are empty
+func (o Object) String(field string) string {
+	switch v := o[field].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		raw, _ := json.Marshal(v)
+		return string(raw)
+	}
+}
+
+// Time parses a date field, missing fields are the zero time
+func (o Object) Time(field string) (time.Time, error) {
+	v := o.String(field)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	return ParseDate(v)
+}
+
+// ParseDate parses a date in OX3's format
+func ParseDate(v string) (time.Time, error) {
+	return time.ParseInLocation(DateFormat, v, time.UTC)
+}
+
+// FormatDate formats t the way OX3 expects dates
+func FormatDate(t time.Time) string {
+	return t.UTC().Format(DateFormat)
+}
+
+// UID returns the object's uid
+func (o Object) UID() string {
+	return o.String("uid")
+}
+
+// ID returns the object's numeric id as a string
+func (o Object) ID() string {
+	return o.String("id")
+}
+
+// Clone returns a shallow copy of o
+func (o Object) Clone() Object {
+	clone := make(Object, len(o))
+	for k, v := range o {
+		clone[k] = v
+	}
+	return clone
+}
+
+// ObjectAPI is the object level access a *Client provides,
+// packages built on top of the client accept it so they can run against something other than OX3
+type ObjectAPI interface {
+	List(ctx context.Context, objectType string, params map[string]interface{}) ([]Object, error)
+	Fetch(ctx context.Context, objectType, uid string) (Object, error)
+	Create(ctx context.Context, objectType string, obj Object) (Object, error)
+	Update(ctx context.Context, objectType, uid string, fields Object) (Object, error)
+	Remove(ctx context.Context, objectType, uid string) error
+}
+
+var _ ObjectAPI = (*Client)(nil)
+
+// verbAPI is the request level access a *Client provides, endpoints that aren't plain objects go through it
+type verbAPI interface {
+	GetContext(ctx context.Context, url string, urlParms map[string]interface{}) (*http.Response, error)
+	PostContext(ctx context.Context, url string, data io.Reader) (*http.Response, error)
+	DeleteContext(ctx context.Context, url string, data io.Reader) (*http.Response, error)
+}
+
+// verbsOf returns a service's api as a verbAPI, failing for apis such as an openxtest.Store that only handle objects
+func verbsOf(api ObjectAPI, what string) (verbAPI, error) {
+	v, ok := api.(verbAPI)
+	if !ok {
+		return nil, errors.Errorf("Couldn't %s: %T only handles plain objects", what, api)
+	}
+	return v, nil
+}
+
+// listPage is one page of a list endpoint
+type listPage struct {
+	Objects    []Object `json:"objects"`
+	TotalCount int      `json:"total_count"`
+}
+
+// List fetches every object of objectType matching params, following OX3's limit and offset pagination
+func (c *Client) List(ctx context.Context, objectType string, params map[string]interface{}) ([]Object, error) {
+	query := make(map[string]interface{}, len(params)+2)
+	for k, v := range params {
+		query[k] = v
+	}
+	if _, ok := query["limit"]; !ok {
+		query["limit"] = listPageSize
+	}
+
+	var objects []Object
+	for offset := 0; ; {
+		query["offset"] = offset
+		raw, err := c.getJSON(ctx, objectType, query)
+		if err != nil {
+			return nil, err
+		}
+
+		// some endpoints skip the pagination envelope and return everything as an array
+		if raw = bytes.TrimSpace(raw); len(raw) > 0 && raw[0] == '[' {
+			var all []Object
+			if err := json.Unmarshal(raw, &all); err != nil {
+				return nil, errors.Wrapf(err, "Couldn't decode %s list", objectType)
+			}
+			return append(objects, all...), nil
+		}
+
+		var page listPage
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return nil, errors.Wrapf(err, "Couldn't decode %s list", objectType)
+		}
+		objects = append(objects, page.Objects...)
+		offset += len(page.Objects)
+		if len(page.Objects) == 0 || offset >= page.TotalCount {
+			return objects, nil
+		}
+	}
+}
+
+// Fetch gets a single object by uid
+func (c *Client) Fetch(ctx context.Context, objectType, uid string) (Object, error) {
+	raw, err := c.getJSON(ctx, objectType+"/"+uid, nil)
+	if err != nil {
+		return nil, err
+	}
+	return decodeObject(raw, objectType)
+}
+
+// Create posts obj and returns the object OX3 created
+func (c *Client) Create(ctx context.Context, objectType string, obj Object) (Object, error) {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't encode %s", objectType)
+	}
+	res, err := c.PostContext(ctx, objectType, bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	body, err := readResponse(res)
+	if err != nil {
+		return nil, err
+	}
+	return decodeObject(body, objectType)
+}
+
+// Update sends only fields to the object with uid and returns the updated object
+func (c *Client) Update(ctx context.Context, objectType, uid string, fields Object) (Object, error) {
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't encode %s", objectType)
+	}
+	res, err := c.PutContext(ctx, objectType+"/"+uid, bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	body, err := readResponse(res)
+	if err != nil {
+		return nil, err
+	}
+	return decodeObject(body, objectType)
+}
+
+// Remove deletes the object with uid
+func (c *Client) Remove(ctx context.Context, objectType, uid string) error {
+	res, err := c.DeleteContext(ctx, objectType+"/"+uid, nil)
+	if err != nil {
+		return err
+	}
+	_, err = readResponse(res)
+	return err
+}
+
+func (c *Client) getJSON(ctx context.Context, endpoint string, params map[string]interface{}) ([]byte, error) {
+	res, err := c.GetContext(ctx, endpoint, params)
+	if err != nil {
+		return nil, err
+	}
+	return readResponse(res)
+}
+
+// readResponse reads and closes the body of a successful response,
+// anything else comes back as an *APIError
+func readResponse(res *http.Response) ([]byte, error) {
+	if err := checkResponse(res); err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	return ioutil.ReadAll(res.Body)
+}
+
+// decodeObject handles OX3 answering with either the object or a list holding it
+func decodeObject(raw []byte, objectType string) (Object, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && raw[0] == '[' {
+		var objects []Object
+		if err := json.Unmarshal(raw, &objects); err != nil {
+			return nil, errors.Wrapf(err, "Couldn't decode %s", objectType)
+		}
+		if len(objects) == 0 {
+			return nil, errors.Errorf("OX3 returned no %s", objectType)
+		}
+		return objects[0], nil
+	}
+
+	var obj Object
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, errors.Wrapf(err, "Couldn't decode %s", objectType)
+	}
+	return obj, nil
+}
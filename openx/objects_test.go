@@ -0,0 +1,88 @@
+package openx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+// TestList every page should be fetched and the caller's filters passed through
+func TestList(t *testing.T) {
+	var pages int
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		if r.URL.Path != "/data/1.0/site" || r.URL.Query().Get("account_uid") != "acct" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		var objects []Object
+		for i := offset; i < offset+2 && i < 5; i++ {
+			objects = append(objects, Object{"uid": fmt.Sprintf("site-%d", i), "id": float64(i)})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"objects": objects, "total_count": 5})
+	}))
+
+	params := map[string]interface{}{"account_uid": "acct", "limit": 2}
+	sites, err := c.List(context.Background(), TypeSite, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sites) != 5 || pages != 3 {
+		t.Fatalf("expected 5 sites over 3 pages, got %d over %d", len(sites), pages)
+	}
+	if sites[4].UID() != "site-4" || sites[4].ID() != "4" {
+		t.Fatalf("unexpected last site %v", sites[4])
+	}
+	if _, ok := params["offset"]; ok {
+		t.Fatal("List shouldn't modify the caller's params")
+	}
+}
+
+// TestCreateUpdateRemove objects may come back bare or wrapped in a list
+func TestCreateUpdateRemove(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		switch r.Method {
+		case "POST":
+			var obj Object
+			json.Unmarshal(body, &obj)
+			obj["uid"] = "new-uid"
+			json.NewEncoder(w).Encode([]Object{obj})
+		case "PUT":
+			w.Write([]byte(`{"uid":"new-uid","name":"renamed"}`))
+		case "DELETE":
+			if r.URL.Path != "/data/1.0/site/gone" {
+				http.NotFound(w, r)
+			}
+		}
+	}))
+
+	ctx := context.Background()
+	site, err := c.Create(ctx, TypeSite, Object{"name": "example"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if site.UID() != "new-uid" || site.String("name") != "example" {
+		t.Fatalf("unexpected created site %v", site)
+	}
+
+	site, err = c.Update(ctx, TypeSite, site.UID(), Object{"name": "renamed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if site.String("name") != "renamed" {
+		t.Fatalf("unexpected updated site %v", site)
+	}
+
+	if err := c.Remove(ctx, TypeSite, "gone"); err != nil {
+		t.Fatal(err)
+	}
+	err = c.Remove(ctx, TypeSite, "missing")
+	if apiErr, ok := err.(*APIError); !ok || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected a 404 APIError, got %v", err)
+	}
+}
@@ -1,10 +1,12 @@
 package openx
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log/slog"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
@@ -12,15 +14,17 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mrjones/oauth"
 	"github.com/pkg/errors"
-	"github.com/timehop/golog/log"
 )
 
 var (
 	// ErrParameter definitions
 	ErrParameter = errors.New("The value entered must be of type string, int, float64, or bool")
+	// ErrClientClosed is returned by every call made after the client logged out
+	ErrClientClosed = errors.New("the client is logged out")
 	// clean up entered domain just incase user passes in a domain in a way I'm not ready for
 	domainReplacer = strings.NewReplacer(
 		"www.", "",
@@ -29,9 +33,6 @@ var (
 		"//", "",
 		"/", "",
 	)
-
-	// keeping this guy private
-	consumer *oauth.Consumer
 )
 
 const (
@@ -41,7 +42,6 @@ const (
 	authorizationURL = "https://sso.openx.com/login/process"
 	apiPath          = "/data/1.0/"
 	callBack         = "oob"
-	logKey           = "Openx-Package"
 )
 
 // Credentials are to filled in order to auth into openx
@@ -87,11 +87,25 @@ type Client struct {
 	email           string
 	password        string
 	apiPath         string
+	consumer        *oauth.Consumer
 	session         *http.Client
+	logger          *slog.Logger
+	middleware      []Middleware
+	limiter         *rateLimiter
+	validate        bool
+	loggedIn        time.Time
+	me              meCache
+	closed          int32 // set atomically by Logout
 }
 
 // NewClient creates the basic Openx3 *Client via oauth1
-func NewClient(creds Credentials, debug bool) (*Client, error) {
+// debug turns on request/response logging to stderr unless a logger is passed with WithLogger
+func NewClient(creds Credentials, debug bool, opts ...Option) (*Client, error) {
+	return NewClientContext(context.Background(), creds, debug, opts...)
+}
+
+// NewClientContext is NewClient with a context covering the SSO handshake
+func NewClientContext(ctx context.Context, creds Credentials, debug bool, opts ...Option) (*Client, error) {
 	if err := creds.validate(); err != nil {
 		return nil, err
 	}
@@ -106,28 +120,34 @@ func NewClient(creds Credentials, debug bool) (*Client, error) {
 		email:           creds.Email,
 		password:        creds.Password,
 		scheme:          "http",
+		logger:          defaultLogger(debug),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	c.logger = slog.New(newRedactingHandler(c.logger.Handler()))
 
-	// create oauth consumer
-	consumer = oauth.NewConsumer(c.consumerKey, c.consumerSecrect, oauth.ServiceProvider{
+	// create oauth consumer, every request it signs goes out through the client transport.
+	// The oauth package builds its own requests without a context so ctx is handed to them until the handshake is over
+	c.consumer = oauth.NewCustomHttpClientConsumer(c.consumerKey, c.consumerSecrect, oauth.ServiceProvider{
 		RequestTokenUrl:   requestTokenURL,
 		AuthorizeTokenUrl: authorizationURL,
 		AccessTokenUrl:    accessTokenURL,
 		HttpMethod:        "POST",
-	})
-	consumer.Debug(debug)
+	}, &http.Client{Transport: withContext(ctx, c.transport())})
 
-	accessToken, err := c.getAccessToken(debug)
+	accessToken, err := c.getAccessToken(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "Access token could not be generated")
 	}
+	c.consumer.HttpClient = &http.Client{Transport: c.transport()}
 
 	if accessToken == nil {
 		return nil, fmt.Errorf("access token is nil")
 	}
 
 	// create a cookie jar to add the access token to
-	log.Trace(logKey, "Creating cookiejar")
+	c.log().Debug("creating cookiejar")
 
 	cj, err := cookiejar.New(nil)
 	if err != nil {
@@ -136,12 +156,12 @@ func NewClient(creds Credentials, debug bool) (*Client, error) {
 
 	c.domain = domainReplacer.Replace(c.domain)
 	// format the domain
-	base, err := url.Parse(fmt.Sprintf("%s://www.%s", c.scheme, c.domain))
+	base, err := c.cookieURL()
 	if err != nil {
 		return nil, err
 	}
 
-	log.Trace(logKey, "setting openx3_access_token in cookie jar")
+	c.log().Debug("setting openx3_access_token in cookie jar")
 
 	// create auth cookie
 	var cookies []*http.Cookie
@@ -157,19 +177,30 @@ func NewClient(creds Credentials, debug bool) (*Client, error) {
 	cj.SetCookies(base, cookies)
 
 	// create authenticated session
-	log.Trace(logKey, "creating oauth1 session")
+	c.log().Debug("creating oauth1 session")
 
-	session, err := consumer.MakeHttpClient(accessToken)
+	session, err := c.consumer.MakeHttpClient(accessToken)
 	if err != nil {
 		return nil, errors.Wrap(err, "Couldn't create client")
 	}
 	session.Jar = cj
 	c.session = session
+	c.loggedIn = time.Now()
 	return c, nil
 }
 
+// cookieURL is the url the access token cookie is set for
+func (c *Client) cookieURL() (*url.URL, error) {
+	return url.Parse(fmt.Sprintf("%s://www.%s", c.scheme, c.domain))
+}
+
 // NewClientFromFile parses a JSON file to grab your Openx creds
-func NewClientFromFile(filePath string, debug bool) (*Client, error) {
+func NewClientFromFile(filePath string, debug bool, opts ...Option) (*Client, error) {
+	return NewClientFromFileContext(context.Background(), filePath, debug, opts...)
+}
+
+// NewClientFromFileContext is NewClientFromFile with a context covering the SSO handshake
+func NewClientFromFileContext(ctx context.Context, filePath string, debug bool, opts ...Option) (*Client, error) {
 	var creds Credentials
 	contents, err := ioutil.ReadFile(filePath)
 	if err != nil {
@@ -185,12 +216,17 @@ func NewClientFromFile(filePath string, debug bool) (*Client, error) {
 		return nil, err
 	}
 
-	return NewClient(creds, debug)
+	return NewClientContext(ctx, creds, debug, opts...)
 }
 
 // Get is simailiar to the normal Go *http.client.Get,
 // except string parameters can be passed in the url or the as a map[string]interface{}
 func (c *Client) Get(url string, urlParms map[string]interface{}) (*http.Response, error) {
+	return c.GetContext(context.Background(), url, urlParms)
+}
+
+// GetContext is Get with a context that is carried through to every middleware
+func (c *Client) GetContext(ctx context.Context, url string, urlParms map[string]interface{}) (*http.Response, error) {
 	url, err := c.formatURL(url)
 	if err != nil {
 		return nil, err
@@ -216,69 +252,120 @@ func (c *Client) Get(url string, urlParms map[string]interface{}) (*http.Respons
 		}
 		url += p[:len(p)-1]
 	}
-	return c.session.Get(url)
+	return c.do(ctx, "GET", url, nil, "")
 }
 
 // Delete creates a delete request
 func (c *Client) Delete(url string, data io.Reader) (*http.Response, error) {
+	return c.DeleteContext(context.Background(), url, data)
+}
+
+// DeleteContext is Delete with a context that is carried through to every middleware
+func (c *Client) DeleteContext(ctx context.Context, url string, data io.Reader) (*http.Response, error) {
 	url, err := c.formatURL(url)
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequest("DELETE", url, data)
-	if err != nil {
-		return nil, err
-	}
-	return c.session.Do(req)
+	return c.do(ctx, "DELETE", url, data, "")
 }
 
 // Options is a wrapper for a GET request that has the /options endpoint already passed in
 func (c *Client) Options() (*http.Response, error) {
+	return c.OptionsContext(context.Background())
+}
+
+// OptionsContext is Options with a context that is carried through to every middleware
+func (c *Client) OptionsContext(ctx context.Context) (*http.Response, error) {
 	url, err := c.formatURL("/options")
 	if err != nil {
 		return nil, err
 	}
-	return c.session.Get(url)
+	return c.do(ctx, "GET", url, nil, "")
 }
 
 // Put creates a put request
 func (c *Client) Put(url string, data io.Reader) (*http.Response, error) {
-	url, err := c.formatURL(url)
+	return c.PutContext(context.Background(), url, data)
+}
+
+// PutContext is Put with a context that is carried through to every middleware
+func (c *Client) PutContext(ctx context.Context, url string, data io.Reader) (*http.Response, error) {
+	data, err := c.checkBody("PUT", url, data)
 	if err != nil {
 		return nil, err
 	}
-
-	req, err := http.NewRequest("PUT", url, data)
+	url, err = c.formatURL(url)
 	if err != nil {
 		return nil, err
 	}
-
-	return c.session.Do(req)
+	return c.do(ctx, "PUT", url, data, "")
 }
 
 // Post is a wrapper for the basic Go *http.client.Post, however content type is automatically set to application/json
 func (c *Client) Post(url string, data io.Reader) (*http.Response, error) {
-	url, err := c.formatURL(url)
+	return c.PostContext(context.Background(), url, data)
+}
+
+// PostContext is Post with a context that is carried through to every middleware
+func (c *Client) PostContext(ctx context.Context, url string, data io.Reader) (*http.Response, error) {
+	data, err := c.checkBody("POST", url, data)
+	if err != nil {
+		return nil, err
+	}
+	url, err = c.formatURL(url)
 	if err != nil {
 		return nil, err
 	}
-	return c.session.Post(url, "application/json", data)
+	return c.do(ctx, "POST", url, data, "application/json")
 }
 
 // PostForm is a wrapper for the basic Go *http.client.PostForm
 func (c *Client) PostForm(url string, data url.Values) (*http.Response, error) {
+	return c.PostFormContext(context.Background(), url, data)
+}
+
+// PostFormContext is PostForm with a context that is carried through to every middleware
+func (c *Client) PostFormContext(ctx context.Context, url string, data url.Values) (*http.Response, error) {
 	url, err := c.formatURL(url)
 	if err != nil {
 		return nil, err
 	}
-	return c.session.PostForm(url, data)
+	return c.do(ctx, "POST", url, strings.NewReader(data.Encode()), "application/x-www-form-urlencoded")
+}
+
+// do sends a request to an already formatted url through the authenticated session
+func (c *Client) do(ctx context.Context, method, url string, body io.Reader, contentType string) (*http.Response, error) {
+	if c.isClosed() {
+		return nil, ErrClientClosed
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return c.session.Do(req)
+}
+
+// transport is the http.RoundTripper every outbound request ends up on,
+// middleware wrap the rate limit and the request logging which sit closest to the wire
+func (c *Client) transport() http.RoundTripper {
+	base := RoundTripFunc((&loggingTransport{next: http.DefaultTransport, logger: c.log()}).RoundTrip)
+	if c.limiter != nil {
+		base = c.limiter.middleware(base)
+	}
+	return Chain(c.middleware...)(base)
 }
 
-// LogOff sets the created session to an empty http.client
-func (c *Client) LogOff() (res *http.Response, err error) {
-	// set the session to an empty struct to clear auth information
-	c.session = &http.Client{}
-	return
+// withContext gives requests built without a context ctx instead
+func withContext(ctx context.Context, next http.RoundTripper) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		if req.Context() == context.Background() {
+			req = req.WithContext(ctx)
+		}
+		return next.RoundTrip(req)
+	}
 }
 
 func (c *Client) formatURL(endpoint string) (string, error) {
@@ -295,27 +382,33 @@ func (c *Client) formatURL(endpoint string) (string, error) {
 	return uri, nil
 }
 
-func (c *Client) getAccessToken(debug bool) (*oauth.AccessToken, error) {
-	requestToken, requestURL, err := consumer.GetRequestTokenAndUrl(callBack)
+func (c *Client) getAccessToken(ctx context.Context) (*oauth.AccessToken, error) {
+	requestToken, requestURL, err := c.consumer.GetRequestTokenAndUrl(callBack)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Trace(logKey, "Requests Token generated")
+	c.log().Debug("request token generated")
 	// auth into openx
-	request := http.Client{}
+	request := http.Client{Transport: c.transport()}
 	urlData := url.Values{}
 	urlData.Set("email", c.email)
 	urlData.Set("password", c.password)
 	urlData.Set("oauth_token", requestToken.Token)
 
-	resp, err := request.PostForm(requestURL, urlData)
+	req, err := http.NewRequestWithContext(ctx, "POST", requestURL, strings.NewReader(urlData.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := request.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	log.Trace(logKey, "Getting auth token")
+	c.log().Debug("getting auth token")
 
 	if resp.StatusCode != http.StatusOK {
 		return nil, errors.Wrapf(err, "Couldn't get authorization status returned: %d", resp.StatusCode)
@@ -345,12 +438,12 @@ func (c *Client) getAccessToken(debug bool) (*oauth.AccessToken, error) {
 	oauthVerifier = authInfo["oauth_verifier"][0]
 
 	// use oauth_verifier to get access_token
-	accessToken, err := consumer.AuthorizeToken(requestToken, oauthVerifier)
+	accessToken, err := c.consumer.AuthorizeToken(requestToken, oauthVerifier)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Trace(logKey, "Access Token generated")
+	c.log().Debug("access token generated")
 	return accessToken, nil
 }
 
@@ -385,17 +478,17 @@ func CreateConfigFileTemplate(fileCreationPath string) string {
 
 	f, err := os.Create(fileCreationPath)
 	if err != nil {
-		log.Error(logKey, "Couldn't create the file", "filename", fileCreationPath)
+		slog.Error("Couldn't create the file", "filename", fileCreationPath)
 		panic(err)
 	}
 	defer f.Close()
 
 	_, err = f.WriteString(configFile)
 	if err != nil {
-		log.Error(logKey, "Couldn't write data to the file", "filename", fileCreationPath)
+		slog.Error("Couldn't write data to the file", "filename", fileCreationPath)
 		panic(err)
 	}
 
-	log.Info(logKey, "The file was created", "filepath", fileCreationPath)
+	slog.Info("The file was created", "filepath", fileCreationPath)
 	return fileCreationPath
 }
@@ -1,8 +1,12 @@
 package openx
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/user"
+	"strings"
 	"testing"
 )
 
@@ -64,3 +68,57 @@ func TestBadAuthFromFile(t *testing.T) {
 	t.Logf("TestBadAuthFromFile File was removed: %s\n", path)
 
 }
+
+// newTestClient builds a Client that skips the SSO handshake and talks to an httptest server
+func newTestClient(t *testing.T, handler http.Handler, opts ...Option) *Client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	c := &Client{
+		domain:  strings.TrimPrefix(srv.URL, "http://"),
+		scheme:  "http",
+		apiPath: apiPath,
+		logger:  discardLogger,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.session = &http.Client{Transport: c.transport()}
+	return c
+}
+
+type ctxKey struct{}
+
+// TestContextPropagation the caller's context should reach every middleware
+func TestContextPropagation(t *testing.T) {
+	var got interface{}
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		WithMiddleware(func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				got = req.Context().Value(ctxKey{})
+				return next(req)
+			}
+		}))
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "caller")
+	res, err := c.GetContext(ctx, "/account", map[string]interface{}{"limit": 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if got != "caller" {
+		t.Fatalf("expected the caller's context, got %v", got)
+	}
+
+	// requests built by the oauth package have no context, withContext fills in the handshake's
+	got = nil
+	req, _ := http.NewRequest("GET", "http://"+c.domain+"/", nil)
+	res, err = withContext(ctx, c.transport()).RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if got != "caller" {
+		t.Fatalf("expected the handshake context, got %v", got)
+	}
+}
@@ -0,0 +1,227 @@
+// Package openxtest provides an in-memory stand-in for OX3 to test code built on openx.ObjectAPI
+package openxtest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+)
+
+// Store keeps objects in memory and implements openx.ObjectAPI.
+// List filters on exact field matches and openx.ModifiedSinceParam, limit and offset are ignored
+type Store struct {
+	// Now stamps created_date and modified_date, defaults to time.Now
+	Now func() time.Time
+
+	mu      sync.Mutex
+	objects map[string][]openx.Object
+	nextID  int
+	calls   []string
+	fail    map[string]error
+}
+
+var _ openx.ObjectAPI = (*Store)(nil)
+
+// NewStore returns an empty Store
+func NewStore() *Store {
+	return &Store{
+		objects: map[string][]openx.Object{},
+		fail:    map[string]error{},
+		nextID:  1,
+	}
+}
+
+// Seed adds objects without going through Create, uid and id are assigned when missing
+func (s *Store) Seed(objectType string, objects ...openx.Object) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, obj := range objects {
+		obj = obj.Clone()
+		s.assignIDs(obj)
+		s.objects[objectType] = append(s.objects[objectType], obj)
+	}
+}
+
+// Objects returns a copy of every stored object of objectType
+func (s *Store) Objects(objectType string) []openx.Object {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	objects := make([]openx.Object, len(s.objects[objectType]))
+	for i, obj := range s.objects[objectType] {
+		objects[i] = obj.Clone()
+	}
+	return objects
+}
+
+// Calls returns every call made so far as "<op> <type>[/<uid>]", op being list, fetch, create, update or remove
+func (s *Store) Calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.calls...)
+}
+
+// FailOn makes the next call of op on objectType return err, op is list, fetch, create, update or remove
+func (s *Store) FailOn(op, objectType string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fail[op+" "+objectType] = err
+}
+
+// List returns the objects whose fields match every parameter other than limit and offset
+func (s *Store) List(ctx context.Context, objectType string, params map[string]interface{}) ([]openx.Object, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.record("list", objectType, ""); err != nil {
+		return nil, err
+	}
+
+	var objects []openx.Object
+	for _, obj := range s.objects[objectType] {
+		if matches(obj, params) {
+			objects = append(objects, obj.Clone())
+		}
+	}
+	return objects, nil
+}
+
+// Fetch returns the object with uid or a 404 *openx.APIError
+func (s *Store) Fetch(ctx context.Context, objectType, uid string) (openx.Object, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.record("fetch", objectType, uid); err != nil {
+		return nil, err
+	}
+
+	i := s.find(objectType, uid)
+	if i < 0 {
+		return nil, notFound("GET", objectType, uid)
+	}
+	return s.objects[objectType][i].Clone(), nil
+}
+
+// Create stores obj with a fresh uid, id and dates
+func (s *Store) Create(ctx context.Context, objectType string, obj openx.Object) (openx.Object, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.record("create", objectType, ""); err != nil {
+		return nil, err
+	}
+
+	obj = obj.Clone()
+	delete(obj, "uid")
+	delete(obj, "id")
+	s.assignIDs(obj)
+	now := s.now()
+	obj["created_date"] = now
+	obj["modified_date"] = now
+	s.objects[objectType] = append(s.objects[objectType], obj)
+	return obj.Clone(), nil
+}
+
+// Update merges fields into the object with uid and bumps its modified_date
+func (s *Store) Update(ctx context.Context, objectType, uid string, fields openx.Object) (openx.Object, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.record("update", objectType, uid); err != nil {
+		return nil, err
+	}
+
+	i := s.find(objectType, uid)
+	if i < 0 {
+		return nil, notFound("PUT", objectType, uid)
+	}
+	obj := s.objects[objectType][i]
+	for k, v := range fields {
+		if k == "uid" || k == "id" {
+			continue
+		}
+		obj[k] = v
+	}
+	obj["modified_date"] = s.now()
+	return obj.Clone(), nil
+}
+
+// Remove deletes the object with uid
+func (s *Store) Remove(ctx context.Context, objectType, uid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.record("remove", objectType, uid); err != nil {
+		return err
+	}
+
+	i := s.find(objectType, uid)
+	if i < 0 {
+		return notFound("DELETE", objectType, uid)
+	}
+	objects := s.objects[objectType]
+	s.objects[objectType] = append(objects[:i:i], objects[i+1:]...)
+	return nil
+}
+
+func (s *Store) record(op, objectType, uid string) error {
+	call := op + " " + objectType
+	if uid != "" {
+		call += "/" + uid
+	}
+	s.calls = append(s.calls, call)
+
+	key := op + " " + objectType
+	if err, ok := s.fail[key]; ok {
+		delete(s.fail, key)
+		return err
+	}
+	return nil
+}
+
+func (s *Store) find(objectType, uid string) int {
+	for i, obj := range s.objects[objectType] {
+		if obj.UID() == uid {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Store) assignIDs(obj openx.Object) {
+	if obj.UID() == "" {
+		obj["uid"] = fmt.Sprintf("%08x-0000-4000-8000-%012x", s.nextID, s.nextID)
+	}
+	if obj.ID() == "" {
+		obj["id"] = float64(s.nextID)
+	}
+	s.nextID++
+}
+
+func (s *Store) now() string {
+	if s.Now != nil {
+		return openx.FormatDate(s.Now())
+	}
+	return openx.FormatDate(time.Now())
+}
+
+func matches(obj openx.Object, params map[string]interface{}) bool {
+	for k, v := range params {
+		switch k {
+		case "limit", "offset":
+			continue
+		case openx.ModifiedSinceParam:
+			// dates in OX3's format sort as text
+			if obj.String("modified_date") < fmt.Sprint(v) {
+				return false
+			}
+			continue
+		}
+		if obj.String(k) != fmt.Sprint(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func notFound(method, objectType, uid string) error {
+	return &openx.APIError{Method: method, URL: objectType + "/" + uid, StatusCode: http.StatusNotFound}
+}
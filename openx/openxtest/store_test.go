@@ -0,0 +1,64 @@
+package openxtest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+)
+
+func TestStore(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	store := NewStore()
+	store.Now = func() time.Time { return now }
+	ctx := context.Background()
+
+	site, err := store.Create(ctx, openx.TypeSite, openx.Object{"name": "example.com", "account_uid": "acct"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if site.UID() == "" || site.String("modified_date") != "2026-01-02 03:04:05" {
+		t.Fatalf("expected ids and dates to be assigned, got %v", site)
+	}
+
+	store.Seed(openx.TypeSite, openx.Object{"name": "other.com", "account_uid": "other"})
+	sites, err := store.List(ctx, openx.TypeSite, map[string]interface{}{"account_uid": "acct", "limit": 10})
+	if err != nil || len(sites) != 1 {
+		t.Fatalf("expected one site for acct, got %v %v", sites, err)
+	}
+
+	now = now.Add(time.Hour)
+	site, err = store.Update(ctx, openx.TypeSite, site.UID(), openx.Object{"url": "http://example.com"})
+	if err != nil || site.String("modified_date") != "2026-01-02 04:04:05" {
+		t.Fatalf("expected modified_date to move, got %v %v", site, err)
+	}
+
+	if err := store.Remove(ctx, openx.TypeSite, site.UID()); err != nil {
+		t.Fatal(err)
+	}
+	_, err = store.Fetch(ctx, openx.TypeSite, site.UID())
+	if apiErr, ok := err.(*openx.APIError); !ok || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected a 404 after removing, got %v", err)
+	}
+	if calls := store.Calls(); len(calls) != 5 || calls[0] != "create site" {
+		t.Fatalf("unexpected calls %v", calls)
+	}
+}
+
+func TestStoreModifiedSince(t *testing.T) {
+	store := NewStore()
+	store.Seed(openx.TypeOrder,
+		openx.Object{"name": "old", "modified_date": "2024-01-01 00:00:00"},
+		openx.Object{"name": "new", "modified_date": "2024-03-01 00:00:00"},
+	)
+	since := openx.FormatDate(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+	orders, err := store.List(context.Background(), openx.TypeOrder, map[string]interface{}{openx.ModifiedSinceParam: since})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 1 || orders[0].String("name") != "new" {
+		t.Errorf("got %v, want only the order modified after %s", orders, since)
+	}
+}
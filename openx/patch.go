@@ -0,0 +1,84 @@
+package openx
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ConflictError is a patch refused because the object changed in OX3 after it was read
+type ConflictError struct {
+	Type string
+	UID  string
+	// Read is the version the patch was based on and Current the one OX3 has,
+	// the revision when the object has one, its modified_date otherwise
+	Read    string
+	Current string
+	// Fields are the fields both the patch and the other change touched, empty when they don't overlap
+	Fields []string
+}
+
+func (e *ConflictError) Error() string {
+	msg := fmt.Sprintf("%s %s changed in OX3 since it was read (%s, now %s)", e.Type, e.UID, e.Read, e.Current)
+	if len(e.Fields) > 0 {
+		msg += ", both changed " + strings.Join(e.Fields, ", ")
+	}
+	return msg
+}
+
+// objectVersion is what tells two reads of an object apart
+func objectVersion(obj Object) string {
+	if v := obj.String("revision"); v != "" {
+		return v
+	}
+	return obj.String("modified_date")
+}
+
+// checkConflict compares the object a patch was based on with the one OX3 has now.
+// There's no conditional update in the API, a change landing between the check and the update goes unnoticed
+func checkConflict(objectType, uid string, read, current, patch Object) error {
+	if read == nil {
+		return nil
+	}
+	if objectVersion(read) == objectVersion(current) {
+		return nil
+	}
+
+	conflict := &ConflictError{Type: objectType, UID: uid, Read: objectVersion(read), Current: objectVersion(current)}
+	for field := range patch {
+		if !reflect.DeepEqual(read[field], current[field]) {
+			conflict.Fields = append(conflict.Fields, field)
+		}
+	}
+	sort.Strings(conflict.Fields)
+	return conflict
+}
+
+// changes returns the fields of model that differ from original, leaving out readOnly ones
+func changes(model interface{}, original Object, readOnly []string) (Object, error) {
+	current, err := encodeModel(model, readOnly)
+	if err != nil {
+		return nil, err
+	}
+	if original == nil {
+		return current, nil
+	}
+
+	skip := map[string]bool{}
+	for _, f := range readOnly {
+		skip[f] = true
+	}
+	changed := Object{}
+	for k, v := range current {
+		if !reflect.DeepEqual(v, original[k]) {
+			changed[k] = v
+		}
+	}
+	for k := range original {
+		if _, ok := current[k]; !ok && !skip[k] {
+			changed[k] = nil
+		}
+	}
+	return changed, nil
+}
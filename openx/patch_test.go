@@ -0,0 +1,117 @@
+package openx
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeAPI holds objects of a single type for service tests, Update bumps modified_date
+type fakeAPI struct {
+	ObjectAPI
+	objects map[string]Object
+	updates []Object
+	clock   int
+}
+
+func (f *fakeAPI) Fetch(ctx context.Context, objectType, uid string) (Object, error) {
+	return f.objects[uid].Clone(), nil
+}
+
+func (f *fakeAPI) Update(ctx context.Context, objectType, uid string, fields Object) (Object, error) {
+	f.updates = append(f.updates, fields)
+	obj := f.objects[uid]
+	for k, v := range fields {
+		if v == nil {
+			delete(obj, k)
+			continue
+		}
+		obj[k] = v
+	}
+	f.clock++
+	obj["modified_date"] = FormatDate(time.Date(2024, 3, 1+f.clock, 0, 0, 0, 0, time.UTC))
+	return obj.Clone(), nil
+}
+
+func newFakeAPI() *fakeAPI {
+	return &fakeAPI{objects: map[string]Object{
+		"site-1": {"uid": "site-1", "id": "1", "name": "example.com", "account_uid": "acct",
+			"url": "http://example.com", "modified_date": "2024-03-01 00:00:00"},
+	}}
+}
+
+// TestPatch only the fields changed since the read should be sent
+func TestPatch(t *testing.T) {
+	ctx := context.Background()
+	api := newFakeAPI()
+	sites := NewSiteService(api)
+
+	site, err := sites.Get(ctx, "site-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes, _ := site.Changes(); len(changes) != 0 {
+		t.Errorf("a freshly read site has changes %v", changes)
+	}
+
+	site.Name = "example.org"
+	site.URL = ""
+	updated, err := sites.Patch(ctx, site)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Object{{"name": "example.org", "url": nil}}
+	if !reflect.DeepEqual(api.updates, want) {
+		t.Errorf("sent %v, want %v", api.updates, want)
+	}
+	if updated.Name != "example.org" || updated.URL != "" {
+		t.Errorf("patched site is %+v", updated)
+	}
+
+	// the patched site tracks the new version, patching it again without changes sends nothing
+	if _, err := sites.Patch(ctx, updated); err != nil || len(api.updates) != 1 {
+		t.Errorf("an unchanged patch sent %v, %v", api.updates, err)
+	}
+
+	// a site built by hand sends every field it sets
+	if changes, _ := (&Site{UID: "site-1", Name: "x"}).Changes(); !reflect.DeepEqual(changes, Object{"name": "x"}) {
+		t.Errorf("changes of an untracked site are %v", changes)
+	}
+}
+
+// TestPatchConflict a site changed by someone else since the read shouldn't be overwritten
+func TestPatchConflict(t *testing.T) {
+	ctx := context.Background()
+	api := newFakeAPI()
+	sites := NewSiteService(api)
+
+	mine, err := sites.Get(ctx, "site-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	theirs, _ := sites.Get(ctx, "site-1")
+	theirs.URL = "https://example.com"
+	if _, err := sites.Patch(ctx, theirs); err != nil {
+		t.Fatal(err)
+	}
+
+	mine.URL = "http://example.org"
+	mine.Name = "example.org"
+	_, err = sites.Patch(ctx, mine)
+	conflict, ok := err.(*ConflictError)
+	if !ok {
+		t.Fatalf("expected a *ConflictError, got %v", err)
+	}
+	if conflict.Read != "2024-03-01 00:00:00" || conflict.Current != "2024-03-02 00:00:00" || !reflect.DeepEqual(conflict.Fields, []string{"url"}) {
+		t.Errorf("got conflict %+v", conflict)
+	}
+	if len(api.updates) != 1 {
+		t.Errorf("the conflicting patch was sent: %v", api.updates)
+	}
+
+	// a revision, when there is one, decides
+	if err := checkConflict(TypeSite, "site-1", Object{"revision": "3", "modified_date": "a"}, Object{"revision": "3", "modified_date": "b"}, nil); err != nil {
+		t.Errorf("same revision conflicted: %v", err)
+	}
+}
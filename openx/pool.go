@@ -0,0 +1,191 @@
+package openx
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PoolOptions configures a Pool
+type PoolOptions struct {
+	// Debug is passed on to NewClient
+	Debug bool
+	// Options are given to every client. They're applied once per client, except WithRateLimit
+	// whose limiter is shared so the limit covers every instance together
+	Options []Option
+	// IdleTimeout is how long a client can go unused before it's logged in again, 30 minutes when zero
+	IdleTimeout time.Duration
+}
+
+// Pool hands out one Client per credentials profile, the domain and realm of the OX3 instance,
+// logging in the first time a profile is asked for. It's safe to use from many goroutines.
+// Ask the pool for the client every time rather than holding on to it, the pool logs idle clients in
+// again and Prune logs them off
+//
+//	pool := openx.NewPool(openx.PoolOptions{Options: []openx.Option{openx.WithRateLimit(10, time.Second)}})
+//	defer pool.Close()
+//	client, err := pool.Client(ctx, creds)
+type Pool struct {
+	opts    PoolOptions
+	limiter *rateLimiter
+	now     func() time.Time
+
+	mu      sync.Mutex
+	clients map[string]*pooled
+}
+
+// pooled is a profile's client, mu is held while logging in so a profile only logs in once at a time
+type pooled struct {
+	mu       sync.Mutex
+	creds    Credentials
+	client   *Client
+	lastUsed int64 // unix nanoseconds, updated by every request the client makes
+}
+
+// NewPool returns an empty Pool
+func NewPool(opts PoolOptions) *Pool {
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = 30 * time.Minute
+	}
+	// the options are tried on a client that's never used to pick up the limiter they set
+	probe := &Client{}
+	for _, opt := range opts.Options {
+		opt(probe)
+	}
+	return &Pool{
+		opts:    opts,
+		limiter: probe.limiter,
+		now:     time.Now,
+		clients: map[string]*pooled{},
+	}
+}
+
+// profile is the pool key of a set of credentials
+func (c Credentials) profile() string {
+	return domainReplacer.Replace(c.Domain) + "/" + c.Realm
+}
+
+// Client returns the client of creds' profile, logging in when there's none yet, when it's been idle
+// longer than IdleTimeout or when creds changed since it logged in
+func (p *Pool) Client(ctx context.Context, creds Credentials) (*Client, error) {
+	if err := creds.validate(); err != nil {
+		return nil, err
+	}
+	key := creds.profile()
+
+	p.mu.Lock()
+	e, ok := p.clients[key]
+	if !ok {
+		e = &pooled{}
+		p.clients[key] = e
+	}
+	p.mu.Unlock()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.client != nil && (e.creds != creds || p.idle(e)) {
+		e.client.Close()
+		e.client = nil
+	}
+	if e.client == nil {
+		opts := append(append([]Option{}, p.opts.Options...), p.shared(e))
+		client, err := NewClientContext(ctx, creds, p.opts.Debug, opts...)
+		if err != nil {
+			return nil, err
+		}
+		e.client, e.creds = client, creds
+	}
+	p.touch(e)
+	return e.client, nil
+}
+
+// shared gives a pooled client the pool's limiter and has it record when it was last used
+func (p *Pool) shared(e *pooled) Option {
+	return func(c *Client) {
+		c.limiter = p.limiter
+		// first so a request answered by a middleware still counts as use
+		c.middleware = append([]Middleware{func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				p.touch(e)
+				return next(req)
+			}
+		}}, c.middleware...)
+	}
+}
+
+func (p *Pool) touch(e *pooled) {
+	atomic.StoreInt64(&e.lastUsed, p.now().UnixNano())
+}
+
+func (p *Pool) idle(e *pooled) bool {
+	return p.now().Sub(time.Unix(0, atomic.LoadInt64(&e.lastUsed))) > p.opts.IdleTimeout
+}
+
+// Profiles lists the profiles with a logged in client, as domain/realm
+func (p *Pool) Profiles() []string {
+	p.mu.Lock()
+	entries := make(map[string]*pooled, len(p.clients))
+	for key, e := range p.clients {
+		entries[key] = e
+	}
+	p.mu.Unlock()
+
+	var profiles []string
+	for key, e := range entries {
+		e.mu.Lock()
+		if e.client != nil {
+			profiles = append(profiles, key)
+		}
+		e.mu.Unlock()
+	}
+	sort.Strings(profiles)
+	return profiles
+}
+
+// Prune logs off the clients idle longer than IdleTimeout and returns how many,
+// call it every now and then to close sessions nobody uses anymore
+func (p *Pool) Prune() int {
+	return p.close(p.idle)
+}
+
+// Close logs off every client, the pool can still be used afterwards
+func (p *Pool) Close() {
+	p.close(func(*pooled) bool { return true })
+}
+
+func (p *Pool) close(match func(*pooled) bool) int {
+	p.mu.Lock()
+	entries := make(map[string]*pooled, len(p.clients))
+	for key, e := range p.clients {
+		entries[key] = e
+	}
+	p.mu.Unlock()
+
+	closed := 0
+	for key, e := range entries {
+		e.mu.Lock()
+		if e.client != nil && match(e) {
+			e.client.Close()
+			e.client = nil
+			closed++
+		}
+		drop := e.client == nil
+		e.mu.Unlock()
+
+		if drop {
+			p.mu.Lock()
+			// the profile may have been asked for again in the meantime, a login in progress keeps it
+			if p.clients[key] == e && e.mu.TryLock() {
+				if e.client == nil {
+					delete(p.clients, key)
+				}
+				e.mu.Unlock()
+			}
+			p.mu.Unlock()
+		}
+	}
+	return closed
+}
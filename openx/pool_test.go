@@ -0,0 +1,166 @@
+package openx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeSSO answers the SSO handshake and every API call without going to the network,
+// counting logins by domain
+type fakeSSO struct {
+	mu     sync.Mutex
+	logins map[string]int
+}
+
+func (f *fakeSSO) middleware(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		rec := httptest.NewRecorder()
+		switch SSOStep(req) {
+		case SSORequestToken:
+			fmt.Fprint(rec, "oauth_token=request&oauth_token_secret=secret&oauth_callback_confirmed=true")
+		case SSOLogin:
+			req.ParseForm()
+			f.mu.Lock()
+			if f.logins == nil {
+				f.logins = map[string]int{}
+			}
+			f.logins[req.PostForm.Get("email")]++
+			f.mu.Unlock()
+			fmt.Fprint(rec, "oob?oauth_verifier=verified")
+		case SSOAccessToken:
+			fmt.Fprint(rec, "oauth_token=access&oauth_token_secret=secret")
+		default:
+			fmt.Fprint(rec, "{}")
+		}
+		return rec.Result(), nil
+	}
+}
+
+func (f *fakeSSO) count(email string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.logins[email]
+}
+
+func poolCreds(domain string) Credentials {
+	return Credentials{Domain: domain, Realm: "realm", ConsumerKey: "key", ConsumerSecrect: "secret", Email: domain + "@example.com", Password: "password"}
+}
+
+// TestPoolLazyLogin every profile should log in once however many goroutines ask for it
+func TestPoolLazyLogin(t *testing.T) {
+	sso := &fakeSSO{}
+	pool := NewPool(PoolOptions{Options: []Option{WithMiddleware(sso.middleware), WithLogger(discardLogger)}})
+	defer pool.Close()
+
+	var wg sync.WaitGroup
+	clients := make([]*Client, 20)
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c, err := pool.Client(context.Background(), poolCreds(fmt.Sprintf("pub%d.ox.example.com", i%2)))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			clients[i] = c
+		}(i)
+	}
+	wg.Wait()
+
+	for _, domain := range []string{"pub0.ox.example.com", "pub1.ox.example.com"} {
+		if n := sso.count(domain + "@example.com"); n != 1 {
+			t.Errorf("%s logged in %d times, want 1", domain, n)
+		}
+	}
+	if clients[0] != clients[2] || clients[0] == clients[1] {
+		t.Error("expected one client per profile")
+	}
+	if got := strings.Join(pool.Profiles(), ","); got != "pub0.ox.example.com/realm,pub1.ox.example.com/realm" {
+		t.Errorf("got profiles %s", got)
+	}
+}
+
+// TestPoolIdle idle clients should log in again when asked for and be logged off by Prune
+func TestPoolIdle(t *testing.T) {
+	sso := &fakeSSO{}
+	pool := NewPool(PoolOptions{Options: []Option{WithMiddleware(sso.middleware), WithLogger(discardLogger)}, IdleTimeout: time.Minute})
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var offset int64
+	pool.now = func() time.Time { return now.Add(time.Duration(atomic.LoadInt64(&offset))) }
+	advance := func(d time.Duration) { atomic.AddInt64(&offset, int64(d)) }
+
+	creds := poolCreds("pub.ox.example.com")
+	c, err := pool.Client(context.Background(), creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// requests keep the client from going idle
+	advance(50 * time.Second)
+	res, err := c.Get("/account", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	advance(50 * time.Second)
+	if again, _ := pool.Client(context.Background(), creds); again != c {
+		t.Error("a client in use was replaced")
+	}
+
+	advance(2 * time.Minute)
+	if again, _ := pool.Client(context.Background(), creds); again == c {
+		t.Error("an idle client wasn't logged in again")
+	}
+	if n := sso.count(creds.Email); n != 2 {
+		t.Errorf("logged in %d times, want 2", n)
+	}
+
+	if n := pool.Prune(); n != 0 {
+		t.Errorf("pruned %d fresh clients", n)
+	}
+	advance(2 * time.Minute)
+	if n := pool.Prune(); n != 1 {
+		t.Errorf("pruned %d clients, want 1", n)
+	}
+	if len(pool.Profiles()) != 0 {
+		t.Errorf("got profiles %v after Prune", pool.Profiles())
+	}
+}
+
+// TestPoolSharedLimiter every client of a pool should draw from the same rate limit
+func TestPoolSharedLimiter(t *testing.T) {
+	sso := &fakeSSO{}
+	pool := NewPool(PoolOptions{Options: []Option{WithMiddleware(sso.middleware), WithLogger(discardLogger), WithRateLimit(10, time.Hour)}})
+	defer pool.Close()
+
+	a, err := pool.Client(context.Background(), poolCreds("a.ox.example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := pool.Client(context.Background(), poolCreds("b.ox.example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.limiter == nil || a.limiter != b.limiter {
+		t.Fatal("expected the clients to share the pool's limiter")
+	}
+}
+
+// TestPoolInvalidCredentials should fail without logging in
+func TestPoolInvalidCredentials(t *testing.T) {
+	pool := NewPool(PoolOptions{})
+	if _, err := pool.Client(context.Background(), Credentials{Domain: "pub.ox.example.com"}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(pool.Profiles()) != 0 {
+		t.Errorf("got profiles %v", pool.Profiles())
+	}
+}
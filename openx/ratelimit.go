@@ -0,0 +1,70 @@
+package openx
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WithRateLimit caps the client at requests per interval, allowing short bursts of up to requests at once.
+// The limit covers every round trip the client makes including retries and the SSO handshake
+func WithRateLimit(requests int, per time.Duration) Option {
+	return func(c *Client) {
+		if requests <= 0 || per <= 0 {
+			c.limiter = nil
+			return
+		}
+		c.limiter = newRateLimiter(requests, per)
+	}
+}
+
+// rateLimiter spaces requests out evenly, it keeps the time the next request may be sent
+// and lets that time fall behind now by at most burst requests
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    int
+	next     time.Time
+}
+
+func newRateLimiter(requests int, per time.Duration) *rateLimiter {
+	return &rateLimiter{
+		interval: per / time.Duration(requests),
+		burst:    requests,
+	}
+}
+
+// wait blocks until a request may be sent or ctx is done
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if earliest := now.Add(-time.Duration(l.burst-1) * l.interval); l.next.Before(earliest) {
+		l.next = earliest
+	}
+	at := l.next
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := at.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (l *rateLimiter) middleware(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		if err := l.wait(req.Context()); err != nil {
+			return nil, err
+		}
+		return next(req)
+	}
+}
@@ -0,0 +1,40 @@
+package openx
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestRateLimit a burst goes out at once and the rest is spaced out
+func TestRateLimit(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		WithRateLimit(5, 100*time.Millisecond))
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		res, err := c.Get("/account", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+	// 5 go out in the burst, the other 5 are 20ms apart
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("expected the limit to slow requests down, took %s", elapsed)
+	}
+}
+
+// TestRateLimitContext waiting on the limit stops when the context is done
+func TestRateLimitContext(t *testing.T) {
+	l := newRateLimiter(1, time.Hour)
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected the deadline to cut the wait short, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package openx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// reportEndpoint runs a report and answers with its rows
+const reportEndpoint = "report/run"
+
+// ReportRequest says which report to run, over which days and with which columns
+type ReportRequest struct {
+	// Report is the report's code, such as inv_rev
+	Report string `json:"report"`
+	// StartDate and EndDate are the first and last day covered, in the report's time zone
+	StartDate *Date `json:"start_date"`
+	EndDate   *Date `json:"end_date"`
+	// Attributes are what rows are grouped by, such as day or site_uid, Metrics what's counted for each group
+	Attributes []string `json:"attributes,omitempty"`
+	Metrics    []string `json:"metrics,omitempty"`
+	// Filters keep the rows whose attribute has one of the values, account_uid for instance
+	Filters map[string][]string `json:"filters,omitempty"`
+	// TimeZone is an IANA name, the instance's time zone when empty
+	TimeZone string `json:"timezone,omitempty"`
+}
+
+// Validate checks the request before it's sent
+func (r *ReportRequest) Validate() error {
+	var errs ValidationErrors
+	errs.required("report", r.Report == "")
+	errs.required("start_date", r.StartDate == nil)
+	errs.required("end_date", r.EndDate == nil)
+	errs.dateOrder("start_date", r.StartDate, "end_date", r.EndDate)
+	errs.required("metrics", len(r.Metrics) == 0)
+	return errs.err()
+}
+
+// ReportResult is a report's table, every value is in its text form
+type ReportResult struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// reportResponse is a report as OX3 returns it, values are strings, numbers or null
+type reportResponse struct {
+	Columns []string            `json:"columns"`
+	Rows    [][]json.RawMessage `json:"rows"`
+}
+
+// ReportService runs OX3 reports
+type ReportService struct {
+	api ObjectAPI
+}
+
+// NewReportService returns a report service sending requests through api
+func NewReportService(api ObjectAPI) *ReportService {
+	return &ReportService{api: api}
+}
+
+// Reports returns the report service
+func (c *Client) Reports() *ReportService {
+	return NewReportService(c)
+}
+
+// Run runs the report and returns its rows
+func (s *ReportService) Run(ctx context.Context, req *ReportRequest) (*ReportResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	v, err := verbsOf(s.api, "run reports")
+	if err != nil {
+		return nil, err
+	}
+	body, err := encodeBody(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := v.PostContext(ctx, reportEndpoint, body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't run report %s", req.Report)
+	}
+	raw, err := readResponse(res)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't run report %s", req.Report)
+	}
+	var report reportResponse
+	if err := json.Unmarshal(raw, &report); err != nil {
+		return nil, errors.Wrapf(err, "Couldn't decode report %s", req.Report)
+	}
+
+	result := &ReportResult{Columns: report.Columns, Rows: make([][]string, len(report.Rows))}
+	for i, row := range report.Rows {
+		if len(row) != len(report.Columns) {
+			return nil, errors.Errorf("Couldn't decode report %s: row %d has %d values for %d columns", req.Report, i, len(row), len(report.Columns))
+		}
+		result.Rows[i] = make([]string, len(row))
+		for j, value := range row {
+			if result.Rows[i][j], err = reportValue(value); err != nil {
+				return nil, errors.Wrapf(err, "Couldn't decode report %s: row %d, column %s", req.Report, i, report.Columns[j])
+			}
+		}
+	}
+	return result, nil
+}
+
+// reportValue is the text of a report value, null is empty
+func reportValue(raw json.RawMessage) (string, error) {
+	raw = bytes.TrimSpace(raw)
+	switch {
+	case bytes.Equal(raw, []byte("null")):
+		return "", nil
+	case len(raw) > 0 && raw[0] == '"':
+		var s string
+		err := json.Unmarshal(raw, &s)
+		return s, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return "", err
+	}
+	if _, ok := v.(float64); !ok {
+		return "", errors.Errorf("%s isn't a string or a number", raw)
+	}
+	// numbers are kept as written so big counts and money don't go through a float
+	return string(raw), nil
+}
@@ -0,0 +1,66 @@
+package openx
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestReportRun the request should be posted and every value read back as text
+func TestReportRun(t *testing.T) {
+	var posted map[string]interface{}
+	c := newSessionClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || !strings.HasSuffix(r.URL.Path, "/report/run") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		raw, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(raw, &posted)
+		w.Write([]byte(`{"columns": ["day", "site_uid", "impressions", "revenue"],
+			"rows": [["2024-06-01", "site-1", 12000000000, 1234.5600], ["2024-06-01", null, 0, "0.00"]]}`))
+	})
+
+	day := NewDate(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	result, err := c.Reports().Run(context.Background(), &ReportRequest{
+		Report:     "inv_rev",
+		StartDate:  day,
+		EndDate:    day,
+		Attributes: []string{"day", "site_uid"},
+		Metrics:    []string{"impressions", "revenue"},
+		Filters:    map[string][]string{"account_uid": {"acct-1"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posted["report"] != "inv_rev" || posted["filters"] == nil {
+		t.Errorf("posted %v", posted)
+	}
+	want := [][]string{{"2024-06-01", "site-1", "12000000000", "1234.5600"}, {"2024-06-01", "", "0", "0.00"}}
+	if !reflect.DeepEqual(result.Rows, want) {
+		t.Errorf("got rows %v, want %v", result.Rows, want)
+	}
+}
+
+// TestReportRunErrors incomplete requests and malformed reports should be errors
+func TestReportRunErrors(t *testing.T) {
+	c := newSessionClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"columns": ["day", "impressions"], "rows": [["2024-06-01"]]}`))
+	})
+	reports := c.Reports()
+	ctx := context.Background()
+
+	_, err := reports.Run(ctx, &ReportRequest{Report: "inv_rev"})
+	errs, ok := err.(ValidationErrors)
+	if !ok || strings.Join(errs.Fields(), ",") != "start_date,end_date,metrics" {
+		t.Errorf("got %v, want validation errors", err)
+	}
+	day := NewDate(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	if _, err := reports.Run(ctx, &ReportRequest{Report: "inv_rev", StartDate: day, EndDate: day, Metrics: []string{"impressions"}}); err == nil {
+		t.Error("expected a short row to fail")
+	}
+}
@@ -0,0 +1,182 @@
+package openx
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// validator is a model that can check itself, every generated model is one
+type validator interface {
+	Validate() error
+}
+
+// ruleValidator is a model with checks beyond what the metadata describes,
+// the generated Validate runs them after the required and enum checks
+type ruleValidator interface {
+	validateRules(errs *ValidationErrors)
+}
+
+// targetingOperators are the comparisons a targeting criterion can use
+var targetingOperators = []string{"==", "!=", "INTERSECTS", "NOT INTERSECTS", "BEGINS WITH", "CONTAINS", "NOT CONTAINS"}
+
+func (m *Account) validateRules(errs *ValidationErrors) {
+	errs.currency("currency", m.Currency)
+}
+
+func (m *Order) validateRules(errs *ValidationErrors) {
+	errs.dateOrder("start_date", m.StartDate, "end_date", m.EndDate)
+	errs.amount("budget", m.Budget)
+	errs.currency("currency", m.Currency)
+	errs.priced("budget", m.Budget, "currency", m.Currency)
+}
+
+func (m *LineItem) validateRules(errs *ValidationErrors) {
+	errs.dateOrder("start_date", m.StartDate, "end_date", m.EndDate)
+	errs.amount("price", m.Price)
+	errs.amount("budget", m.Budget)
+	errs.currency("currency", m.Currency)
+	errs.priced("budget", m.Budget, "currency", m.Currency)
+	errs.priced("price", m.Price, "currency", m.Currency)
+	if m.PricingModel != "" && m.Price == "" {
+		errs.addWith("price", "pricing_model", RuleAmount, "is required with pricing_model %q", m.PricingModel)
+	}
+	errs.targeting("targeting", m.Targeting)
+}
+
+func (m *Deal) validateRules(errs *ValidationErrors) {
+	errs.dateOrder("start_date", m.StartDate, "end_date", m.EndDate)
+	errs.amount("floor_price", m.FloorPrice)
+	errs.currency("currency", m.Currency)
+	errs.priced("floor_price", m.FloorPrice, "currency", m.Currency)
+	if m.Type == DealTypeProgrammaticGuaranteed && m.FloorPrice == "" {
+		errs.addWith("floor_price", "type", RuleAmount, "is required for %s deals", m.Type)
+	}
+	for i, seat := range m.BuyerSeatIDs {
+		if strings.TrimSpace(seat) == "" {
+			errs.add("buyer_seat_ids["+strconv.Itoa(i)+"]", RuleRequired, "is empty")
+		}
+	}
+}
+
+func (m *ConversionTag) validateRules(errs *ValidationErrors) {
+	errs.amount("value", m.Value)
+	errs.currency("currency", m.Currency)
+	errs.priced("value", m.Value, "currency", m.Currency)
+	if m.ClickWindowDays < 0 {
+		errs.add("click_window_days", RuleAmount, "can't be negative")
+	}
+	if m.ViewWindowDays < 0 {
+		errs.add("view_window_days", RuleAmount, "can't be negative")
+	}
+}
+
+func (m *Creative) validateRules(errs *ValidationErrors) {
+	if m.Width < 0 {
+		errs.add("width", RuleAmount, "can't be negative")
+	}
+	if m.Height < 0 {
+		errs.add("height", RuleAmount, "can't be negative")
+	}
+}
+
+// dateOrder checks that end doesn't come before start, unset dates are fine
+func (e *ValidationErrors) dateOrder(startField string, start *Date, endField string, end *Date) {
+	if start != nil && end != nil && !start.IsZero() && !end.IsZero() && end.Before(start.Time) {
+		e.addWith(endField, startField, RuleDateOrder, "%s is before %s %s", FormatDate(end.Time), startField, FormatDate(start.Time))
+	}
+}
+
+// amount checks that a decimal isn't negative
+func (e *ValidationErrors) amount(field string, d Decimal) {
+	v, err := d.Float()
+	switch {
+	case err != nil:
+		e.add(field, RuleAmount, "%q isn't a number", d)
+	case v < 0:
+		e.add(field, RuleAmount, "can't be negative")
+	}
+}
+
+// currency checks that a currency is an ISO 4217 code such as USD
+func (e *ValidationErrors) currency(field, code string) {
+	if code == "" {
+		return
+	}
+	if len(code) != 3 || strings.ToUpper(code) != code || strings.IndexFunc(code, func(r rune) bool { return r < 'A' || r > 'Z' }) >= 0 {
+		e.add(field, RuleCurrency, "%q isn't a three letter currency code", code)
+	}
+}
+
+// priced checks that an amount of money says which currency it's in
+func (e *ValidationErrors) priced(amountField string, amount Decimal, currencyField, currency string) {
+	if amount != "" && currency == "" {
+		e.addWith(currencyField, amountField, RuleCurrency, "is required when %s is set", amountField)
+	}
+}
+
+// targeting checks the shape of a targeting object: every dimension is a criterion
+// or a list of them, a criterion being an object with an op and a non empty val
+//
+//	{"geo": [{"op": "INTERSECTS", "val": ["US", "CA"]}], "os": {"op": "==", "val": "ios"}}
+func (e *ValidationErrors) targeting(field string, targeting map[string]interface{}) {
+	for _, dim := range sortedFields(targeting) {
+		path := field + "." + dim
+		switch v := targeting[dim].(type) {
+		case []interface{}:
+			if len(v) == 0 {
+				e.add(path, RuleTargeting, "has no criteria")
+			}
+			for i, c := range v {
+				e.criterion(path+"["+strconv.Itoa(i)+"]", c)
+			}
+		default:
+			e.criterion(path, v)
+		}
+	}
+}
+
+func (e *ValidationErrors) criterion(path string, v interface{}) {
+	c, ok := v.(map[string]interface{})
+	if !ok {
+		e.add(path, RuleTargeting, "is %s, want an object with op and val", describe(v))
+		return
+	}
+	op, _ := c["op"].(string)
+	valid := false
+	for _, o := range targetingOperators {
+		valid = valid || o == op
+	}
+	if !valid {
+		e.add(path+".op", RuleTargeting, "is %s, want one of %s", describe(c["op"]), strings.Join(targetingOperators, ", "))
+	}
+	switch val := c["val"].(type) {
+	case nil:
+		e.add(path+".val", RuleTargeting, "is required")
+	case string:
+		if val == "" {
+			e.add(path+".val", RuleTargeting, "is empty")
+		}
+	case []interface{}:
+		if len(val) == 0 {
+			e.add(path+".val", RuleTargeting, "is empty")
+		}
+	}
+}
+
+func describe(v interface{}) string {
+	if v == nil {
+		return "missing"
+	}
+	return fmt.Sprintf("%#v", v)
+}
+
+func sortedFields(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,227 @@
+package scheduler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/pkg/errors"
+	"github.com/robfig/cron"
+	"gopkg.in/yaml.v2"
+)
+
+// Report periods, the days a run covers relative to the day it runs on
+const (
+	Today       = "today"
+	Yesterday   = "yesterday"
+	Last7Days   = "last_7_days"
+	Last30Days  = "last_30_days"
+	MonthToDate = "month_to_date"
+	LastMonth   = "last_month"
+)
+
+var periods = []string{Today, Yesterday, Last7Days, Last30Days, MonthToDate, LastMonth}
+
+// defaultOutput is where a definition's file goes when it doesn't say
+const defaultOutput = "{name}/{start}_{end}.{format}"
+
+// Definition is a report to run on a schedule
+type Definition struct {
+	Name string `yaml:"name"`
+	// Schedule is a five field cron expression or a descriptor such as @daily
+	Schedule string `yaml:"schedule"`
+	// TimeZone is an IANA name the schedule and the period are in, UTC when empty
+	TimeZone string `yaml:"timezone"`
+
+	Report     string              `yaml:"report"`
+	Period     string              `yaml:"period"`
+	Attributes []string            `yaml:"attributes"`
+	Metrics    []string            `yaml:"metrics"`
+	Filters    map[string][]string `yaml:"filters"`
+
+	// Format is csv, json or parquet
+	Format string `yaml:"format"`
+	// Output is the file name given to the sink, {name}, {format}, {start}, {end} and {run} are filled in,
+	// {run} being the time of the run. {name}/{start}_{end}.{format} when empty
+	Output string `yaml:"output"`
+
+	location *time.Location
+	schedule cron.Schedule
+}
+
+// Load reads the definitions of a YAML file, or of every .yaml and .yml file of a directory
+func Load(path string) ([]*Definition, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't read the definitions: %s", path)
+	}
+	files := []string{path}
+	if info.IsDir() {
+		files = nil
+		for _, pattern := range []string{"*.yaml", "*.yml"} {
+			matches, err := filepath.Glob(filepath.Join(path, pattern))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, matches...)
+		}
+		sort.Strings(files)
+	}
+
+	var defs []*Definition
+	names := map[string]string{}
+	for _, file := range files {
+		raw, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Couldn't read the file: %s", file)
+		}
+		parsed, err := Parse(raw)
+		if err != nil {
+			return nil, errors.Wrap(err, file)
+		}
+		for _, def := range parsed {
+			if other, ok := names[def.Name]; ok {
+				return nil, fmt.Errorf("%s: report %q is already defined in %s", file, def.Name, other)
+			}
+			names[def.Name] = file
+		}
+		defs = append(defs, parsed...)
+	}
+	return defs, nil
+}
+
+// Parse decodes and validates the definitions of a YAML stream, one per document
+func Parse(raw []byte) ([]*Definition, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+	dec.SetStrict(true)
+	var defs []*Definition
+	names := map[string]bool{}
+	for {
+		def := &Definition{}
+		err := dec.Decode(def)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "Couldn't parse the report definitions")
+		}
+		if err := def.init(); err != nil {
+			return nil, err
+		}
+		if names[def.Name] {
+			return nil, fmt.Errorf("report %q is defined twice", def.Name)
+		}
+		names[def.Name] = true
+		defs = append(defs, def)
+	}
+	return defs, nil
+}
+
+// init validates the definition, fills in its defaults and parses its schedule
+func (d *Definition) init() error {
+	if strings.TrimSpace(d.Name) == "" {
+		return errors.New("every report definition needs a name")
+	}
+	if d.Report == "" {
+		return fmt.Errorf("report %q doesn't say which report to run", d.Name)
+	}
+	if len(d.Metrics) == 0 {
+		return fmt.Errorf("report %q has no metrics", d.Name)
+	}
+	if d.Period == "" {
+		d.Period = Yesterday
+	}
+	if !contains(periods, d.Period) {
+		return fmt.Errorf("report %q has period %q, want one of %s", d.Name, d.Period, strings.Join(periods, ", "))
+	}
+	d.Format = strings.ToLower(d.Format)
+	if d.Format == "" {
+		d.Format = FormatCSV
+	}
+	if _, ok := encoder(d.Format); !ok {
+		return fmt.Errorf("report %q has format %q, want one of %s", d.Name, d.Format, strings.Join(Formats(), ", "))
+	}
+	if d.Output == "" {
+		d.Output = defaultOutput
+	}
+
+	var err error
+	d.location = time.UTC
+	if d.TimeZone != "" {
+		if d.location, err = time.LoadLocation(d.TimeZone); err != nil {
+			return errors.Wrapf(err, "report %q has an unknown time zone", d.Name)
+		}
+	}
+	if d.schedule, err = cron.ParseStandard(d.Schedule); err != nil {
+		return errors.Wrapf(err, "report %q has a bad schedule %q", d.Name, d.Schedule)
+	}
+	return nil
+}
+
+// Next is the first time the definition is due after t
+func (d *Definition) Next(t time.Time) time.Time {
+	return d.schedule.Next(t.In(d.location))
+}
+
+// Dates are the first and last day covered by a run at t
+func (d *Definition) Dates(t time.Time) (start, end time.Time) {
+	t = t.In(d.location)
+	today := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, d.location)
+	switch d.Period {
+	case Today:
+		return today, today
+	case Last7Days:
+		return today.AddDate(0, 0, -7), today.AddDate(0, 0, -1)
+	case Last30Days:
+		return today.AddDate(0, 0, -30), today.AddDate(0, 0, -1)
+	case MonthToDate:
+		return today.AddDate(0, 0, 1-today.Day()), today
+	case LastMonth:
+		first := today.AddDate(0, 0, 1-today.Day())
+		return first.AddDate(0, -1, 0), first.AddDate(0, 0, -1)
+	}
+	yesterday := today.AddDate(0, 0, -1)
+	return yesterday, yesterday
+}
+
+// Request is the report request of a run at t, in the time zone its dates were worked out in
+func (d *Definition) Request(t time.Time) *openx.ReportRequest {
+	start, end := d.Dates(t)
+	return &openx.ReportRequest{
+		Report:     d.Report,
+		StartDate:  openx.NewDate(start),
+		EndDate:    openx.NewDate(end),
+		Attributes: d.Attributes,
+		Metrics:    d.Metrics,
+		Filters:    d.Filters,
+		TimeZone:   d.location.String(),
+	}
+}
+
+// OutputName is the name of the file written by a run at t
+func (d *Definition) OutputName(t time.Time) string {
+	start, end := d.Dates(t)
+	return strings.NewReplacer(
+		"{name}", d.Name,
+		"{format}", d.Format,
+		"{start}", start.Format("2006-01-02"),
+		"{end}", end.Format("2006-01-02"),
+		"{run}", t.In(d.location).Format("20060102T150405"),
+	).Replace(d.Output)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
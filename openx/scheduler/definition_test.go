@@ -0,0 +1,131 @@
+package scheduler
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testDefinitions = `
+name: daily-revenue
+schedule: "0 6 * * *"
+timezone: America/New_York
+report: inv_rev
+attributes: [day, site_uid]
+metrics: [impressions, revenue]
+filters:
+  account_uid: [acct-1]
+---
+name: monthly
+schedule: "@monthly"
+report: inv_rev
+period: last_month
+metrics: [revenue]
+format: Parquet
+output: finance/{name}-{start}-{run}.{format}
+`
+
+// TestParseDefinitions definitions should get their defaults and resolve their dates at run time
+func TestParseDefinitions(t *testing.T) {
+	defs, err := Parse([]byte(testDefinitions))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(defs) != 2 {
+		t.Fatalf("got %d definitions, want 2", len(defs))
+	}
+	daily, monthly := defs[0], defs[1]
+	if daily.Period != Yesterday || daily.Format != FormatCSV || monthly.Format != FormatParquet {
+		t.Errorf("got defaults %s %s %s", daily.Period, daily.Format, monthly.Format)
+	}
+
+	// 03:00 UTC is still the 14th in New York, the next 6:00 there is 10:00 UTC on the 15th
+	at := time.Date(2024, 3, 15, 3, 0, 0, 0, time.UTC)
+	if got := daily.OutputName(at); got != "daily-revenue/2024-03-13_2024-03-13.csv" {
+		t.Errorf("got output %s", got)
+	}
+	if next := daily.Next(at); !next.Equal(time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("got next run %s", next.UTC())
+	}
+	req := daily.Request(at)
+	if req.Report != "inv_rev" || req.TimeZone != "America/New_York" || req.Filters["account_uid"][0] != "acct-1" {
+		t.Errorf("got request %+v", req)
+	}
+	if got := monthly.OutputName(at); got != "finance/monthly-2024-02-01-20240315T030000.parquet" {
+		t.Errorf("got output %s", got)
+	}
+	if req := monthly.Request(at); req.TimeZone != "UTC" {
+		t.Errorf("got time zone %q, want the UTC the dates were worked out in", req.TimeZone)
+	}
+	if start, end := monthly.Dates(at); end.Format("2006-01-02") != "2024-02-29" || start.Day() != 1 {
+		t.Errorf("got last month %s to %s", start, end)
+	}
+}
+
+// TestPeriods every period should cover the days it says
+func TestPeriods(t *testing.T) {
+	at := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	tests := map[string]string{
+		Today:       "2024-03-15 2024-03-15",
+		Yesterday:   "2024-03-14 2024-03-14",
+		Last7Days:   "2024-03-08 2024-03-14",
+		Last30Days:  "2024-02-14 2024-03-14",
+		MonthToDate: "2024-03-01 2024-03-15",
+		LastMonth:   "2024-02-01 2024-02-29",
+	}
+	for period, want := range tests {
+		d := &Definition{Period: period, location: time.UTC}
+		start, end := d.Dates(at)
+		if got := start.Format("2006-01-02") + " " + end.Format("2006-01-02"); got != want {
+			t.Errorf("%s: got %s, want %s", period, got, want)
+		}
+	}
+}
+
+// TestParseDefinitionErrors bad definitions should be refused with the report named
+func TestParseDefinitionErrors(t *testing.T) {
+	base := "name: r\nschedule: \"0 6 * * *\"\nreport: inv_rev\nmetrics: [revenue]\n"
+	tests := map[string]string{
+		"no name":     "schedule: \"@daily\"\nreport: inv_rev\nmetrics: [revenue]\n",
+		"no metrics":  "name: r\nschedule: \"@daily\"\nreport: inv_rev\n",
+		"schedule":    strings.Replace(base, "0 6 * * *", "every morning", 1),
+		"period":      base + "period: fortnight\n",
+		"format":      base + "format: xlsx\n",
+		"time zone":   base + "timezone: Mars/Olympus\n",
+		"unknown key": base + "recipients: [finance@example.com]\n",
+		"twice":       base + "---\n" + base,
+	}
+	for name, raw := range tests {
+		if _, err := Parse([]byte(raw)); err == nil {
+			t.Errorf("%s: got no error", name)
+		}
+	}
+}
+
+// TestLoadDirectory every YAML file of a directory should be read, names can't repeat across files
+func TestLoadDirectory(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.yaml", "name: a\nschedule: \"@daily\"\nreport: inv_rev\nmetrics: [revenue]\n")
+	write("b.yml", "name: b\nschedule: \"@hourly\"\nreport: inv_rev\nmetrics: [revenue]\n")
+	write("notes.txt", "not a definition")
+
+	defs, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(defs) != 2 || defs[0].Name != "a" || defs[1].Name != "b" {
+		t.Errorf("got %d definitions", len(defs))
+	}
+
+	write("c.yaml", "name: a\nschedule: \"@daily\"\nreport: inv_rev\nmetrics: [revenue]\n")
+	if _, err := Load(dir); err == nil {
+		t.Error("expected a name defined in two files to fail")
+	}
+}
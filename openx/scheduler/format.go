@@ -0,0 +1,101 @@
+package scheduler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/pkg/errors"
+)
+
+// Output formats
+const (
+	FormatCSV     = "csv"
+	FormatJSON    = "json"
+	FormatParquet = "parquet"
+)
+
+// Encoder writes a report's table to w
+type Encoder func(w io.Writer, report *openx.ReportResult) error
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]Encoder{
+		FormatCSV:     encodeCSV,
+		FormatJSON:    encodeJSON,
+		FormatParquet: encodeParquet,
+	}
+)
+
+// RegisterFormat makes a format usable in definitions, replacing the encoder of a format with the same name.
+// Formats have to be registered before the definitions using them are parsed
+func RegisterFormat(name string, enc Encoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	encoders[strings.ToLower(name)] = enc
+}
+
+// Formats lists the formats definitions can use
+func Formats() []string {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	names := make([]string, 0, len(encoders))
+	for name := range encoders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func encoder(format string) (Encoder, bool) {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	enc, ok := encoders[format]
+	return enc, ok
+}
+
+// checkRows makes sure every row has a value per column, a Reporter is free to return anything
+func checkRows(format string, report *openx.ReportResult) error {
+	for i, row := range report.Rows {
+		if len(row) != len(report.Columns) {
+			return errors.Errorf("Couldn't write %s: row %d has %d values for %d columns", format, i, len(row), len(report.Columns))
+		}
+	}
+	return nil
+}
+
+// encodeCSV writes a header line of the column names and a line per row
+func encodeCSV(w io.Writer, report *openx.ReportResult) error {
+	if err := checkRows("CSV", report); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(report.Columns); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(report.Rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// encodeJSON writes an array with an object per row, keyed by column name
+func encodeJSON(w io.Writer, report *openx.ReportResult) error {
+	if err := checkRows("JSON", report); err != nil {
+		return err
+	}
+	rows := make([]map[string]string, len(report.Rows))
+	for i, row := range report.Rows {
+		rows[i] = make(map[string]string, len(row))
+		for j, value := range row {
+			rows[i][report.Columns[j]] = value
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rows)
+}
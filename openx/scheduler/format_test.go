@@ -0,0 +1,196 @@
+package scheduler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+)
+
+var testReport = &openx.ReportResult{
+	Columns: []string{"day", "site_uid", "revenue"},
+	Rows: [][]string{
+		{"2024-06-01", "site-1", "1234.56"},
+		{"2024-06-01", "site, \"two\"", ""},
+	},
+}
+
+// TestEncodeCSV the header should come first and values should be quoted when needed
+func TestEncodeCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := encodeCSV(&buf, testReport); err != nil {
+		t.Fatal(err)
+	}
+	want := "day,site_uid,revenue\n2024-06-01,site-1,1234.56\n2024-06-01,\"site, \"\"two\"\"\",\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+// TestEncodeJSON every row should be an object keyed by column
+func TestEncodeJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := encodeJSON(&buf, testReport); err != nil {
+		t.Fatal(err)
+	}
+	var rows []map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &rows); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[1]["site_uid"] != `site, "two"` || rows[0]["revenue"] != "1234.56" {
+		t.Errorf("got rows %v", rows)
+	}
+}
+
+// TestEncodeMismatchedRows rows with more or fewer values than columns should fail rather than panic or misalign
+func TestEncodeMismatchedRows(t *testing.T) {
+	for _, format := range []string{FormatCSV, FormatJSON} {
+		enc, _ := encoder(format)
+		for _, row := range [][]string{{"2024-06-01", "site-1", "1", "extra"}, {"2024-06-01"}} {
+			var buf bytes.Buffer
+			if err := enc(&buf, &openx.ReportResult{Columns: testReport.Columns, Rows: [][]string{row}}); err == nil {
+				t.Errorf("%s: expected an error for a row of %d values", format, len(row))
+			}
+			if buf.Len() != 0 {
+				t.Errorf("%s: wrote %q", format, buf.String())
+			}
+		}
+	}
+}
+
+// TestEncodeParquet the footer should describe the columns and point at pages holding the values
+func TestEncodeParquet(t *testing.T) {
+	var buf bytes.Buffer
+	if err := encodeParquet(&buf, testReport); err != nil {
+		t.Fatal(err)
+	}
+	file := buf.Bytes()
+	if string(file[:4]) != "PAR1" || string(file[len(file)-4:]) != "PAR1" {
+		t.Fatal("the file doesn't start and end with PAR1")
+	}
+	size := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footerStart := len(file) - 8 - size
+	r := &thriftReader{data: file[footerStart : len(file)-8]}
+	meta := r.readStruct()
+	if r.pos != size {
+		t.Fatalf("read %d bytes of a %d byte footer", r.pos, size)
+	}
+
+	if meta[3] != int64(2) {
+		t.Errorf("got %v rows, want 2", meta[3])
+	}
+	var names []string
+	for _, el := range meta[2].([]interface{})[1:] {
+		names = append(names, string(el.(map[int16]interface{})[4].([]byte)))
+	}
+	if !reflect.DeepEqual(names, testReport.Columns) {
+		t.Errorf("got columns %v", names)
+	}
+
+	group := meta[4].([]interface{})[0].(map[int16]interface{})
+	for col, chunk := range group[1].([]interface{}) {
+		columnMeta := chunk.(map[int16]interface{})[3].(map[int16]interface{})
+		offset := int(columnMeta[9].(int64))
+		page := &thriftReader{data: file[offset:footerStart]}
+		header := page.readStruct()
+		if n := header[5].(map[int16]interface{})[1]; n != int64(2) {
+			t.Errorf("column %d: got %v values, want 2", col, n)
+		}
+		values := file[offset+page.pos : offset+page.pos+int(header[2].(int64))]
+		var got []string
+		for len(values) > 0 {
+			n := binary.LittleEndian.Uint32(values)
+			got = append(got, string(values[4:4+n]))
+			values = values[4+n:]
+		}
+		for row := range testReport.Rows {
+			if got[row] != testReport.Rows[row][col] {
+				t.Errorf("column %d row %d: got %q, want %q", col, row, got[row], testReport.Rows[row][col])
+			}
+		}
+		if int64(page.pos)+header[2].(int64) != columnMeta[6].(int64) {
+			t.Errorf("column %d: chunk size %v doesn't match its page", col, columnMeta[6])
+		}
+	}
+}
+
+// TestEncodeParquetInvalid reports Parquet can't describe should fail rather than write a broken file
+func TestEncodeParquetInvalid(t *testing.T) {
+	for name, report := range map[string]*openx.ReportResult{
+		"no columns": {},
+		"short row":  {Columns: []string{"day", "revenue"}, Rows: [][]string{{"2024-06-01"}}},
+	} {
+		var buf bytes.Buffer
+		if err := encodeParquet(&buf, report); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%s: wrote %d bytes", name, buf.Len())
+		}
+	}
+}
+
+// thriftReader decodes Thrift compact structs into maps of field id to int64, []byte, []interface{} or map
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) varint() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) readStruct() map[int16]interface{} {
+	fields := map[int16]interface{}{}
+	var last int16
+	for {
+		b := r.data[r.pos]
+		r.pos++
+		if b == 0 {
+			return fields
+		}
+		id := last + int16(b>>4)
+		if b>>4 == 0 {
+			id = int16(r.varint())
+		}
+		last = id
+		fields[id] = r.readValue(b & 0x0f)
+	}
+}
+
+func (r *thriftReader) readValue(typ byte) interface{} {
+	switch typ {
+	case thriftI32, thriftI64:
+		return r.varint()
+	case thriftBinary:
+		n := int(r.uvarint())
+		r.pos += n
+		return r.data[r.pos-n : r.pos]
+	case thriftList:
+		b := r.data[r.pos]
+		r.pos++
+		size := int(b >> 4)
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = r.readValue(b & 0x0f)
+		}
+		return list
+	case thriftStruct:
+		return r.readStruct()
+	}
+	panic(fmt.Sprintf("unexpected thrift type %d", typ))
+}
@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Run is the record of a definition being run
+type Run struct {
+	Report string `json:"report"`
+	// Scheduled is when the run was due, Started and Finished when it actually ran
+	Scheduled time.Time `json:"scheduled"`
+	Started   time.Time `json:"started"`
+	Finished  time.Time `json:"finished"`
+	// Attempts is how many times the report was run, more than one when failures were retried
+	Attempts int `json:"attempts"`
+	// Output is the name the file was written under and Rows the number of rows in it
+	Output string `json:"output,omitempty"`
+	Rows   int    `json:"rows"`
+	// Error is why the last attempt failed, empty for runs that succeeded
+	Error string `json:"error,omitempty"`
+}
+
+// OK reports whether the run wrote its file
+func (r *Run) OK() bool {
+	return r.Error == ""
+}
+
+// History records runs
+type History interface {
+	Record(ctx context.Context, run Run) error
+	// Runs returns the runs of a report, oldest first, every report's when report is empty
+	Runs(ctx context.Context, report string) ([]Run, error)
+}
+
+// MemoryHistory keeps runs in memory, it's lost when the process exits
+type MemoryHistory struct {
+	mu   sync.Mutex
+	runs []Run
+}
+
+// Record adds run to the history
+func (h *MemoryHistory) Record(ctx context.Context, run Run) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.runs = append(h.runs, run)
+	return nil
+}
+
+// Runs returns the runs of report
+func (h *MemoryHistory) Runs(ctx context.Context, report string) ([]Run, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return filterRuns(h.runs, report), nil
+}
+
+// FileHistory appends runs to a file, a JSON object per line
+type FileHistory string
+
+var fileHistoryMu sync.Mutex
+
+// Record appends run to the file, creating it when needed
+func (f FileHistory) Record(ctx context.Context, run Run) error {
+	raw, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	fileHistoryMu.Lock()
+	defer fileHistoryMu.Unlock()
+	file, err := os.OpenFile(string(f), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "Couldn't open the run history: %s", string(f))
+	}
+	if _, err := file.Write(append(raw, '\n')); err != nil {
+		file.Close()
+		return errors.Wrapf(err, "Couldn't record the run in %s", string(f))
+	}
+	return file.Close()
+}
+
+// Runs reads the runs of report from the file, a missing file has none
+func (f FileHistory) Runs(ctx context.Context, report string) ([]Run, error) {
+	file, err := os.Open(string(f))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't open the run history: %s", string(f))
+	}
+	defer file.Close()
+
+	var runs []Run
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 4096), 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var run Run
+		if err := json.Unmarshal(scanner.Bytes(), &run); err != nil {
+			return nil, errors.Wrapf(err, "Couldn't decode line %d of %s", line, string(f))
+		}
+		runs = append(runs, run)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "Couldn't read the run history: %s", string(f))
+	}
+	return filterRuns(runs, report), nil
+}
+
+func filterRuns(runs []Run, report string) []Run {
+	var out []Run
+	for _, run := range runs {
+		if report == "" || run.Report == report {
+			out = append(out, run)
+		}
+	}
+	return out
+}
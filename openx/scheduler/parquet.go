@@ -0,0 +1,221 @@
+package scheduler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/pkg/errors"
+)
+
+// Parquet is written without a library: a single row group holding one uncompressed, PLAIN encoded data page per column.
+// Every column is a required UTF8 string as report values are text, readers can cast numeric columns.
+// The page headers and the footer are Thrift structs in the compact protocol, see
+// https://github.com/apache/parquet-format/blob/master/src/main/thrift/parquet.thrift
+
+const parquetMagic = "PAR1"
+
+// parquet.thrift enum values
+const (
+	parquetByteArray    = 6 // Type BYTE_ARRAY
+	parquetRequired     = 0 // FieldRepetitionType REQUIRED
+	parquetUTF8         = 0 // ConvertedType UTF8
+	parquetPlain        = 0 // Encoding PLAIN
+	parquetRLE          = 3 // Encoding RLE
+	parquetUncompressed = 0 // CompressionCodec UNCOMPRESSED
+	parquetDataPage     = 0 // PageType DATA_PAGE
+)
+
+// Thrift compact protocol field types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// encodeParquet writes the report as a Parquet file
+func encodeParquet(w io.Writer, report *openx.ReportResult) error {
+	// a schema without columns isn't valid Parquet, readers refuse the file
+	if len(report.Columns) == 0 {
+		return errors.New("Couldn't write Parquet: the report has no columns")
+	}
+	if err := checkRows("Parquet", report); err != nil {
+		return err
+	}
+
+	file := &bytes.Buffer{}
+	file.WriteString(parquetMagic)
+
+	type chunk struct {
+		offset, size int64
+	}
+	chunks := make([]chunk, len(report.Columns))
+	for col := range report.Columns {
+		page := &bytes.Buffer{}
+		var length [4]byte
+		for _, row := range report.Rows {
+			binary.LittleEndian.PutUint32(length[:], uint32(len(row[col])))
+			page.Write(length[:])
+			page.WriteString(row[col])
+		}
+
+		header := &thriftWriter{}
+		header.i32(1, parquetDataPage)
+		header.i32(2, int32(page.Len()))
+		header.i32(3, int32(page.Len()))
+		header.beginStruct(5) // DataPageHeader
+		header.i32(1, int32(len(report.Rows)))
+		header.i32(2, parquetPlain)
+		header.i32(3, parquetRLE)
+		header.i32(4, parquetRLE)
+		header.endStruct()
+		header.stop()
+
+		chunks[col] = chunk{offset: int64(file.Len()), size: int64(header.buf.Len() + page.Len())}
+		file.Write(header.buf.Bytes())
+		file.Write(page.Bytes())
+	}
+
+	var total int64
+	for _, c := range chunks {
+		total += c.size
+	}
+	footer := &thriftWriter{}
+	footer.i32(1, 1) // version
+	footer.beginList(2, thriftStruct, len(report.Columns)+1)
+	footer.beginElement() // the root of the schema holds the columns
+	footer.binary(4, "schema")
+	footer.i32(5, int32(len(report.Columns)))
+	footer.endStruct()
+	for _, name := range report.Columns {
+		footer.beginElement()
+		footer.i32(1, parquetByteArray)
+		footer.i32(3, parquetRequired)
+		footer.binary(4, name)
+		footer.i32(6, parquetUTF8)
+		footer.endStruct()
+	}
+	footer.i64(3, int64(len(report.Rows)))
+	footer.beginList(4, thriftStruct, 1)
+	footer.beginElement() // RowGroup
+	footer.beginList(1, thriftStruct, len(report.Columns))
+	for col, name := range report.Columns {
+		footer.beginElement() // ColumnChunk
+		footer.i64(2, chunks[col].offset)
+		footer.beginStruct(3) // ColumnMetaData
+		footer.i32(1, parquetByteArray)
+		footer.beginList(2, thriftI32, 2)
+		footer.element32(parquetPlain)
+		footer.element32(parquetRLE)
+		footer.beginList(3, thriftBinary, 1)
+		footer.elementBinary(name)
+		footer.i32(4, parquetUncompressed)
+		footer.i64(5, int64(len(report.Rows)))
+		footer.i64(6, chunks[col].size)
+		footer.i64(7, chunks[col].size)
+		footer.i64(9, chunks[col].offset)
+		footer.endStruct()
+		footer.endStruct()
+	}
+	footer.i64(2, total)
+	footer.i64(3, int64(len(report.Rows)))
+	footer.endStruct()
+	footer.binary(6, "OX3-Go-API-Client")
+	footer.stop()
+
+	file.Write(footer.buf.Bytes())
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(footer.buf.Len()))
+	file.Write(length[:])
+	file.WriteString(parquetMagic)
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+// thriftWriter writes a struct in the Thrift compact protocol, structs nest by field and as list elements
+type thriftWriter struct {
+	buf bytes.Buffer
+	// last is the id of the last field written, per struct being written
+	last []int16
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	if len(t.last) == 0 {
+		t.last = []int16{0}
+	}
+	last := &t.last[len(t.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(int64(id))
+	}
+	*last = id
+}
+
+// varint writes n zigzag encoded, as i16, i32 and i64 are
+func (t *thriftWriter) varint(n int64) {
+	t.uvarint(uint64(n<<1) ^ uint64(n>>63))
+}
+
+func (t *thriftWriter) uvarint(n uint64) {
+	var b [binary.MaxVarintLen64]byte
+	t.buf.Write(b[:binary.PutUvarint(b[:], n)])
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.elementBinary(s)
+}
+
+func (t *thriftWriter) beginStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.beginElement()
+}
+
+func (t *thriftWriter) beginList(id int16, elem byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elem)
+		return
+	}
+	t.buf.WriteByte(0xf0 | elem)
+	t.uvarint(uint64(size))
+}
+
+// beginElement starts a struct that's an element of a list
+func (t *thriftWriter) beginElement() {
+	t.last = append(t.last, 0)
+}
+
+func (t *thriftWriter) element32(v int32) {
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) elementBinary(s string) {
+	t.uvarint(uint64(len(s)))
+	t.buf.WriteString(s)
+}
+
+func (t *thriftWriter) endStruct() {
+	t.stop()
+	t.last = t.last[:len(t.last)-1]
+}
+
+// stop ends the struct being written, the outermost one has no endStruct
+func (t *thriftWriter) stop() {
+	t.buf.WriteByte(0)
+}
@@ -0,0 +1,233 @@
+// Package scheduler runs OX3 reports on cron schedules and stores their output.
+//
+// Reports are defined in YAML, one per document, and run through the client's report service.
+// Every run writes a CSV, JSON or Parquet file to a Sink and is recorded in a History.
+// A run that fails is tried again with a growing wait before it's recorded as failed
+//
+//	name: daily-revenue
+//	schedule: "0 6 * * *"
+//	timezone: America/New_York
+//	report: inv_rev
+//	period: yesterday
+//	attributes: [day, site_uid]
+//	metrics: [impressions, revenue]
+//	filters:
+//	  account_uid: [6003a3f8-f0e7-fff1-8123-0c6a5b1e2e7f]
+//	format: csv
+//	output: finance/{name}-{start}.csv
+//
+// Run it with
+//
+//	defs, _ := scheduler.Load("reports.yaml")
+//	s, _ := scheduler.New(client.Reports(), defs, scheduler.Options{Sink: scheduler.DirSink("exports")})
+//	s.Run(ctx)
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/pkg/errors"
+	"github.com/robfig/cron"
+)
+
+const (
+	defaultAttempts = 3
+	defaultBackoff  = time.Minute
+)
+
+// Reporter runs reports, *openx.ReportService is one
+type Reporter interface {
+	Run(ctx context.Context, req *openx.ReportRequest) (*openx.ReportResult, error)
+}
+
+// Options configures a Scheduler
+type Options struct {
+	// Sink stores the files, it's required
+	Sink Sink
+	// History records every run, runs are only kept in memory when nil
+	History History
+	// Attempts is how many times a run is tried before it's recorded as failed, 3 when zero
+	Attempts int
+	// Backoff is the wait before a run's second try, it doubles after every try. A minute when zero
+	Backoff time.Duration
+	// Logger is told about runs, nothing is logged when nil
+	Logger *slog.Logger
+}
+
+// Scheduler runs report definitions on their schedules
+type Scheduler struct {
+	reports Reporter
+	defs    map[string]*Definition
+	order   []*Definition
+	opts    Options
+	now     func() time.Time
+	// running keeps a definition from being run twice at once
+	running sync.Map
+}
+
+// New returns a scheduler running defs through reports
+func New(reports Reporter, defs []*Definition, opts Options) (*Scheduler, error) {
+	if opts.Sink == nil {
+		return nil, errors.New("the scheduler needs a Sink to write files to")
+	}
+	if opts.History == nil {
+		opts.History = &MemoryHistory{}
+	}
+	if opts.Attempts <= 0 {
+		opts.Attempts = defaultAttempts
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = defaultBackoff
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.New(slog.DiscardHandler)
+	}
+	s := &Scheduler{reports: reports, defs: map[string]*Definition{}, opts: opts, now: time.Now}
+	for _, def := range defs {
+		if def.schedule == nil {
+			if err := def.init(); err != nil {
+				return nil, err
+			}
+		}
+		if _, ok := s.defs[def.Name]; ok {
+			return nil, errors.Errorf("report %q is defined twice", def.Name)
+		}
+		s.defs[def.Name] = def
+		s.order = append(s.order, def)
+	}
+	return s, nil
+}
+
+// History returns where runs are recorded
+func (s *Scheduler) History() History {
+	return s.opts.History
+}
+
+// Run runs the definitions on their schedules until ctx is done, then waits for the runs in progress
+func (s *Scheduler) Run(ctx context.Context) error {
+	c := cron.New(cron.WithLogger(cronLogger{s.opts.Logger}))
+	for _, def := range s.order {
+		def := def
+		c.Schedule(def, cron.FuncJob(func() {
+			s.RunNow(ctx, def.Name)
+		}))
+		s.opts.Logger.Info("report scheduled", "report", def.Name, "next", def.Next(s.now()))
+	}
+	c.Start()
+	<-ctx.Done()
+	<-c.Stop().Done()
+	return ctx.Err()
+}
+
+// RunNow runs the definition named name once, as if it was due now, and records the run.
+// The error is the run's, it's also in the returned Run
+func (s *Scheduler) RunNow(ctx context.Context, name string) (Run, error) {
+	def, ok := s.defs[name]
+	if !ok {
+		return Run{}, errors.Errorf("Couldn't run report %q: it isn't defined", name)
+	}
+	if _, busy := s.running.LoadOrStore(name, true); busy {
+		s.opts.Logger.Warn("report skipped, the previous run is still going", "report", name)
+		return Run{}, errors.Errorf("Couldn't run report %q: it's already running", name)
+	}
+	defer s.running.Delete(name)
+
+	run := Run{Report: name, Scheduled: s.now()}
+	run.Started = run.Scheduled
+	run.Output = def.OutputName(run.Scheduled)
+	err := s.attempt(ctx, def, &run)
+	run.Finished = s.now()
+	if err != nil {
+		run.Error = err.Error()
+		s.opts.Logger.Error("report failed", "report", name, "attempts", run.Attempts, "error", err)
+	} else {
+		s.opts.Logger.Info("report written", "report", name, "output", run.Output, "rows", run.Rows)
+	}
+	if herr := s.opts.History.Record(ctx, run); herr != nil {
+		s.opts.Logger.Error("couldn't record the run", "report", name, "error", herr)
+		if err == nil {
+			err = herr
+		}
+	}
+	return run, err
+}
+
+// attempt runs the report until it's written, the attempts run out or a failure isn't worth retrying
+func (s *Scheduler) attempt(ctx context.Context, def *Definition, run *Run) error {
+	wait := s.opts.Backoff
+	for {
+		run.Attempts++
+		err := s.write(ctx, def, run)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if run.Attempts >= s.opts.Attempts || !retryable(err) {
+			return err
+		}
+		s.opts.Logger.Warn("report failed, retrying", "report", def.Name, "attempt", run.Attempts, "wait", wait, "error", err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		wait *= 2
+	}
+}
+
+// write runs the report once and hands its file to the sink
+func (s *Scheduler) write(ctx context.Context, def *Definition, run *Run) error {
+	result, err := s.reports.Run(ctx, def.Request(run.Scheduled))
+	if err != nil {
+		return err
+	}
+	enc, ok := encoder(def.Format)
+	if !ok {
+		return errors.Errorf("Couldn't write report %q: format %q isn't registered", def.Name, def.Format)
+	}
+	var buf bytes.Buffer
+	if err := enc(&buf, result); err != nil {
+		return errors.Wrapf(err, "Couldn't encode report %q as %s", def.Name, def.Format)
+	}
+	if err := s.opts.Sink.Write(ctx, run.Output, &buf); err != nil {
+		return err
+	}
+	run.Rows = len(result.Rows)
+	return nil
+}
+
+// retryable reports whether a failed run is worth trying again: not for invalid requests and client errors
+// other than 429, they'd fail the same way
+func retryable(err error) bool {
+	switch e := errors.Cause(err).(type) {
+	case openx.ValidationErrors:
+		return false
+	case *openx.APIError:
+		return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+	}
+	return true
+}
+
+// cronLogger passes cron's own logging on to the scheduler's logger
+type cronLogger struct {
+	logger *slog.Logger
+}
+
+func (l cronLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.logger.Debug(msg, keysAndValues...)
+}
+
+func (l cronLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	l.logger.Error(msg, append(keysAndValues, "error", err)...)
+}
@@ -0,0 +1,148 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+)
+
+// fakeReports answers with testReport after failing the first failures calls with err
+type fakeReports struct {
+	mu       sync.Mutex
+	calls    []*openx.ReportRequest
+	failures int
+	err      error
+}
+
+func (f *fakeReports) Run(ctx context.Context, req *openx.ReportRequest) (*openx.ReportResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, req)
+	if len(f.calls) <= f.failures {
+		return nil, f.err
+	}
+	return testReport, nil
+}
+
+func testScheduler(t *testing.T, reports Reporter, opts Options) *Scheduler {
+	defs, err := Parse([]byte("name: revenue\nschedule: \"@every 10ms\"\nreport: inv_rev\nmetrics: [revenue]\noutput: \"{name}/{start}.{format}\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Backoff == 0 {
+		opts.Backoff = time.Millisecond
+	}
+	s, err := New(reports, defs, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.now = func() time.Time { return time.Date(2024, 6, 2, 6, 0, 0, 0, time.UTC) }
+	return s
+}
+
+// TestRunNow a run should write the file to the sink and be recorded
+func TestRunNow(t *testing.T) {
+	dir := t.TempDir()
+	history := FileHistory(filepath.Join(dir, "history.jsonl"))
+	reports := &fakeReports{}
+	s := testScheduler(t, reports, Options{Sink: DirSink(dir), History: history})
+
+	run, err := s.RunNow(context.Background(), "revenue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Output != "revenue/2024-06-01.csv" || run.Rows != 2 || run.Attempts != 1 || !run.OK() {
+		t.Errorf("got run %+v", run)
+	}
+	raw, err := ioutil.ReadFile(filepath.Join(dir, "revenue", "2024-06-01.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw[:20]) != "day,site_uid,revenue" {
+		t.Errorf("got file %q", raw)
+	}
+	if start := reports.calls[0].StartDate.Format("2006-01-02"); start != "2024-06-01" {
+		t.Errorf("requested %s", start)
+	}
+
+	runs, err := history.Runs(context.Background(), "revenue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].Output != run.Output || runs[0].Rows != 2 {
+		t.Errorf("got history %+v", runs)
+	}
+	if _, err := s.RunNow(context.Background(), "missing"); err == nil {
+		t.Error("expected running an unknown report to fail")
+	}
+}
+
+// TestRunRetries failures should be retried until the attempts run out, client errors shouldn't be
+func TestRunRetries(t *testing.T) {
+	ctx := context.Background()
+	reports := &fakeReports{failures: 2, err: &openx.APIError{StatusCode: http.StatusServiceUnavailable}}
+	s := testScheduler(t, reports, Options{Sink: DirSink(t.TempDir())})
+	run, err := s.RunNow(ctx, "revenue")
+	if err != nil || run.Attempts != 3 {
+		t.Errorf("got run %+v and %v, want success on the third attempt", run, err)
+	}
+
+	reports = &fakeReports{failures: 5, err: errors.New("connection reset")}
+	s = testScheduler(t, reports, Options{Sink: DirSink(t.TempDir()), Attempts: 2})
+	if run, err := s.RunNow(ctx, "revenue"); err == nil || run.Attempts != 2 || run.OK() {
+		t.Errorf("got run %+v, want a failure after 2 attempts", run)
+	}
+
+	reports = &fakeReports{failures: 5, err: &openx.APIError{StatusCode: http.StatusBadRequest}}
+	s = testScheduler(t, reports, Options{Sink: DirSink(t.TempDir())})
+	if run, err := s.RunNow(ctx, "revenue"); err == nil || run.Attempts != 1 {
+		t.Errorf("got run %+v, want a single attempt for a bad request", run)
+	}
+	runs, _ := s.History().Runs(ctx, "")
+	if len(runs) != 1 || runs[0].Error == "" {
+		t.Errorf("got history %+v, want the failure recorded", runs)
+	}
+}
+
+// TestRunSchedule reports should run on their schedule until the context is done
+func TestRunSchedule(t *testing.T) {
+	written := make(chan string, 10)
+	sink := SinkFunc(func(ctx context.Context, name string, r io.Reader) error {
+		written <- name
+		return nil
+	})
+	s := testScheduler(t, &fakeReports{}, Options{Sink: sink})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+	select {
+	case name := <-written:
+		if name != "revenue/2024-06-01.csv" {
+			t.Errorf("wrote %s", name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("the report didn't run")
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
+
+// TestDirSinkOutside names can't climb out of the sink's directory
+func TestDirSinkOutside(t *testing.T) {
+	dir := t.TempDir()
+	if err := DirSink(filepath.Join(dir, "exports")).Write(context.Background(), "../escape.csv", strings.NewReader("x")); err == nil {
+		t.Error("expected a name outside the directory to fail")
+	}
+}
@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Sink stores the files runs produce, a bucket uploader for instance
+type Sink interface {
+	// Write stores the file name with the content read from r, replacing a file with the same name
+	Write(ctx context.Context, name string, r io.Reader) error
+}
+
+// SinkFunc makes a function a Sink
+type SinkFunc func(ctx context.Context, name string, r io.Reader) error
+
+// Write calls f
+func (f SinkFunc) Write(ctx context.Context, name string, r io.Reader) error {
+	return f(ctx, name, r)
+}
+
+// DirSink writes files under a local directory, names with slashes go to sub directories
+type DirSink string
+
+// Write writes a temporary file first so a failed run never leaves half a file behind
+func (d DirSink) Write(ctx context.Context, name string, r io.Reader) error {
+	path := filepath.Join(string(d), filepath.FromSlash(name))
+	if rel, err := filepath.Rel(string(d), path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return errors.Errorf("Couldn't write %s: it's outside of %s", name, string(d))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrapf(err, "Couldn't write %s", name)
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return errors.Wrapf(err, "Couldn't write %s", name)
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return errors.Wrapf(err, "Couldn't write %s", name)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return errors.Wrapf(err, "Couldn't write %s", name)
+	}
+	return errors.Wrapf(os.Rename(tmp.Name(), path), "Couldn't write %s", name)
+}
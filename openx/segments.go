@@ -0,0 +1,8 @@
+package openx
+
+import "context"
+
+// ListByAccount returns every audience segment of an account
+func (s *AudienceSegmentService) ListByAccount(ctx context.Context, accountUID string) ([]*AudienceSegment, error) {
+	return s.List(ctx, map[string]interface{}{"account_uid": accountUID})
+}
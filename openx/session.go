@@ -0,0 +1,116 @@
+package openx
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// sessionEndpoint is where OX3 describes and ends the session of the access token
+const sessionEndpoint = "session"
+
+// Session describes who the client is logged in as
+type Session struct {
+	UserUID    string
+	UserID     string
+	Email      string
+	AccountUID string
+	AccountID  string
+	// LoggedIn is when the client finished the SSO handshake
+	LoggedIn time.Time
+	// Expires is when OX3 ends the session, zero when OX3 doesn't say
+	Expires time.Time
+}
+
+// Session asks OX3 about the client's session
+func (c *Client) Session() (*Session, error) {
+	return c.SessionContext(context.Background())
+}
+
+// SessionContext is Session with a context that is carried through to every middleware
+func (c *Client) SessionContext(ctx context.Context) (*Session, error) {
+	raw, err := c.getJSON(ctx, sessionEndpoint, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Couldn't get the session")
+	}
+	obj, err := decodeObject(raw, sessionEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	expires, err := obj.Time("expires")
+	if err != nil {
+		return nil, errors.Wrap(err, "Couldn't read the session expiry")
+	}
+	return &Session{
+		UserUID:    obj.String("user_uid"),
+		UserID:     obj.String("user_id"),
+		Email:      obj.String("email"),
+		AccountUID: obj.String("account_uid"),
+		AccountID:  obj.String("account_id"),
+		LoggedIn:   c.loggedIn,
+		Expires:    expires,
+	}, nil
+}
+
+// Logout ends the session on OX3 and forgets the access token. The client is closed even when OX3
+// can't be reached, every call afterwards returns ErrClientClosed. Logging out twice does nothing
+func (c *Client) Logout(ctx context.Context) error {
+	if !atomic.CompareAndSwapInt32(&c.closed, 0, 1) {
+		return nil
+	}
+	defer c.forgetToken()
+
+	endpoint, err := c.formatURL(sessionEndpoint)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "DELETE", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	res, err := c.session.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "Couldn't end the session")
+	}
+	if _, err := readResponse(res); err != nil {
+		return errors.Wrap(err, "Couldn't end the session")
+	}
+	c.log().Debug("session ended")
+	return nil
+}
+
+// Close is Logout without a context so the client is an io.Closer
+func (c *Client) Close() error {
+	return c.Logout(context.Background())
+}
+
+// LogOff ends the session, it never returns a response
+//
+// Deprecated: use Logout or Close
+func (c *Client) LogOff() (res *http.Response, err error) {
+	return nil, c.Close()
+}
+
+func (c *Client) isClosed() bool {
+	return atomic.LoadInt32(&c.closed) == 1
+}
+
+// forgetToken expires the access token cookie, the jar is safe to change while requests are in flight
+func (c *Client) forgetToken() {
+	if c.session == nil || c.session.Jar == nil {
+		return
+	}
+	base, err := c.cookieURL()
+	if err != nil {
+		return
+	}
+	c.session.Jar.SetCookies(base, []*http.Cookie{{
+		Name:   "openx3_access_token",
+		Path:   "/",
+		Domain: c.domain,
+		MaxAge: -1,
+	}})
+}
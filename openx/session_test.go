@@ -0,0 +1,95 @@
+package openx
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// newSessionClient returns a client logged in to ox.example.com whose requests are answered by handler
+func newSessionClient(t *testing.T, handler http.HandlerFunc) *Client {
+	c := newTestClient(t, http.NotFoundHandler(), WithMiddleware(func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+			return rec.Result(), nil
+		}
+	}))
+	c.domain = "ox.example.com"
+	jar, _ := cookiejar.New(nil)
+	base, _ := c.cookieURL()
+	jar.SetCookies(base, []*http.Cookie{{Name: "openx3_access_token", Value: "token", Path: "/", Domain: c.domain}})
+	c.session.Jar = jar
+	return c
+}
+
+// TestSession should report who the client is logged in as
+func TestSession(t *testing.T) {
+	c := newSessionClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/data/1.0/session" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"user_uid": "u-1", "user_id": 7, "email": "me@example.com", "account_uid": "a-1", "account_id": "3", "expires": "2020-01-02 03:04:05"}`))
+	})
+
+	s, err := c.Session()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.UserUID != "u-1" || s.UserID != "7" || s.Email != "me@example.com" || s.AccountUID != "a-1" || s.AccountID != "3" {
+		t.Errorf("got %+v", s)
+	}
+	if got := FormatDate(s.Expires); got != "2020-01-02 03:04:05" {
+		t.Errorf("got expiry %s", got)
+	}
+}
+
+// TestLogout should end the session on OX3, forget the token and refuse further calls
+func TestLogout(t *testing.T) {
+	var deleted int
+	c := newSessionClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" && r.URL.Path == "/data/1.0/session" {
+			deleted++
+		}
+	})
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Errorf("the session was deleted %d times, want 1", deleted)
+	}
+	base, _ := c.cookieURL()
+	if cookies := c.session.Jar.Cookies(base); len(cookies) != 0 {
+		t.Errorf("the access token is still in the jar: %v", cookies)
+	}
+	if _, err := c.Get("/account", nil); err != ErrClientClosed {
+		t.Errorf("got %v, want ErrClientClosed", err)
+	}
+	if _, err := c.Session(); errors.Cause(err) != ErrClientClosed {
+		t.Errorf("got %v, want ErrClientClosed", err)
+	}
+
+	if err := c.Close(); err != nil || deleted != 1 {
+		t.Errorf("logging out again: got %v and %d deletes", err, deleted)
+	}
+}
+
+// TestLogoutFailure the client should be closed even when OX3 refuses the logout
+func TestLogoutFailure(t *testing.T) {
+	c := newSessionClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	err := c.Close()
+	if _, ok := errors.Cause(err).(*APIError); !ok {
+		t.Fatalf("got %v, want an *APIError", err)
+	}
+	if _, err := c.Get("/account", nil); err != ErrClientClosed {
+		t.Errorf("got %v, want ErrClientClosed", err)
+	}
+}
@@ -0,0 +1,188 @@
+package openx
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Ad tag formats
+const (
+	TagJS     = "js"
+	TagIframe = "iframe"
+	TagVAST   = "vast"
+)
+
+// Delivery paths of each tag format
+const (
+	jsTagPath     = "/w/1.0/ajs"
+	iframeTagPath = "/w/1.0/afr"
+	vastTagPath   = "/v/1.0/av"
+)
+
+// cacheBuster is the placeholder publishers swap for a random number in iframe and VAST tags,
+// the JS tag makes its own
+const cacheBuster = "INSERT_RANDOM_NUMBER_HERE"
+
+// TagOptions configures the tags of ad units
+type TagOptions struct {
+	// DeliveryDomain is the instance's ad serving domain, such as ox-d.example.servedbyopenx.com
+	DeliveryDomain string
+	// Formats are the tags wanted for each unit, when empty it's JS and iframe for web and mobile units
+	// and VAST for video ones
+	Formats []string
+	// CustomVars are passed along with every ad request as c.<name>=<value>, for targeting on page data
+	CustomVars map[string]string
+	// ClickURL is a click tracker, ad clicks go through it before the advertiser's page
+	ClickURL string
+	// Insecure serves the tags over http, they're https otherwise
+	Insecure bool
+}
+
+// Tag is a snippet of one format for an ad unit
+type Tag struct {
+	AdUnitUID  string `json:"adunit_uid"`
+	AdUnitName string `json:"adunit_name"`
+	Format     string `json:"format"`
+	// Code is the HTML to paste on the page, for VAST tags it's the URL the video player loads
+	Code string `json:"code"`
+}
+
+// TagService builds the ad tags publishers put on their pages
+type TagService struct {
+	api ObjectAPI
+}
+
+// NewTagService returns a tag service reading ad units through api
+func NewTagService(api ObjectAPI) *TagService {
+	return &TagService{api: api}
+}
+
+// Tags returns the tag service
+func (c *Client) Tags() *TagService {
+	return NewTagService(c)
+}
+
+// AdUnit returns the tags of the ad unit with uid
+func (s *TagService) AdUnit(ctx context.Context, uid string, opts TagOptions) ([]Tag, error) {
+	unit, err := NewAdUnitService(s.api).Get(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return AdUnitTags(unit, opts)
+}
+
+// Site returns the tags of every ad unit of the site with uid, in the order OX3 lists them
+func (s *TagService) Site(ctx context.Context, uid string, opts TagOptions) ([]Tag, error) {
+	units, err := NewAdUnitService(s.api).List(ctx, map[string]interface{}{"site_uid": uid})
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't list the ad units of site %s", uid)
+	}
+	var tags []Tag
+	for _, unit := range units {
+		unitTags, err := AdUnitTags(unit, opts)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, unitTags...)
+	}
+	return tags, nil
+}
+
+// AdUnitTags generates the tags of unit in opts.Formats, the unit needs its id and, for iframe tags, its primary size
+func AdUnitTags(unit *AdUnit, opts TagOptions) ([]Tag, error) {
+	formats := opts.Formats
+	if len(formats) == 0 {
+		formats = []string{TagJS, TagIframe}
+		if unit.Type == AdUnitTypeVideo {
+			formats = []string{TagVAST}
+		}
+	}
+	tags := make([]Tag, len(formats))
+	for i, format := range formats {
+		code, err := adUnitTag(unit, strings.ToLower(format), opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Couldn't make the %s tag of ad unit %s", format, unit.UID)
+		}
+		tags[i] = Tag{AdUnitUID: unit.UID, AdUnitName: unit.Name, Format: strings.ToLower(format), Code: code}
+	}
+	return tags, nil
+}
+
+func adUnitTag(unit *AdUnit, format string, opts TagOptions) (string, error) {
+	if unit.ID == 0 {
+		return "", errors.New("the ad unit has no id, create it first")
+	}
+	if opts.DeliveryDomain == "" {
+		return "", errors.New("no delivery domain")
+	}
+	video := unit.Type == AdUnitTypeVideo
+	switch {
+	case format == TagVAST && !video:
+		return "", errors.Errorf("VAST tags are for video units, this one is %s", unit.Type)
+	case (format == TagJS || format == TagIframe) && video:
+		return "", errors.Errorf("%s tags are for web and mobile units, this one is video", format)
+	}
+
+	params := url.Values{}
+	params.Set("auid", strconv.FormatInt(int64(unit.ID), 10))
+	for name, value := range opts.CustomVars {
+		if name == "" {
+			return "", errors.New("a custom variable has no name")
+		}
+		params.Set("c."+name, value)
+	}
+	if opts.ClickURL != "" {
+		params.Set("r", opts.ClickURL)
+	}
+	comment := fmt.Sprintf("<!-- OpenX ad unit: %s (%d) -->", html.EscapeString(unit.Name), unit.ID)
+
+	switch format {
+	case TagJS:
+		src := deliveryURL(opts.DeliveryDomain, jsTagPath, opts.Insecure, params)
+		// document.write keeps the ad where the tag is, the random parameter keeps it out of caches
+		return fmt.Sprintf(`%s
+<script type="text/javascript">
+(function() {
+  var src = %s + "&cb=" + Math.floor(Math.random() * 1e9);
+  document.write('<scr' + 'ipt type="text/javascript" src="' + src + '"></scr' + 'ipt>');
+})();
+</script>
+`, comment, jsString(src)), nil
+	case TagIframe:
+		width, height, err := parseSize(unit.PrimarySize)
+		if err != nil {
+			return "", err
+		}
+		src := deliveryURL(opts.DeliveryDomain, iframeTagPath, opts.Insecure, params) + "&cb=" + cacheBuster
+		return fmt.Sprintf(`%s
+<iframe src="%s" width="%d" height="%d" frameborder="0" scrolling="no" marginwidth="0" marginheight="0"></iframe>
+`, comment, html.EscapeString(src), width, height), nil
+	case TagVAST:
+		return deliveryURL(opts.DeliveryDomain, vastTagPath, opts.Insecure, params) + "&cb=" + cacheBuster, nil
+	}
+	return "", errors.Errorf("unknown format %q, want %s, %s or %s", format, TagJS, TagIframe, TagVAST)
+}
+
+// parseSize reads a WIDTHxHEIGHT size such as 300x250
+func parseSize(size string) (width, height int, err error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(size)), "x")
+	if len(parts) == 2 {
+		width, err = strconv.Atoi(parts[0])
+		if err == nil {
+			height, err = strconv.Atoi(parts[1])
+		}
+		if err == nil && width > 0 && height > 0 {
+			return width, height, nil
+		}
+	}
+	if size == "" {
+		return 0, 0, errors.New("the ad unit has no primary size")
+	}
+	return 0, 0, errors.Errorf("primary size %q isn't WIDTHxHEIGHT", size)
+}
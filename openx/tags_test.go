@@ -0,0 +1,108 @@
+package openx
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// unitsAPI is a fakeAPI listing the ad units of a site
+type unitsAPI struct {
+	*fakeAPI
+}
+
+func (u unitsAPI) List(ctx context.Context, objectType string, params map[string]interface{}) ([]Object, error) {
+	var uids []string
+	for uid, obj := range u.objects {
+		if obj["site_uid"] == params["site_uid"] {
+			uids = append(uids, uid)
+		}
+	}
+	sort.Strings(uids)
+	objects := make([]Object, len(uids))
+	for i, uid := range uids {
+		objects[i] = u.objects[uid].Clone()
+	}
+	return objects, nil
+}
+
+func newUnitsAPI() unitsAPI {
+	return unitsAPI{&fakeAPI{objects: map[string]Object{
+		"unit-1": {"uid": "unit-1", "id": "538", "name": "Top <banner>", "site_uid": "site-1", "type": "web", "primary_size": "728x90"},
+		"unit-2": {"uid": "unit-2", "id": "539", "name": "Pre-roll", "site_uid": "site-1", "type": "video"},
+		"unit-3": {"uid": "unit-3", "id": "540", "name": "Other", "site_uid": "site-2", "type": "web"},
+	}}}
+}
+
+// TestAdUnitTags the tags should carry the unit's id, custom vars and click url
+func TestAdUnitTags(t *testing.T) {
+	opts := TagOptions{
+		DeliveryDomain: "ox-d.example.servedbyopenx.com",
+		CustomVars:     map[string]string{"section": "news"},
+		ClickURL:       "https://clicks.example.com/?u=",
+	}
+	tags, err := NewTagService(newUnitsAPI()).AdUnit(context.Background(), "unit-1", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 || tags[0].Format != TagJS || tags[1].Format != TagIframe {
+		t.Fatalf("got tags %+v, want js and iframe", tags)
+	}
+	query := "auid=538&c.section=news&r=https%3A%2F%2Fclicks.example.com%2F%3Fu%3D"
+	for _, want := range []string{
+		`var src = "https://ox-d.example.servedbyopenx.com/w/1.0/ajs?` + query + `" + "&cb="`,
+		`<!-- OpenX ad unit: Top &lt;banner&gt; (538) -->`,
+	} {
+		if !strings.Contains(tags[0].Code, want) {
+			t.Errorf("js tag %q doesn't contain %q", tags[0].Code, want)
+		}
+	}
+	want := `<iframe src="https://ox-d.example.servedbyopenx.com/w/1.0/afr?` + strings.Replace(query, "&", "&amp;", -1) +
+		`&amp;cb=INSERT_RANDOM_NUMBER_HERE" width="728" height="90"`
+	if !strings.Contains(tags[1].Code, want) {
+		t.Errorf("iframe tag %q doesn't contain %q", tags[1].Code, want)
+	}
+}
+
+// TestSiteTags every unit of the site should get its tags, VAST for video units
+func TestSiteTags(t *testing.T) {
+	tags, err := NewTagService(newUnitsAPI()).Site(context.Background(), "site-1", TagOptions{DeliveryDomain: "ox-d.example.com", Insecure: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, tag := range tags {
+		got = append(got, tag.AdUnitUID+" "+tag.Format)
+	}
+	if strings.Join(got, ",") != "unit-1 js,unit-1 iframe,unit-2 vast" {
+		t.Fatalf("got tags %v", got)
+	}
+	if want := "http://ox-d.example.com/v/1.0/av?auid=539&cb=INSERT_RANDOM_NUMBER_HERE"; tags[2].Code != want {
+		t.Errorf("got VAST tag %s, want %s", tags[2].Code, want)
+	}
+}
+
+// TestAdUnitTagErrors tags that can't work should be refused
+func TestAdUnitTagErrors(t *testing.T) {
+	opts := TagOptions{DeliveryDomain: "ox-d.example.com"}
+	web := &AdUnit{UID: "unit-1", ID: 1, Type: AdUnitTypeWeb, PrimarySize: "300x250"}
+	tests := []struct {
+		unit *AdUnit
+		opts TagOptions
+	}{
+		{&AdUnit{Type: AdUnitTypeWeb, PrimarySize: "300x250"}, opts},
+		{web, TagOptions{}},
+		{web, TagOptions{DeliveryDomain: opts.DeliveryDomain, Formats: []string{TagVAST}}},
+		{web, TagOptions{DeliveryDomain: opts.DeliveryDomain, Formats: []string{"amp"}}},
+		{web, TagOptions{DeliveryDomain: opts.DeliveryDomain, CustomVars: map[string]string{"": "x"}}},
+		{&AdUnit{ID: 2, Type: AdUnitTypeVideo}, TagOptions{DeliveryDomain: opts.DeliveryDomain, Formats: []string{TagJS}}},
+		{&AdUnit{ID: 3, Type: AdUnitTypeWeb, PrimarySize: "fluid"}, opts},
+		{&AdUnit{ID: 4, Type: AdUnitTypeWeb}, opts},
+	}
+	for i, tt := range tests {
+		if _, err := AdUnitTags(tt.unit, tt.opts); err == nil {
+			t.Errorf("%d: got no error", i)
+		}
+	}
+}
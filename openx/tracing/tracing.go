@@ -0,0 +1,103 @@
+// Package tracing emits OpenTelemetry spans for OX3 API calls through openx middleware
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/marcsantiago/OX3-Go-API-Client/openx/tracing"
+
+// span attribute keys, the http ones follow the OpenTelemetry semantic conventions
+const (
+	attrMethod   = attribute.Key("http.request.method")
+	attrStatus   = attribute.Key("http.response.status_code")
+	attrHost     = attribute.Key("server.address")
+	attrEndpoint = attribute.Key("ox3.endpoint")
+	attrAttempt  = attribute.Key("ox3.retry.attempt")
+	attrSSOStep  = attribute.Key("ox3.sso.step")
+)
+
+type config struct {
+	provider    trace.TracerProvider
+	propagators propagation.TextMapPropagator
+}
+
+// Option configures Middleware
+type Option func(*config)
+
+// WithTracerProvider sets the provider spans are created from, the global provider is used by default
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.provider = provider
+	}
+}
+
+// WithPropagators sets the propagators used to inject the span context into outgoing headers,
+// the global propagators are used by default
+func WithPropagators(propagators propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagators = propagators
+	}
+}
+
+// Middleware starts a client span for every request the client makes, SSO handshake steps included.
+// The span is a child of whatever span is in the request context, so use the *Context methods
+// on openx.Client to tie OX3 calls to the caller's trace.
+// Add it after openx.Retry to get a span per attempt
+func Middleware(opts ...Option) openx.Middleware {
+	cfg := config{
+		provider:    otel.GetTracerProvider(),
+		propagators: otel.GetTextMapPropagator(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	tracer := cfg.provider.Tracer(instrumentationName)
+
+	return func(next openx.RoundTripFunc) openx.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			endpoint := openx.EndpointTemplate(req.URL)
+			name := req.Method + " " + endpoint
+			attrs := []attribute.KeyValue{
+				attrMethod.String(req.Method),
+				attrHost.String(req.URL.Host),
+				attrEndpoint.String(endpoint),
+				attrAttempt.Int(openx.RetryAttempt(req.Context())),
+			}
+			if step := openx.SSOStep(req); step != "" {
+				name = "OX3 SSO " + step
+				attrs = append(attrs, attrSSOStep.String(step))
+			}
+
+			ctx, span := tracer.Start(req.Context(), name,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attrs...),
+			)
+			defer span.End()
+
+			// round trippers must not mutate the request they are handed
+			r := req.Clone(ctx)
+			cfg.propagators.Inject(ctx, propagation.HeaderCarrier(r.Header))
+
+			res, err := next(r)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return res, err
+			}
+
+			span.SetAttributes(attrStatus.Int(res.StatusCode))
+			if res.StatusCode >= http.StatusBadRequest {
+				span.SetStatus(codes.Error, http.StatusText(res.StatusCode))
+			}
+			return res, nil
+		}
+	}
+}
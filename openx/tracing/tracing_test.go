@@ -0,0 +1,140 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recorder is a minimal TracerProvider that keeps every span it starts
+type recorder struct {
+	embedded.TracerProvider
+	spans []*span
+}
+
+type tracer struct {
+	embedded.Tracer
+	rec *recorder
+}
+
+type span struct {
+	noop.Span
+	name   string
+	parent trace.SpanContext
+	sc     trace.SpanContext
+	attrs  map[attribute.Key]attribute.Value
+	status codes.Code
+	ended  bool
+}
+
+func (r *recorder) Tracer(string, ...trace.TracerOption) trace.Tracer { return &tracer{rec: r} }
+
+func (t *tracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	r := t.rec
+	cfg := trace.NewSpanStartConfig(opts...)
+	s := &span{
+		name:   name,
+		parent: trace.SpanContextFromContext(ctx),
+		sc: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{1},
+			SpanID:     trace.SpanID{byte(len(r.spans) + 2)},
+			TraceFlags: trace.FlagsSampled,
+		}),
+		attrs: map[attribute.Key]attribute.Value{},
+	}
+	s.SetAttributes(cfg.Attributes()...)
+	r.spans = append(r.spans, s)
+	return trace.ContextWithSpan(ctx, s), s
+}
+
+func (s *span) SpanContext() trace.SpanContext { return s.sc }
+func (s *span) IsRecording() bool              { return true }
+func (s *span) End(...trace.SpanEndOption)     { s.ended = true }
+func (s *span) SetStatus(code codes.Code, _ string) {
+	s.status = code
+}
+func (s *span) SetAttributes(kv ...attribute.KeyValue) {
+	for _, a := range kv {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+// TestMiddleware spans should carry the endpoint template, status and attempt and be children of the caller's span
+func TestMiddleware(t *testing.T) {
+	rec := &recorder{}
+	var traceparent string
+	base := func(req *http.Request) (*http.Response, error) {
+		traceparent = req.Header.Get("traceparent")
+		w := httptest.NewRecorder()
+		w.WriteHeader(http.StatusNotFound)
+		return w.Result(), nil
+	}
+	client := &http.Client{Transport: openx.Chain(
+		Middleware(WithTracerProvider(rec), WithPropagators(propagation.TraceContext{})),
+	)(base)}
+
+	parent := trace.NewSpanContext(trace.SpanContextConfig{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{1}})
+	ctx := trace.ContextWithSpanContext(context.Background(), parent)
+	req, _ := http.NewRequestWithContext(ctx, "GET", "http://ox.example.com/data/1.0/lineitem/12345", nil)
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if len(rec.spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(rec.spans))
+	}
+	s := rec.spans[0]
+	if s.name != "GET /lineitem/{id}" {
+		t.Errorf("unexpected span name %q", s.name)
+	}
+	if s.parent.SpanID() != parent.SpanID() {
+		t.Errorf("span isn't a child of the caller's span")
+	}
+	if got := s.attrs[attrEndpoint].AsString(); got != "/lineitem/{id}" {
+		t.Errorf("unexpected endpoint %q", got)
+	}
+	if got := s.attrs[attrStatus].AsInt64(); got != http.StatusNotFound {
+		t.Errorf("unexpected status %d", got)
+	}
+	if got := s.attrs[attrAttempt].AsInt64(); got != 1 {
+		t.Errorf("unexpected attempt %d", got)
+	}
+	if s.status != codes.Error || !s.ended {
+		t.Errorf("expected an ended span with an error status")
+	}
+	if traceparent == "" {
+		t.Errorf("expected the span context to be propagated")
+	}
+}
+
+// TestSSOSpan SSO handshake steps get their own span names
+func TestSSOSpan(t *testing.T) {
+	rec := &recorder{}
+	base := func(req *http.Request) (*http.Response, error) {
+		return httptest.NewRecorder().Result(), nil
+	}
+	client := &http.Client{Transport: openx.Chain(Middleware(WithTracerProvider(rec)))(base)}
+	res, err := client.Post("https://sso.openx.com/api/index/initiate", "application/x-www-form-urlencoded", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if len(rec.spans) != 1 || rec.spans[0].name != "OX3 SSO "+openx.SSORequestToken {
+		t.Fatalf("expected a single request token span, got %+v", rec.spans)
+	}
+	if got := rec.spans[0].attrs[attrSSOStep].AsString(); got != openx.SSORequestToken {
+		t.Errorf("unexpected sso step %q", got)
+	}
+}
@@ -0,0 +1,93 @@
+package openx
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// RoleAssignment is a role a user holds on an account, it covers the account's sub accounts too
+type RoleAssignment struct {
+	AccountUID string `json:"account_uid"`
+	RoleUID    string `json:"role_uid"`
+}
+
+// Deactivate sets the user with uid Inactive so they can't log in anymore, OX3 keeps the user
+func (s *UserService) Deactivate(ctx context.Context, uid string) (*User, error) {
+	obj, err := s.api.Update(ctx, TypeUser, uid, Object{"status": string(StatusInactive)})
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't deactivate user %s", uid)
+	}
+	updated := &User{}
+	return updated, decodeModel(obj, updated)
+}
+
+// Roles lists the roles the user with uid holds, one per account
+func (s *UserService) Roles(ctx context.Context, uid string) ([]RoleAssignment, error) {
+	v, err := verbsOf(s.api, "list roles")
+	if err != nil {
+		return nil, err
+	}
+	res, err := v.GetContext(ctx, userRoleEndpoint(uid), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't get the roles of user %s", uid)
+	}
+	raw, err := readResponse(res)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't get the roles of user %s", uid)
+	}
+	var assignments []RoleAssignment
+	if err := json.Unmarshal(raw, &assignments); err != nil {
+		return nil, errors.Wrapf(err, "Couldn't decode the roles of user %s", uid)
+	}
+	return assignments, nil
+}
+
+// AssignRole gives the user with uid roleUID on an account and the accounts under it,
+// replacing the role they held there
+func (s *UserService) AssignRole(ctx context.Context, uid, accountUID, roleUID string) error {
+	v, err := verbsOf(s.api, "assign roles")
+	if err != nil {
+		return err
+	}
+	body, err := encodeBody(RoleAssignment{AccountUID: accountUID, RoleUID: roleUID})
+	if err != nil {
+		return err
+	}
+	res, err := v.PostContext(ctx, userRoleEndpoint(uid), body)
+	if err == nil {
+		_, err = readResponse(res)
+	}
+	return errors.Wrapf(err, "Couldn't assign role %s on account %s to user %s", roleUID, accountUID, uid)
+}
+
+// RemoveRole takes away the role the user with uid holds on an account
+func (s *UserService) RemoveRole(ctx context.Context, uid, accountUID string) error {
+	v, err := verbsOf(s.api, "remove roles")
+	if err != nil {
+		return err
+	}
+	res, err := v.DeleteContext(ctx, userRoleEndpoint(uid)+"/"+accountUID, nil)
+	if err == nil {
+		_, err = readResponse(res)
+	}
+	return errors.Wrapf(err, "Couldn't remove the role of user %s on account %s", uid, accountUID)
+}
+
+// ResetPassword has OX3 email the user with uid a link to pick a new password
+func (s *UserService) ResetPassword(ctx context.Context, uid string) error {
+	v, err := verbsOf(s.api, "reset passwords")
+	if err != nil {
+		return err
+	}
+	res, err := v.PostContext(ctx, TypeUser+"/"+uid+"/reset_password", nil)
+	if err == nil {
+		_, err = readResponse(res)
+	}
+	return errors.Wrapf(err, "Couldn't reset the password of user %s", uid)
+}
+
+func userRoleEndpoint(uid string) string {
+	return TypeUser + "/" + uid + "/role"
+}
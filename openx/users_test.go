@@ -0,0 +1,88 @@
+package openx
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestUserRoles roles should be assigned, listed and removed through the user's role endpoint
+func TestUserRoles(t *testing.T) {
+	var calls []string
+	var posted RoleAssignment
+	c := newSessionClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+strings.TrimPrefix(r.URL.Path, "/data/1.0"))
+		switch {
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/role"):
+			raw, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(raw, &posted)
+		case r.Method == "GET":
+			w.Write([]byte(`[{"account_uid": "pub", "role_uid": "r-1"}]`))
+		case r.Method == "DELETE" && strings.HasSuffix(r.URL.Path, "/missing"):
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	users := c.Users()
+	ctx := context.Background()
+
+	if err := users.AssignRole(ctx, "u-1", "pub", "r-1"); err != nil {
+		t.Fatal(err)
+	}
+	if posted != (RoleAssignment{AccountUID: "pub", RoleUID: "r-1"}) {
+		t.Errorf("posted %+v", posted)
+	}
+	roles, err := users.Roles(ctx, "u-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roles) != 1 || roles[0].RoleUID != "r-1" {
+		t.Errorf("got roles %+v", roles)
+	}
+	if err := users.RemoveRole(ctx, "u-1", "pub"); err != nil {
+		t.Fatal(err)
+	}
+	if err := users.ResetPassword(ctx, "u-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := users.RemoveRole(ctx, "u-1", "missing"); err == nil {
+		t.Error("expected removing a missing role to fail")
+	}
+
+	want := []string{
+		"POST /user/u-1/role",
+		"GET /user/u-1/role",
+		"DELETE /user/u-1/role/pub",
+		"POST /user/u-1/reset_password",
+		"DELETE /user/u-1/role/missing",
+	}
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got calls\n%s\nwant\n%s", strings.Join(calls, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// TestDeactivateUser should only send the status
+func TestDeactivateUser(t *testing.T) {
+	api := &fakeAPI{objects: map[string]Object{
+		"u-1": {"uid": "u-1", "email": "me@example.com", "account_uid": "pub", "status": "Active"},
+	}}
+	users := NewUserService(api)
+
+	user, err := users.Deactivate(context.Background(), "u-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Status != StatusInactive || user.Email != "me@example.com" {
+		t.Errorf("got %+v", user)
+	}
+	if len(api.updates) != 1 || len(api.updates[0]) != 1 {
+		t.Errorf("sent %v, want only the status", api.updates)
+	}
+
+	// role endpoints need a client
+	if _, err := users.Roles(context.Background(), "u-1"); err == nil {
+		t.Error("expected an error listing roles without a client")
+	}
+}
@@ -0,0 +1,151 @@
+package openx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Validation rules a FieldError can break
+const (
+	RuleRequired  = "required"
+	RuleEnum      = "enum"
+	RuleDateOrder = "date_order"
+	RuleAmount    = "amount"
+	RuleCurrency  = "currency"
+	RuleTargeting = "targeting"
+)
+
+// FieldError is a problem with one field of a model. Field is its JSON path,
+// nested fields joined with dots and list items indexed, targeting.geo[0].op for instance.
+// Companion is the other field a rule across two fields read, budget for a currency required with it
+type FieldError struct {
+	Field     string
+	Companion string
+	Rule      string
+	Message   string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationErrors lists every problem found with a model
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return "invalid object: " + strings.Join(msgs, "; ")
+}
+
+// Fields returns the paths of the invalid fields
+func (e ValidationErrors) Fields() []string {
+	fields := make([]string, len(e))
+	for i, fe := range e {
+		fields[i] = fe.Field
+	}
+	return fields
+}
+
+func (e *ValidationErrors) add(field, rule, format string, args ...interface{}) {
+	e.addWith(field, "", rule, format, args...)
+}
+
+// addWith reports a problem with field found by looking at companion too
+func (e *ValidationErrors) addWith(field, companion, rule, format string, args ...interface{}) {
+	*e = append(*e, FieldError{Field: field, Companion: companion, Rule: rule, Message: fmt.Sprintf(format, args...)})
+}
+
+func (e *ValidationErrors) required(field string, missing bool) {
+	if missing {
+		e.add(field, RuleRequired, "is required")
+	}
+}
+
+func (e *ValidationErrors) oneOf(field, value string, valid bool, allowed []string) {
+	if value != "" && !valid {
+		e.add(field, RuleEnum, "is %q, want one of %s", value, strings.Join(allowed, ", "))
+	}
+}
+
+// err returns nil when nothing was found so callers don't end up with a non-nil empty error
+func (e ValidationErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// WithValidation checks the JSON sent by Post and Put against the typed model of the endpoint's object type,
+// Create and Update included. An invalid object comes back as ValidationErrors and nothing is sent.
+// Puts are updates that only carry the changed fields, a missing required field isn't reported for them
+// and neither are rules across two fields when one of them wasn't sent
+func WithValidation() Option {
+	return func(c *Client) {
+		c.validate = true
+	}
+}
+
+// ValidateObject checks obj against the typed model of objectType, types without one always pass.
+// partial leaves out required fields obj doesn't have, for checking an update, and rules across two fields
+// when obj doesn't have both as the server's value of the other one isn't known
+func ValidateObject(objectType string, obj Object, partial bool) error {
+	model := newModel(objectType)
+	if model == nil {
+		return nil
+	}
+	if err := decodeModel(obj, model); err != nil {
+		return errors.Wrapf(err, "Couldn't check %s", objectType)
+	}
+	err := model.Validate()
+	verrs, ok := err.(ValidationErrors)
+	if !ok || !partial {
+		return err
+	}
+
+	var kept ValidationErrors
+	for _, fe := range verrs {
+		_, sent := obj[fe.Field]
+		if fe.Companion != "" {
+			_, companionSent := obj[fe.Companion]
+			sent = sent && companionSent
+		}
+		if !sent && (fe.Rule == RuleRequired || fe.Companion != "") {
+			continue
+		}
+		kept = append(kept, fe)
+	}
+	return kept.err()
+}
+
+// checkBody validates what's about to be posted or put to endpoint when the client validates,
+// only "<type>" and "<type>/<uid>" endpoints carrying a JSON object are checked
+func (c *Client) checkBody(method, endpoint string, data io.Reader) (io.Reader, error) {
+	if !c.validate || data == nil {
+		return data, nil
+	}
+	parts := strings.Split(strings.Trim(endpoint, "/"), "/")
+	if len(parts) > 2 || newModel(parts[0]) == nil {
+		return data, nil
+	}
+
+	raw, err := ioutil.ReadAll(data)
+	if err != nil {
+		return nil, err
+	}
+	var obj Object
+	if json.Unmarshal(raw, &obj) == nil && obj != nil {
+		if err := ValidateObject(parts[0], obj, method == "PUT"); err != nil {
+			return nil, err
+		}
+	}
+	return bytes.NewReader(raw), nil
+}
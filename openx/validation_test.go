@@ -0,0 +1,135 @@
+package openx
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestValidateLineItem every broken rule should be reported with the path of its field
+func TestValidateLineItem(t *testing.T) {
+	li := &LineItem{
+		Name:         "spring",
+		AccountUID:   "acct",
+		OrderUID:     "order",
+		Type:         LineItemTypeHouse,
+		StartDate:    NewDate(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)),
+		EndDate:      NewDate(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)),
+		PricingModel: PricingModelCPM,
+		Budget:       "-5",
+		Currency:     "usd",
+		Targeting: map[string]interface{}{
+			"geo": []interface{}{map[string]interface{}{"op": "NEAR", "val": []interface{}{"US"}}},
+			"os":  map[string]interface{}{"op": "==", "val": ""},
+			"day": "monday",
+		},
+	}
+	err := li.Validate()
+	verrs, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+	want := []string{"end_date", "budget", "currency", "price", "targeting.day", "targeting.geo[0].op", "targeting.os.val"}
+	if got := verrs.Fields(); !reflect.DeepEqual(got, want) {
+		t.Errorf("invalid fields %v, want %v\n%v", got, want, err)
+	}
+
+	li.EndDate = nil
+	li.Budget = "500"
+	li.Price = "2.5"
+	li.Currency = "USD"
+	li.Targeting = map[string]interface{}{"geo": []interface{}{map[string]interface{}{"op": "INTERSECTS", "val": []interface{}{"US"}}}}
+	if err := li.Validate(); err != nil {
+		t.Errorf("valid line item failed validation: %v", err)
+	}
+}
+
+// TestValidateObject partial checks should skip the required fields that weren't sent and rules reading them
+func TestValidateObject(t *testing.T) {
+	update := Object{"currency": "USD", "budget": "10"}
+	if err := ValidateObject(TypeOrder, update, true); err != nil {
+		t.Errorf("partial update failed validation: %v", err)
+	}
+	if err := ValidateObject(TypeOrder, update, false); err == nil {
+		t.Error("expected missing required fields to be reported for a full object")
+	}
+	err := ValidateObject(TypeOrder, Object{"name": "", "budget": "10"}, true)
+	if verrs, ok := err.(ValidationErrors); !ok || !reflect.DeepEqual(verrs.Fields(), []string{"name"}) {
+		t.Errorf("got %v, want only name reported", err)
+	}
+	for typ, update := range map[string]Object{
+		TypeLineItem: {"budget": "10"},
+		TypeDeal:     {"floor_price": "1.50"},
+	} {
+		if err := ValidateObject(typ, update, true); err != nil {
+			t.Errorf("%s: amount without its currency was refused: %v", typ, err)
+		}
+	}
+	err = ValidateObject(TypeLineItem, Object{"budget": "10", "currency": ""}, true)
+	if verrs, ok := err.(ValidationErrors); !ok || !reflect.DeepEqual(verrs.Fields(), []string{"currency"}) {
+		t.Errorf("got %v, want the currency sent empty reported", err)
+	}
+	if err := ValidateObject("report", Object{"anything": 1}, false); err != nil {
+		t.Errorf("types without a model should pass, got %v", err)
+	}
+}
+
+// TestWithValidation an invalid object shouldn't reach OX3
+func TestWithValidation(t *testing.T) {
+	var requests int
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"uid": "site-1"}`))
+	}), WithValidation())
+
+	ctx := context.Background()
+	_, err := c.Create(ctx, TypeSite, Object{"name": "example.com"})
+	if verrs, ok := err.(ValidationErrors); !ok || verrs[0].Field != "account_uid" || verrs[0].Rule != RuleRequired {
+		t.Errorf("expected account_uid to be required, got %v", err)
+	}
+	if _, err := c.PutContext(ctx, "site/site-1", bytes.NewReader([]byte(`{"status": "Gone"}`))); err == nil {
+		t.Error("expected a bad status to be refused")
+	}
+	if requests != 0 {
+		t.Fatalf("%d invalid requests were sent", requests)
+	}
+
+	if _, err := c.Update(ctx, TypeSite, "site-1", Object{"status": "Inactive"}); err != nil {
+		t.Errorf("valid partial update was refused: %v", err)
+	}
+	if _, err := c.PostContext(ctx, "site/site-1/notes", bytes.NewReader([]byte(`{"text": "hi"}`))); err != nil {
+		t.Errorf("endpoint without a model was refused: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("got %d requests, want the 2 valid ones", requests)
+	}
+}
+
+// TestPatchWithValidation a patch of an amount alone should go through, the line item already has its currency
+func TestPatchWithValidation(t *testing.T) {
+	var sent []string
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			body, _ := ioutil.ReadAll(r.Body)
+			sent = append(sent, string(body))
+		}
+		w.Write([]byte(`{"uid": "li-1", "name": "spring", "budget": "5", "currency": "USD", "modified_date": "2024-03-01 00:00:00"}`))
+	}), WithValidation())
+
+	ctx := context.Background()
+	li, err := c.LineItems().Get(ctx, "li-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	li.Budget = "10"
+	if _, err := c.LineItems().Patch(ctx, li); err != nil {
+		t.Fatalf("budget only patch was refused: %v", err)
+	}
+	if want := []string{`{"budget":"10"}`}; !reflect.DeepEqual(sent, want) {
+		t.Errorf("sent %v, want %v", sent, want)
+	}
+}
@@ -0,0 +1,134 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/pkg/errors"
+)
+
+// Cursor is how far a watcher got, by object type
+type Cursor map[string]*TypeCursor
+
+// TypeCursor is how far a watcher got with one object type
+type TypeCursor struct {
+	// Watermark is the newest modified_date seen, in OX3's date format
+	Watermark string `json:"watermark"`
+	// AtWatermark are the uids already delivered whose modified_date is the watermark,
+	// the next poll returns them again since modified_since includes its second
+	AtWatermark []string `json:"at_watermark,omitempty"`
+	// Known are the uids that exist as far as the watcher knows, only kept when looking for deletes
+	Known []string `json:"known,omitempty"`
+}
+
+func (c Cursor) clone() Cursor {
+	out := make(Cursor, len(c))
+	for k, tc := range c {
+		out[k] = tc.clone()
+	}
+	return out
+}
+
+func (tc *TypeCursor) clone() *TypeCursor {
+	return &TypeCursor{
+		Watermark:   tc.Watermark,
+		AtWatermark: append([]string(nil), tc.AtWatermark...),
+		Known:       append([]string(nil), tc.Known...),
+	}
+}
+
+func (tc *TypeCursor) atWatermark() map[string]bool {
+	return set(tc.AtWatermark)
+}
+
+func (tc *TypeCursor) known() map[string]bool {
+	return set(tc.Known)
+}
+
+// see moves the watermark forward to modified
+func (tc *TypeCursor) see(uid, modified string) {
+	switch {
+	case modified > tc.Watermark:
+		tc.Watermark = modified
+		tc.AtWatermark = []string{uid}
+	case modified == tc.Watermark:
+		for _, u := range tc.AtWatermark {
+			if u == uid {
+				return
+			}
+		}
+		tc.AtWatermark = append(tc.AtWatermark, uid)
+	}
+}
+
+func (tc *TypeCursor) addKnown(objects []openx.Object) {
+	known := tc.known()
+	for _, obj := range objects {
+		if uid := obj.UID(); uid != "" && !known[uid] {
+			known[uid] = true
+			tc.Known = append(tc.Known, uid)
+		}
+	}
+	sort.Strings(tc.Known)
+}
+
+func set(list []string) map[string]bool {
+	out := make(map[string]bool, len(list))
+	for _, v := range list {
+		out[v] = true
+	}
+	return out
+}
+
+// CursorStore keeps a watcher's cursor between runs
+type CursorStore interface {
+	// Load returns the saved cursor, nil when there's none yet
+	Load(ctx context.Context) (Cursor, error)
+	Save(ctx context.Context, cursor Cursor) error
+}
+
+// FileCursor keeps the cursor in a JSON file
+type FileCursor string
+
+// Load reads the file, a missing file is an empty cursor
+func (f FileCursor) Load(ctx context.Context) (Cursor, error) {
+	raw, err := ioutil.ReadFile(string(f))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't read the file: %s", string(f))
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, errors.Wrapf(err, "Couldn't decode %s", string(f))
+	}
+	return cursor, nil
+}
+
+// Save replaces the file, writing a temporary file first so a crash never leaves half a cursor
+func (f FileCursor) Save(ctx context.Context, cursor Cursor) error {
+	raw, err := json.MarshalIndent(cursor, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(string(f)), filepath.Base(string(f))+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), string(f))
+}
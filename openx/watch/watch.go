@@ -0,0 +1,258 @@
+// Package watch polls OX3 for objects that changed and turns them into created, updated and deleted events.
+//
+// Each poll lists every watched type modified since the type's cursor, the newest modified_date seen,
+// so a quiet account costs one small request per type. OX3 can't list what was deleted,
+// deletes are found by listing every object now and again and comparing with the uids known so far.
+//
+// The cursor is saved after the events of a type are delivered, a watcher restarted from it
+// picks up where it left off and may deliver the last events again, never fewer.
+// Requests go through the client so they wait on its rate limit, see openx.WithRateLimit
+package watch
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/pkg/errors"
+)
+
+// EventType is what happened to an object
+type EventType string
+
+// Event types
+const (
+	Created EventType = "created"
+	Updated EventType = "updated"
+	Deleted EventType = "deleted"
+)
+
+// Event is a change to an OX3 object
+type Event struct {
+	Type       EventType
+	ObjectType string
+	UID        string
+	// Object is the object as OX3 returned it, nil for deletes
+	Object openx.Object
+	// Time is the object's modified_date, when the delete was noticed for deletes
+	Time time.Time
+}
+
+// Options controls a Watcher
+type Options struct {
+	// Types are the object types to watch, every type openx.Kinds lists when empty
+	Types []string
+	// Interval is the time between polls, a minute when zero
+	Interval time.Duration
+	// Params are extra List parameters sent for every type, an account_uid filter for instance
+	Params map[string]interface{}
+	// DeleteScanEvery lists every object every that many polls to find deletes, 0 never looks for deletes
+	DeleteScanEvery int
+	// Backfill sends a created event for every existing object on the first poll of a type without a cursor,
+	// otherwise that poll only sets the cursor
+	Backfill bool
+	// Cursor keeps the cursor between runs, it only lives in memory when nil
+	Cursor CursorStore
+	// OnError is told about failed polls, the type is polled again next time.
+	// When nil the first failure stops Run
+	OnError func(objectType string, err error)
+}
+
+// Watcher polls OX3 for changes
+type Watcher struct {
+	api    openx.ObjectAPI
+	opts   Options
+	cursor Cursor
+	polls  int
+	now    func() time.Time
+}
+
+// New returns a Watcher reading through api
+func New(api openx.ObjectAPI, opts Options) *Watcher {
+	if len(opts.Types) == 0 {
+		for _, kind := range openx.Kinds() {
+			opts.Types = append(opts.Types, kind.Type)
+		}
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Minute
+	}
+	return &Watcher{api: api, opts: opts, now: time.Now}
+}
+
+// Run polls until ctx is done, sending events to events in the order they happened per type.
+// It returns ctx's error, or the first failure when Options.OnError is nil
+func (w *Watcher) Run(ctx context.Context, events chan<- Event) error {
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+	for {
+		if err := w.Poll(ctx, events); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll checks every watched type once and sends what changed to events
+func (w *Watcher) Poll(ctx context.Context, events chan<- Event) error {
+	if w.cursor == nil {
+		cursor, err := w.load(ctx)
+		if err != nil {
+			return err
+		}
+		w.cursor = cursor
+	}
+
+	scanDeletes := w.opts.DeleteScanEvery > 0 && w.polls%w.opts.DeleteScanEvery == 0
+	w.polls++
+	for _, objectType := range w.opts.Types {
+		err := w.pollType(ctx, objectType, scanDeletes, events)
+		if err == nil {
+			err = w.save(ctx)
+		}
+		if err == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		err = errors.Wrapf(err, "Couldn't poll %s", objectType)
+		if w.opts.OnError == nil {
+			return err
+		}
+		w.opts.OnError(objectType, err)
+	}
+	return nil
+}
+
+func (w *Watcher) pollType(ctx context.Context, objectType string, scanDeletes bool, events chan<- Event) error {
+	tc, ok := w.cursor[objectType]
+	first := !ok
+	if first {
+		tc = &TypeCursor{}
+	}
+	quiet := first && !w.opts.Backfill
+
+	params := make(map[string]interface{}, len(w.opts.Params)+1)
+	for k, v := range w.opts.Params {
+		params[k] = v
+	}
+	// a delete scan needs every object, without a watermark everything comes back anyway
+	if tc.Watermark != "" && !scanDeletes {
+		params[openx.ModifiedSinceParam] = tc.Watermark
+	}
+	objects, err := w.api.List(ctx, objectType, params)
+	if err != nil {
+		return err
+	}
+	// oldest first so events come in the order they happened and the cursor only moves forward
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objects[i].String("modified_date") < objects[j].String("modified_date")
+	})
+
+	next := tc.clone()
+	known := tc.known()
+	atWatermark := tc.atWatermark()
+	var pending []Event
+	for _, obj := range objects {
+		uid, modified := obj.UID(), obj.String("modified_date")
+		if uid == "" {
+			continue
+		}
+		next.see(uid, modified)
+		if modified < tc.Watermark || (modified == tc.Watermark && atWatermark[uid]) {
+			// already delivered, only here because of a delete scan or the watermark's second
+			continue
+		}
+		when, err := openx.ParseDate(modified)
+		if err != nil {
+			return errors.Wrapf(err, "%s %s has a bad modified_date", objectType, uid)
+		}
+		pending = append(pending, Event{Type: w.classify(obj, tc, known), ObjectType: objectType, UID: uid, Object: obj, Time: when})
+	}
+
+	if scanDeletes {
+		seen := make(map[string]bool, len(objects))
+		for _, obj := range objects {
+			seen[obj.UID()] = true
+		}
+		var gone []string
+		for uid := range known {
+			if !seen[uid] {
+				gone = append(gone, uid)
+			}
+		}
+		sort.Strings(gone)
+		now := w.now().UTC()
+		for _, uid := range gone {
+			pending = append(pending, Event{Type: Deleted, ObjectType: objectType, UID: uid, Time: now})
+		}
+		next.Known = make([]string, 0, len(seen))
+		for uid := range seen {
+			next.Known = append(next.Known, uid)
+		}
+		sort.Strings(next.Known)
+	} else if w.opts.DeleteScanEvery > 0 {
+		next.addKnown(objects)
+	}
+
+	if !quiet {
+		for _, e := range pending {
+			select {
+			case events <- e:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	w.cursor[objectType] = next
+	return nil
+}
+
+// classify tells a created object from an updated one. Known uids decide when deletes are tracked,
+// otherwise an object created after the cursor counts as created
+func (w *Watcher) classify(obj openx.Object, tc *TypeCursor, known map[string]bool) EventType {
+	if w.opts.DeleteScanEvery > 0 && len(tc.Known) > 0 {
+		if known[obj.UID()] {
+			return Updated
+		}
+		return Created
+	}
+	if created := obj.String("created_date"); created != "" && created >= tc.Watermark {
+		if created > tc.Watermark || !tc.atWatermark()[obj.UID()] {
+			return Created
+		}
+	}
+	return Updated
+}
+
+// Cursor returns a copy of where the watcher is, by type
+func (w *Watcher) Cursor() Cursor {
+	return w.cursor.clone()
+}
+
+func (w *Watcher) load(ctx context.Context) (Cursor, error) {
+	if w.opts.Cursor == nil {
+		return Cursor{}, nil
+	}
+	cursor, err := w.opts.Cursor.Load(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "Couldn't load the watch cursor")
+	}
+	if cursor == nil {
+		cursor = Cursor{}
+	}
+	return cursor, nil
+}
+
+func (w *Watcher) save(ctx context.Context) error {
+	if w.opts.Cursor == nil {
+		return nil
+	}
+	return errors.Wrap(w.opts.Cursor.Save(ctx, w.cursor.clone()), "Couldn't save the watch cursor")
+}
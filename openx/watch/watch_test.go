@@ -0,0 +1,188 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/marcsantiago/OX3-Go-API-Client/openx"
+	"github.com/marcsantiago/OX3-Go-API-Client/openx/openxtest"
+)
+
+// clock hands out times a minute apart, or the same time when frozen
+type clock struct {
+	t      time.Time
+	frozen bool
+}
+
+func (c *clock) now() time.Time {
+	if !c.frozen {
+		c.t = c.t.Add(time.Minute)
+	}
+	return c.t
+}
+
+func newStore() *openxtest.Store {
+	store := openxtest.NewStore()
+	store.Now = (&clock{t: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}).now
+	return store
+}
+
+// poll runs one poll and returns "<type> <object type> <name>" for every event
+func poll(t *testing.T, w *Watcher) []string {
+	t.Helper()
+	events := make(chan Event, 100)
+	if err := w.Poll(context.Background(), events); err != nil {
+		t.Fatal(err)
+	}
+	close(events)
+	var got []string
+	for e := range events {
+		name := e.UID
+		if e.Object != nil {
+			name = e.Object.String("name")
+		}
+		got = append(got, string(e.Type)+" "+e.ObjectType+" "+name)
+	}
+	return got
+}
+
+func expect(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if len(got) == 0 && len(want) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got events %q, want %q", got, want)
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	ctx := context.Background()
+	store := newStore()
+	existing, _ := store.Create(ctx, openx.TypeSite, openx.Object{"name": "existing.com"})
+
+	w := New(store, Options{Types: []string{openx.TypeSite, openx.TypeOrder}})
+	expect(t, poll(t, w))
+
+	site, _ := store.Create(ctx, openx.TypeSite, openx.Object{"name": "example.com"})
+	store.Create(ctx, openx.TypeOrder, openx.Object{"name": "spring"})
+	expect(t, poll(t, w), "created site example.com", "created order spring")
+
+	store.Update(ctx, openx.TypeSite, site.UID(), openx.Object{"url": "http://example.com"})
+	store.Update(ctx, openx.TypeSite, existing.UID(), openx.Object{"url": "http://existing.com"})
+	expect(t, poll(t, w), "updated site example.com", "updated site existing.com")
+
+	// nothing new, the objects at the watermark aren't sent twice
+	expect(t, poll(t, w))
+
+	if got := w.Cursor()[openx.TypeSite].Watermark; got != store.Objects(openx.TypeSite)[0].String("modified_date") {
+		t.Errorf("site watermark is %s", got)
+	}
+}
+
+func TestWatcherSameSecond(t *testing.T) {
+	ctx := context.Background()
+	store := openxtest.NewStore()
+	c := &clock{t: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), frozen: true}
+	store.Now = c.now
+
+	w := New(store, Options{Types: []string{openx.TypeSite}, Backfill: true})
+	store.Create(ctx, openx.TypeSite, openx.Object{"name": "a.com"})
+	expect(t, poll(t, w), "created site a.com")
+
+	// created within the watermark's second, after the last poll
+	store.Create(ctx, openx.TypeSite, openx.Object{"name": "b.com"})
+	expect(t, poll(t, w), "created site b.com")
+	expect(t, poll(t, w))
+}
+
+func TestWatcherDeletes(t *testing.T) {
+	ctx := context.Background()
+	store := newStore()
+	a, _ := store.Create(ctx, openx.TypeSite, openx.Object{"name": "a.com"})
+
+	w := New(store, Options{Types: []string{openx.TypeSite}, DeleteScanEvery: 2})
+	expect(t, poll(t, w))
+
+	b, _ := store.Create(ctx, openx.TypeSite, openx.Object{"name": "b.com"})
+	store.Remove(ctx, openx.TypeSite, a.UID())
+	// not a scan, the delete goes unnoticed for now
+	expect(t, poll(t, w), "created site b.com")
+
+	store.Update(ctx, openx.TypeSite, b.UID(), openx.Object{"url": "http://b.com"})
+	expect(t, poll(t, w), "updated site b.com", "deleted site "+a.UID())
+	expect(t, poll(t, w))
+}
+
+func TestWatcherCursorFile(t *testing.T) {
+	ctx := context.Background()
+	store := newStore()
+	path := FileCursor(filepath.Join(t.TempDir(), "cursor.json"))
+	opts := Options{Types: []string{openx.TypeSite}, Cursor: path}
+
+	expect(t, poll(t, New(store, opts)))
+	store.Create(ctx, openx.TypeSite, openx.Object{"name": "a.com"})
+	expect(t, poll(t, New(store, opts)), "created site a.com")
+
+	// a restarted watcher carries on from the saved cursor
+	expect(t, poll(t, New(store, opts)))
+
+	saved, err := path.Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved[openx.TypeSite] == nil || saved[openx.TypeSite].Watermark == "" {
+		t.Errorf("cursor file holds %+v", saved)
+	}
+}
+
+func TestWatcherErrors(t *testing.T) {
+	ctx := context.Background()
+	store := newStore()
+	boom := errors.New("boom")
+
+	store.FailOn("list", openx.TypeSite, boom)
+	w := New(store, Options{Types: []string{openx.TypeSite, openx.TypeOrder}})
+	if err := w.Poll(ctx, make(chan Event, 1)); err == nil {
+		t.Error("expected the failure to stop the poll without OnError")
+	}
+
+	var failed []string
+	store.FailOn("list", openx.TypeSite, boom)
+	w = New(store, Options{Types: []string{openx.TypeSite, openx.TypeOrder}, OnError: func(objectType string, err error) {
+		failed = append(failed, objectType)
+	}})
+	if err := w.Poll(ctx, make(chan Event, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(failed, []string{openx.TypeSite}) {
+		t.Errorf("OnError got %v", failed)
+	}
+	if _, ok := w.Cursor()[openx.TypeOrder]; !ok {
+		t.Error("orders weren't polled after sites failed")
+	}
+}
+
+func TestWatcherRun(t *testing.T) {
+	store := newStore()
+	w := New(store, Options{Types: []string{openx.TypeSite}, Interval: time.Millisecond, Backfill: true})
+	store.Create(context.Background(), openx.TypeSite, openx.Object{"name": "a.com"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan Event)
+	done := make(chan error)
+	go func() { done <- w.Run(ctx, events) }()
+
+	e := <-events
+	if e.Type != Created || e.Object.String("name") != "a.com" {
+		t.Errorf("got %+v", e)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run returned %v, want context.Canceled", err)
+	}
+}